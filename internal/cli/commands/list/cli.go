@@ -31,7 +31,8 @@ const (
 	DependenciesFlagName = "dependencies"
 	ExternalFlagName     = "external"
 
-	DAGFlagName = "dag"
+	DAGFlagName     = "dag"
+	ReducedFlagName = "reduced"
 
 	QueueConstructAsFlagName  = "queue-construct-as"
 	QueueConstructAsFlagAlias = "as"
@@ -49,7 +50,7 @@ func NewFlags(l log.Logger, opts *Options, prefix flags.Prefix) clihelper.Flags
 			Name:        FormatFlagName,
 			EnvVars:     tgPrefix.EnvVars(FormatFlagName),
 			Destination: &opts.Format,
-			Usage:       "Output format for list results. Valid values: text, tree, long, dot.",
+			Usage:       "Output format for list results. Valid values: text, tree, long, dot, mermaid.",
 			DefaultText: FormatText,
 		}),
 		flags.NewFlag(&clihelper.BoolFlag{
@@ -121,6 +122,12 @@ func NewFlags(l log.Logger, opts *Options, prefix flags.Prefix) clihelper.Flags
 			Destination: &opts.DAG,
 			Usage:       "Use DAG mode to sort and group output.",
 		}),
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ReducedFlagName,
+			EnvVars:     tgPrefix.EnvVars(ReducedFlagName),
+			Destination: &opts.Reduced,
+			Usage:       "Omit dependencies that are implied by other dependencies from the output.",
+		}),
 		flags.NewFlag(&clihelper.GenericFlag[string]{
 			Name:        QueueConstructAsFlagName,
 			EnvVars:     tgPrefix.EnvVars(QueueConstructAsFlagName),
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -28,8 +29,8 @@ func Run(ctx context.Context, l log.Logger, opts *Options) error {
 		WorkingDir:        opts.WorkingDir,
 		QueueConstructAs:  opts.QueueConstructAs,
 		NoHidden:          opts.NoHidden,
-		WithRequiresParse: opts.Dependencies || opts.Mode == ModeDAG,
-		WithRelationships: opts.Dependencies || opts.Mode == ModeDAG,
+		WithRequiresParse: opts.Dependencies || opts.Reduced || opts.Mode == ModeDAG,
+		WithRelationships: opts.Dependencies || opts.Reduced || opts.Mode == ModeDAG,
 		Filters:           opts.Filters,
 		Experiments:       opts.Experiments,
 	})
@@ -103,6 +104,16 @@ func Run(ctx context.Context, l log.Logger, opts *Options) error {
 		return errors.New("invalid mode: " + opts.Mode)
 	}
 
+	// Drop the dependencies implied by other dependencies, e.g. to declutter the dot and mermaid graphs.
+	if opts.Reduced {
+		q, queueErr := queue.NewQueue(components)
+		if queueErr != nil {
+			return errors.New(queueErr)
+		}
+
+		q.TransitiveReduction()
+	}
+
 	var listedComponents dag.ListedComponents
 
 	err = telemetry.TelemeterFromContext(ctx).Collect(ctx, "list_discovered_to_listed", map[string]any{
@@ -128,6 +139,8 @@ func Run(ctx context.Context, l log.Logger, opts *Options) error {
 		return outputLong(l, opts, listedComponents)
 	case FormatDot:
 		return outputDot(l, opts, listedComponents)
+	case FormatMermaid:
+		return outputMermaid(l, opts, listedComponents)
 	default:
 		// This should never happen, because of validation in the command.
 		// If it happens, we want to throw so we can fix the validation.
@@ -146,7 +159,7 @@ func discoveredToListed(components component.Components, opts *Options) (dag.Lis
 			if unit, ok := c.(*component.Unit); ok {
 				if cfg := unit.Config(); cfg != nil && cfg.Exclude != nil {
 					if cfg.Exclude.IsActionListed(opts.QueueConstructAs) {
-						if opts.Format != FormatDot {
+						if opts.Format != FormatDot && opts.Format != FormatMermaid {
 							continue
 						}
 
@@ -336,6 +349,11 @@ func renderTabular(opts *Options, components dag.ListedComponents, c *dag.Colori
 	return errors.New(err)
 }
 
+// outputMermaid outputs the discovered components as a Mermaid flowchart.
+func outputMermaid(_ log.Logger, opts *Options, components dag.ListedComponents) error {
+	return renderMermaid(opts, components)
+}
+
 // outputTree outputs the discovered components in tree format.
 func outputTree(l log.Logger, opts *Options, components dag.ListedComponents, sort string) error {
 	s := dag.NewTreeStyler(shouldColor(l))
@@ -520,3 +538,76 @@ func renderDot(opts *Options, components dag.ListedComponents) error {
 
 	return errors.New(err)
 }
+
+// mermaidReservedIDs are words that cannot be used as Mermaid node IDs.
+var mermaidReservedIDs = map[string]bool{"end": true, "graph": true, "subgraph": true, "flowchart": true}
+
+// mermaidInvalidIDChars matches characters that are not allowed in Mermaid node IDs.
+var mermaidInvalidIDChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// renderMermaid renders the components as a Mermaid flowchart, so they can be embedded in Markdown.
+// Node IDs are derived from the component paths, while the paths themselves are used as node labels.
+func renderMermaid(opts *Options, components dag.ListedComponents) error {
+	var buf strings.Builder
+
+	buf.WriteString("graph TD\n")
+
+	sortedComponents := make(dag.ListedComponents, len(components))
+	copy(sortedComponents, components)
+	sort.Slice(sortedComponents, func(i, j int) bool {
+		return sortedComponents[i].Path < sortedComponents[j].Path
+	})
+
+	ids := make(map[string]string, len(sortedComponents))
+	used := make(map[string]bool, len(sortedComponents))
+
+	nodeID := func(path string) string {
+		if id, ok := ids[path]; ok {
+			return id
+		}
+
+		base := mermaidInvalidIDChars.ReplaceAllString(path, "_")
+		id := base
+
+		for i := 2; used[id] || mermaidReservedIDs[strings.ToLower(id)]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+
+		ids[path] = id
+		used[id] = true
+
+		return id
+	}
+
+	hasExcluded := false
+
+	for _, component := range sortedComponents {
+		style := ""
+		if component.Excluded {
+			style = ":::excluded"
+			hasExcluded = true
+		}
+
+		fmt.Fprintf(&buf, "\t%s[\"%s\"]%s\n", nodeID(component.Path), strings.ReplaceAll(component.Path, `"`, "#quot;"), style)
+	}
+
+	for _, component := range sortedComponents {
+		deps := make([]*dag.ListedComponent, len(component.Dependencies))
+		copy(deps, component.Dependencies)
+		sort.Slice(deps, func(i, j int) bool {
+			return deps[i].Path < deps[j].Path
+		})
+
+		for _, dep := range deps {
+			fmt.Fprintf(&buf, "\t%s --> %s\n", nodeID(component.Path), nodeID(dep.Path))
+		}
+	}
+
+	if hasExcluded {
+		buf.WriteString("\tclassDef excluded stroke:#f00,stroke-dasharray:5 5\n")
+	}
+
+	_, err := opts.Writers.Writer.Write([]byte(buf.String()))
+
+	return errors.New(err)
+}
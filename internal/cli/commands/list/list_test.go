@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -725,6 +726,84 @@ dependency "unit2" {
 	)
 }
 
+func TestDotFormatReduced(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := helpers.TmpDirWOSymlinks(t)
+
+	testDirs := []string{
+		"unit1",
+		"unit2",
+		"unit3",
+	}
+
+	for _, dir := range testDirs {
+		err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		require.NoError(t, err)
+	}
+
+	testFiles := map[string]string{
+		"unit1/terragrunt.hcl": "",
+		"unit2/terragrunt.hcl": `
+dependency "unit1" {
+  config_path = "../unit1"
+}
+`,
+		"unit3/terragrunt.hcl": `
+dependency "unit1" {
+  config_path = "../unit1"
+}
+
+dependency "unit2" {
+  config_path = "../unit2"
+}
+`,
+	}
+
+	for path, content := range testFiles {
+		err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+
+	l := logger.CreateLogger()
+	tgOptions, err := options.NewTerragruntOptionsForTest(tmpDir)
+	require.NoError(t, err)
+
+	opts := list.NewOptions(tgOptions)
+	opts.Format = list.FormatDot
+	opts.Mode = list.ModeDAG
+	opts.Dependencies = true
+	opts.Reduced = true
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	opts.Writers.Writer = w
+
+	err = list.Run(t.Context(), l, opts)
+	require.NoError(t, err)
+
+	w.Close()
+
+	output, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	outputStr := string(output)
+
+	assert.Equal(
+		t,
+		`digraph {
+	"001/unit1" ;
+	"001/unit2" ;
+	"001/unit2" -> "001/unit1";
+	"001/unit3" ;
+	"001/unit3" -> "001/unit2";
+}
+`,
+		outputStr,
+	)
+}
+
 func TestDotFormatWithExcludedComponents(t *testing.T) {
 	t.Parallel()
 
@@ -804,6 +883,91 @@ dependency "unit2" {
 	)
 }
 
+func TestMermaidFormatWithExcludedComponents(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := helpers.TmpDirWOSymlinks(t)
+
+	testDirs := []string{
+		"unit1",
+		"unit-2",
+		"end",
+	}
+
+	for _, dir := range testDirs {
+		err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		require.NoError(t, err)
+	}
+
+	testFiles := map[string]string{
+		"unit1/terragrunt.hcl": "",
+		"unit-2/terragrunt.hcl": `
+dependency "unit1" {
+  config_path = "../unit1"
+}
+
+exclude {
+  if      = true
+  actions = ["apply"]
+}
+`,
+		"end/terragrunt.hcl": `
+dependency "unit2" {
+  config_path = "../unit-2"
+}
+`,
+	}
+
+	for path, content := range testFiles {
+		err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+
+	l := logger.CreateLogger()
+	tgOptions, err := options.NewTerragruntOptionsForTest(tmpDir)
+	require.NoError(t, err)
+
+	opts := list.NewOptions(tgOptions)
+	opts.Format = list.FormatMermaid
+	opts.Mode = list.ModeDAG
+	opts.Dependencies = true
+	opts.QueueConstructAs = "apply"
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	opts.Writers.Writer = w
+
+	err = list.Run(t.Context(), l, opts)
+	require.NoError(t, err)
+
+	w.Close()
+
+	output, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	outputStr := string(output)
+
+	assert.Equal(
+		t,
+		`graph TD
+	001_end["001/end"]
+	001_unit_2["001/unit-2"]:::excluded
+	001_unit1["001/unit1"]
+	001_end --> 001_unit_2
+	001_unit_2 --> 001_unit1
+	classDef excluded stroke:#f00,stroke-dasharray:5 5
+`,
+		outputStr,
+	)
+
+	// Every line must be a valid Mermaid flowchart statement.
+	statement := regexp.MustCompile(`^(graph TD|\t[A-Za-z0-9_]+\["[^"]*"\](:::[a-z]+)?|\t[A-Za-z0-9_]+ --> [A-Za-z0-9_]+|\tclassDef [a-z]+ [^;]+)$`)
+	for line := range strings.SplitSeq(strings.TrimSuffix(outputStr, "\n"), "\n") {
+		assert.Regexp(t, statement, line)
+	}
+}
+
 func TestDotFormatWithExcludedDependency(t *testing.T) {
 	t.Parallel()
 
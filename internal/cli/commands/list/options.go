@@ -18,6 +18,9 @@ const (
 	// FormatDot outputs the discovered configurations in GraphViz DOT format.
 	FormatDot = "dot"
 
+	// FormatMermaid outputs the discovered configurations as a Mermaid flowchart.
+	FormatMermaid = "mermaid"
+
 	// SortDAG sorts the discovered configurations in a topological sort order.
 	SortDAG = "dag"
 
@@ -54,6 +57,9 @@ type Options struct {
 
 	// DAG determines whether to output in DAG format.
 	DAG bool
+
+	// Reduced determines whether dependencies implied by other dependencies are omitted from the output.
+	Reduced bool
 }
 
 func NewOptions(opts *options.TerragruntOptions) *Options {
@@ -92,6 +98,8 @@ func (o *Options) validateFormat() error {
 		return nil
 	case FormatDot:
 		return nil
+	case FormatMermaid:
+		return nil
 	default:
 		return errors.New("invalid format: " + o.Format)
 	}
@@ -30,6 +30,39 @@ const (
 	DependencyFetchOutputFromStateFlagName   = "dependency-fetch-output-from-state"
 	UsePartialParseConfigCacheFlagName       = "use-partial-parse-config-cache"
 	SummaryPerUnitFlagName                   = "summary-per-unit"
+	MaxTotalChangesFlagName                  = "max-total-changes"
+	FailOnEmptyRunFlagName                   = "fail-on-empty-run"
+	ParallelismAutoFlagName                  = "parallelism-auto"
+	ParallelismFileFlagName                  = "parallelism-file"
+	ParallelismScheduleFlagName              = "parallelism-schedule"
+	MaxTotalRetriesFlagName                  = "max-total-retries"
+	ContinueOnErrorFlagName                  = "continue-on-error"
+	MaxReportedErrorsFlagName                = "max-reported-errors"
+	GroupErrorsBySubtreeFlagName             = "group-errors-by-subtree"
+	GroupErrorsByTypeFlagName                = "group-errors-by-type"
+	CanariesFlagName                         = "canaries"
+	WaveBarrierOnFailureFlagName             = "wave-barrier-on-failure"
+	FanOutWarningThresholdFlagName           = "fan-out-warning-threshold"
+	ProgressLoggingFlagName                  = "progress-logging"
+	ConfirmEachUnitFlagName                  = "confirm-each-unit"
+	ExcludeGlobFlagName                      = "exclude-glob"
+	UnitCommandFlagName                      = "unit-command"
+	UnitSuccessCmdFlagName                   = "unit-success-cmd"
+	FailOnUnitSuccessCmdErrorFlagName        = "fail-on-unit-success-cmd-error"
+	PlanFileResolverCmdFlagName              = "plan-file-resolver-cmd"
+	MaxStartsPerSecondFlagName               = "max-starts-per-second"
+	FlakyHuntSeedFlagName                    = "flaky-hunt-seed"
+	MinFreeDiskBytesFlagName                 = "min-free-disk-bytes"
+	StrictReportingFlagName                  = "strict-reporting"
+	FailOnStalePlanFlagName                  = "fail-on-stale-plan"
+	RequireFullDependencyChainFlagName       = "require-full-dependency-chain"
+	GroupedOutputFlagName                    = "grouped-output"
+	UnitOutputMemoryBudgetFlagName           = "unit-output-memory-budget"
+	MemoryBudgetFlagName                     = "memory-budget"
+	UnitMemoryEstimateFlagName               = "unit-memory-estimate"
+	ReleaseFinishedUnitsFlagName             = "release-finished-units"
+	EventSocketFlagName                      = "event-socket"
+	ResultWebhookFlagName                    = "result-webhook"
 	VersionManagerFileNameFlagName           = "version-manager-file-name"
 
 	DisableCommandValidationFlagName   = "disable-command-validation"
@@ -60,15 +93,17 @@ const (
 
 	// Report related flags.
 
-	SummaryDisableFlagName = "summary-disable"
-	ReportFileFlagName     = "report-file"
-	ReportFormatFlagName   = "report-format"
-	ReportSchemaFlagName   = "report-schema-file"
+	SummaryDisableFlagName         = "summary-disable"
+	ReportFileFlagName             = "report-file"
+	ReportFormatFlagName           = "report-format"
+	ReportSchemaFlagName           = "report-schema-file"
+	ReportSnapshotIntervalFlagName = "report-snapshot-interval"
 
 	// `--all` related flags.
 
-	OutDirFlagName     = "out-dir"
-	JSONOutDirFlagName = "json-out-dir"
+	OutDirFlagName               = "out-dir"
+	JSONOutDirFlagName           = "json-out-dir"
+	KeepJSONInMemoryOnlyFlagName = "keep-json-in-memory-only"
 
 	// `--graph` related flags.
 	GraphRootFlagName = "graph-root"
@@ -110,6 +145,13 @@ func NewFlags(l log.Logger, opts *options.TerragruntOptions, prefix flags.Prefix
 		},
 			flags.WithDeprecatedEnvVars(terragruntPrefix.EnvVars("json-out-dir"), terragruntPrefixControl)),
 
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        KeepJSONInMemoryOnlyFlagName,
+			EnvVars:     tgPrefix.EnvVars(KeepJSONInMemoryOnlyFlagName),
+			Destination: &opts.KeepJSONInMemoryOnly,
+			Usage:       "Convert the plans of a run --all to JSON to record their changes in the report, without writing JSON plan files.",
+		}),
+
 		// `graph/-graph` related flags.
 
 		flags.NewFlag(&clihelper.GenericFlag[string]{
@@ -434,6 +476,246 @@ func NewFlags(l log.Logger, opts *options.TerragruntOptions, prefix flags.Prefix
 			Usage:       `Show duration information for each unit in the summary output.`,
 		}),
 
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        MaxTotalChangesFlagName,
+			EnvVars:     tgPrefix.EnvVars(MaxTotalChangesFlagName),
+			Destination: &opts.MaxTotalChanges,
+			Usage:       `Abort a run --all apply before applying anything if the plan reports more resource changes than this across all units.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        FailOnEmptyRunFlagName,
+			EnvVars:     tgPrefix.EnvVars(FailOnEmptyRunFlagName),
+			Destination: &opts.FailOnEmptyRun,
+			Usage:       `Fail a run --all when no unit is left to run, e.g. because every unit was excluded.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        MaxTotalRetriesFlagName,
+			EnvVars:     tgPrefix.EnvVars(MaxTotalRetriesFlagName),
+			Destination: &opts.MaxTotalRetries,
+			Usage:       `Limit the total number of retries across all units of a run --all.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[string]{
+			Name:        EventSocketFlagName,
+			EnvVars:     tgPrefix.EnvVars(EventSocketFlagName),
+			Destination: &opts.EventSocketPath,
+			Usage:       `Stream the start and finish of every unit of a run --all to a Unix domain socket, as newline delimited JSON.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[string]{
+			Name:        ResultWebhookFlagName,
+			EnvVars:     tgPrefix.EnvVars(ResultWebhookFlagName),
+			Destination: &opts.ResultWebhook,
+			Usage:       `Post the result of every unit of a run --all to a webhook URL as JSON when it finishes.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        MaxReportedErrorsFlagName,
+			EnvVars:     tgPrefix.EnvVars(MaxReportedErrorsFlagName),
+			Destination: &opts.MaxReportedErrors,
+			Usage:       `Report at most this many unit errors when a run --all fails, and summarize the rest.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        CanariesFlagName,
+			EnvVars:     tgPrefix.EnvVars(CanariesFlagName),
+			Destination: &opts.Canaries,
+			Usage:       `Run the first unit by path of each dependency wave of a run --all alone, and only run the rest of the wave once it succeeded.`,
+		}),
+
+		flags.NewFlag(&clihelper.SliceFlag[string]{
+			Name:        ExcludeGlobFlagName,
+			EnvVars:     tgPrefix.EnvVars(ExcludeGlobFlagName),
+			Destination: &opts.ExcludeGlobs,
+			Usage:       `Exclude the units of a run --all whose path matches the glob, e.g. '**/experimental/**'. A glob prefixed with '!' includes the units it matches again.`,
+		}),
+
+		flags.NewFlag(&clihelper.MapFlag[string, string]{
+			Name:        UnitCommandFlagName,
+			EnvVars:     tgPrefix.EnvVars(UnitCommandFlagName),
+			Destination: &opts.UnitCommands,
+			Usage:       `Run the given command in the unit at the given path instead of the command of the run --all, e.g. 'app=plan'.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[string]{
+			Name:        UnitSuccessCmdFlagName,
+			EnvVars:     tgPrefix.EnvVars(UnitSuccessCmdFlagName),
+			Destination: &opts.UnitSuccessCmd,
+			Usage:       `Run the given command after every successful run of a unit of a run --all, e.g. to replicate its state to a secondary backend.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        FailOnUnitSuccessCmdErrorFlagName,
+			EnvVars:     tgPrefix.EnvVars(FailOnUnitSuccessCmdErrorFlagName),
+			Destination: &opts.FailOnUnitSuccessCmdError,
+			Usage:       `Fail a unit when the command of --unit-success-cmd fails for it, instead of logging a warning.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[string]{
+			Name:        PlanFileResolverCmdFlagName,
+			EnvVars:     tgPrefix.EnvVars(PlanFileResolverCmdFlagName),
+			Destination: &opts.PlanFileResolverCmd,
+			Usage:       `Run the given command for each unit of a run --all to print the plan file to convert to JSON, e.g. after decrypting the plan the run wrote.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ConfirmEachUnitFlagName,
+			EnvVars:     tgPrefix.EnvVars(ConfirmEachUnitFlagName),
+			Destination: &opts.ConfirmEachUnit,
+			Usage:       `Prompt for confirmation before each unit of a run --all starts, skipping the declined units and their dependents.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ProgressLoggingFlagName,
+			EnvVars:     tgPrefix.EnvVars(ProgressLoggingFlagName),
+			Destination: &opts.ProgressLogging,
+			Usage:       `Log how far a run --all has progressed every time one of its units finishes.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        FanOutWarningThresholdFlagName,
+			EnvVars:     tgPrefix.EnvVars(FanOutWarningThresholdFlagName),
+			Destination: &opts.FanOutWarningThreshold,
+			Usage:       `Warn about the units of a run --all that more than this many units directly depend on.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        WaveBarrierOnFailureFlagName,
+			EnvVars:     tgPrefix.EnvVars(WaveBarrierOnFailureFlagName),
+			Destination: &opts.WaveBarrierOnFailure,
+			Usage:       `Let the dependency waves of a run --all overlap, except after a wave with failures, where the next wave has to finish before the one after it starts.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        GroupErrorsBySubtreeFlagName,
+			EnvVars:     tgPrefix.EnvVars(GroupErrorsBySubtreeFlagName),
+			Destination: &opts.GroupErrorsBySubtree,
+			Usage:       `Group the unit errors of a failed run --all by the top-level directory of the stack the units belong to.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        GroupErrorsByTypeFlagName,
+			EnvVars:     tgPrefix.EnvVars(GroupErrorsByTypeFlagName),
+			Destination: &opts.GroupErrorsByType,
+			Usage:       `Group the unit errors of a failed run --all by the type of their cause.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        MaxStartsPerSecondFlagName,
+			EnvVars:     tgPrefix.EnvVars(MaxStartsPerSecondFlagName),
+			Destination: &opts.MaxStartsPerSecond,
+			Usage:       `Start at most this many units per second when running --all, to protect rate limited APIs.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int64]{
+			Name:        MinFreeDiskBytesFlagName,
+			EnvVars:     tgPrefix.EnvVars(MinFreeDiskBytesFlagName),
+			Destination: &opts.MinFreeDiskBytes,
+			Usage:       `Fail the units of a run --all whose working directory has less than this many bytes of free disk space, without running them.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int64]{
+			Name:        FlakyHuntSeedFlagName,
+			EnvVars:     tgPrefix.EnvVars(FlakyHuntSeedFlagName),
+			Destination: &opts.FlakyHuntSeed,
+			Usage:       `Shuffle the dispatch order and randomize the parallelism of a run --all from this seed, to reproducibly surface flaky units.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ReleaseFinishedUnitsFlagName,
+			EnvVars:     tgPrefix.EnvVars(ReleaseFinishedUnitsFlagName),
+			Destination: &opts.ReleaseFinishedUnits,
+			Usage:       `Drop the parsed configuration of every unit of a run --all once it has finished, to reduce memory usage on massive stacks.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        StrictReportingFlagName,
+			EnvVars:     tgPrefix.EnvVars(StrictReportingFlagName),
+			Destination: &opts.StrictReporting,
+			Usage:       `Fail units of a run --all whose result cannot be recorded in the report.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        FailOnStalePlanFlagName,
+			EnvVars:     tgPrefix.EnvVars(FailOnStalePlanFlagName),
+			Destination: &opts.FailOnStalePlan,
+			Usage:       `Fail units of a run --all apply whose dependency outputs changed since their plan was saved to --out-dir.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        RequireFullDependencyChainFlagName,
+			EnvVars:     tgPrefix.EnvVars(RequireFullDependencyChainFlagName),
+			Destination: &opts.RequireFullDependencyChain,
+			Usage:       `Fail units of a run --all apply with a dependency that was neither applied by the run nor assumed to be applied.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        GroupedOutputFlagName,
+			EnvVars:     tgPrefix.EnvVars(GroupedOutputFlagName),
+			Destination: &opts.GroupedOutput,
+			Usage:       `Write the output of each unit of a run --all as a single block once the unit finishes.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        UnitOutputMemoryBudgetFlagName,
+			EnvVars:     tgPrefix.EnvVars(UnitOutputMemoryBudgetFlagName),
+			Destination: &opts.UnitOutputMemoryBudget,
+			Usage:       `Buffer at most this many bytes of output across the units of a run --all, writing the largest buffer early past the budget.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        MemoryBudgetFlagName,
+			EnvVars:     tgPrefix.EnvVars(MemoryBudgetFlagName),
+			Destination: &opts.MemoryBudget,
+			Usage:       `Run units of a run --all only while the sum of their memory estimates, in MiB, fits in this budget.`,
+		}),
+
+		flags.NewFlag(&clihelper.MapFlag[string, int]{
+			Name:        UnitMemoryEstimateFlagName,
+			EnvVars:     tgPrefix.EnvVars(UnitMemoryEstimateFlagName),
+			Destination: &opts.UnitMemoryEstimates,
+			Usage:       `Estimated peak memory of the unit at the given path, in MiB, as path=MiB, counted against --memory-budget.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ContinueOnErrorFlagName,
+			EnvVars:     tgPrefix.EnvVars(ContinueOnErrorFlagName),
+			Destination: &opts.ContinueOnError,
+			Usage:       `Run every unit of a run --all regardless of failures, and fail the run if any unit failed.`,
+		}),
+
+		flags.NewFlag(&clihelper.BoolFlag{
+			Name:        ParallelismAutoFlagName,
+			EnvVars:     tgPrefix.EnvVars(ParallelismAutoFlagName),
+			Destination: &opts.ParallelismAuto,
+			Usage:       `Set the parallelism of a run --all to the number of units that can run at once, capped by --parallelism and the number of CPUs.`,
+		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[string]{
+			Name:        ParallelismFileFlagName,
+			EnvVars:     tgPrefix.EnvVars(ParallelismFileFlagName),
+			Destination: &opts.ParallelismFile,
+			Usage:       `Path to a file holding the parallelism of a run --all, read again every second so that it can be changed during the run.`,
+		}),
+
+		flags.NewFlag(&clihelper.SliceFlag[int]{
+			Name:        ParallelismScheduleFlagName,
+			EnvVars:     tgPrefix.EnvVars(ParallelismScheduleFlagName),
+			Destination: &opts.ParallelismSchedule,
+			Usage:       `Parallelism of each dependency wave of a run --all, in order, the last value applying to every later wave.`,
+			Action: func(_ context.Context, _ *clihelper.Context, value []int) error {
+				for _, parallelism := range value {
+					if parallelism <= 0 {
+						return fmt.Errorf("invalid --%s value %d, the parallelism of every wave must be positive", ParallelismScheduleFlagName, parallelism)
+					}
+				}
+
+				return nil
+			},
+		}),
+
 		flags.NewFlag(&clihelper.GenericFlag[string]{
 			Name:    ReportFileFlagName,
 			EnvVars: tgPrefix.EnvVars(ReportFileFlagName),
@@ -450,12 +732,15 @@ func NewFlags(l log.Logger, opts *options.TerragruntOptions, prefix flags.Prefix
 					ext = ".csv"
 				}
 
-				if ext != ".csv" && ext != ".json" {
+				if ext != ".csv" && ext != ".json" && ext != ".xml" && ext != ".sarif" {
 					return nil
 				}
 
 				if opts.ReportFormat == "" {
 					opts.ReportFormat = report.Format(ext[1:])
+					if ext == ".xml" {
+						opts.ReportFormat = report.FormatJUnit
+					}
 				}
 
 				return nil
@@ -478,6 +763,10 @@ func NewFlags(l log.Logger, opts *options.TerragruntOptions, prefix flags.Prefix
 				switch opts.ReportFormat {
 				case report.FormatCSV:
 				case report.FormatJSON:
+				case report.FormatJUnit:
+				case report.FormatSARIF:
+				case report.FormatChanges:
+				case report.FormatParquet:
 				default:
 					return fmt.Errorf("unsupported report format: %s", value)
 				}
@@ -492,6 +781,13 @@ func NewFlags(l log.Logger, opts *options.TerragruntOptions, prefix flags.Prefix
 			Usage:       `Path to generate report schema file in.`,
 			Destination: &opts.ReportSchemaFile,
 		}),
+
+		flags.NewFlag(&clihelper.GenericFlag[int]{
+			Name:        ReportSnapshotIntervalFlagName,
+			EnvVars:     tgPrefix.EnvVars(ReportSnapshotIntervalFlagName),
+			Usage:       `Write the report to the report file every given number of seconds while a run --all is in progress.`,
+			Destination: &opts.ReportSnapshotInterval,
+		}),
 	}
 
 	// Add shared flags
@@ -131,6 +131,19 @@ func WriteToFile(l log.Logger, basePath string, config *GenerateConfig) error {
 		}
 	}
 
+	const ownerWriteGlobalReadPerms = 0644
+	if err := os.WriteFile(targetPath, FileContents(config), ownerWriteGlobalReadPerms); err != nil {
+		return errors.New(err)
+	}
+
+	l.Debugf("Generated file %s.", targetPath)
+
+	return nil
+}
+
+// FileContents returns the contents WriteToFile writes for the given config: its contents prefixed with the
+// Terragrunt signature, unless the signature is disabled, and formatted when they hold HCL.
+func FileContents(config *GenerateConfig) []byte {
 	// Add the signature as a prefix to the file, unless it is disabled.
 	prefix := ""
 	if !config.DisableSignature {
@@ -159,14 +172,7 @@ func WriteToFile(l log.Logger, basePath string, config *GenerateConfig) error {
 		contentsToWrite = hclwrite.Format(contentsToWrite)
 	}
 
-	const ownerWriteGlobalReadPerms = 0644
-	if err := os.WriteFile(targetPath, contentsToWrite, ownerWriteGlobalReadPerms); err != nil {
-		return errors.New(err)
-	}
-
-	l.Debugf("Generated file %s.", targetPath)
-
-	return nil
+	return contentsToWrite
 }
 
 // Whether or not file generation should continue if the file path already exists. The answer depends on the
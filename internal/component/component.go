@@ -37,6 +37,7 @@ type Component interface {
 	Origin() Origin
 	AddDependency(Component)
 	AddDependent(Component)
+	RemoveDependency(Component)
 	Dependencies() Components
 	Dependents() Components
 
@@ -47,8 +48,14 @@ type Component interface {
 
 	ensureDependency(Component)
 	ensureDependent(Component)
+	dropDependency(Component)
+	dropDependent(Component)
 }
 
+// PathDisplayFunc transforms the canonical path of a component into the path it is displayed with in logs and
+// reports, e.g. relative to the root of a stack.
+type PathDisplayFunc func(path string) string
+
 // Origin determines the discovery origin of a component.
 // This is important if there are multiple different reasons that a component might have been discovered.
 //
@@ -145,6 +145,30 @@ func TestComponentsCycleCheck(t *testing.T) {
 	}
 }
 
+func TestRemoveDependency(t *testing.T) {
+	t.Parallel()
+
+	a := component.NewUnit("a")
+	b := component.NewUnit("b")
+	c := component.NewStack("c")
+
+	a.AddDependency(b)
+	a.AddDependency(c)
+
+	deps := a.Dependencies()
+
+	a.RemoveDependency(b)
+
+	assert.Equal(t, []string{"c"}, a.Dependencies().Paths())
+	assert.Empty(t, b.Dependents())
+	assert.Equal(t, []string{"a"}, c.Dependents().Paths())
+	assert.Equal(t, []string{"b", "c"}, deps.Paths(), "previously returned dependencies should not change")
+
+	// Removing a component that is not a dependency is a no-op.
+	a.RemoveDependency(b)
+	assert.Equal(t, []string{"c"}, a.Dependencies().Paths())
+}
+
 func TestUnitStringConcurrent(t *testing.T) {
 	t.Parallel()
 
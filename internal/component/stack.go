@@ -197,6 +197,34 @@ func (s *Stack) AddDependent(dependent Component) {
 	dependent.ensureDependency(s)
 }
 
+// RemoveDependency removes a dependency from the Stack and vice versa.
+func (s *Stack) RemoveDependency(dependency Component) {
+	s.dropDependency(dependency)
+
+	dependency.dropDependent(s)
+}
+
+// dropDependency removes a dependency from a stack if it's present.
+func (s *Stack) dropDependency(dependency Component) {
+	s.lock()
+	defer s.unlock()
+
+	// Build a new slice, as callers may be iterating over the one returned by Dependencies.
+	s.dependencies = slices.DeleteFunc(slices.Clone(s.dependencies), func(c Component) bool {
+		return c == dependency
+	})
+}
+
+// dropDependent removes a dependent from a stack if it's present.
+func (s *Stack) dropDependent(dependent Component) {
+	s.lock()
+	defer s.unlock()
+
+	s.dependents = slices.DeleteFunc(slices.Clone(s.dependents), func(c Component) bool {
+		return c == dependent
+	})
+}
+
 // Dependencies returns the dependencies of the Stack.
 func (s *Stack) Dependencies() Components {
 	s.rLock()
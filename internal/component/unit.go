@@ -14,12 +14,16 @@ import (
 
 const (
 	UnitKind Kind = "unit"
+
+	// CPUProfileFileName is the name of the per-unit CPU profile file.
+	CPUProfileFileName = "cpu.pprof"
 )
 
 // Unit represents a discovered Terragrunt unit configuration.
 type Unit struct {
 	cfg              *config.TerragruntConfig
 	discoveryContext *DiscoveryContext
+	pathDisplay      PathDisplayFunc
 	path             string
 	configFile       string
 	reading          []string
@@ -65,11 +69,17 @@ func (u *Unit) WithDiscoveryContext(ctx *DiscoveryContext) *Unit {
 
 // Config returns the parsed Terragrunt configuration for this unit.
 func (u *Unit) Config() *config.TerragruntConfig {
+	u.rLock()
+	defer u.rUnlock()
+
 	return u.cfg
 }
 
 // StoreConfig stores the parsed Terragrunt configuration for this unit.
 func (u *Unit) StoreConfig(cfg *config.TerragruntConfig) {
+	u.lock()
+	defer u.unlock()
+
 	u.cfg = cfg
 }
 
@@ -147,6 +157,12 @@ func (u *Unit) SetDiscoveryContext(ctx *DiscoveryContext) {
 	u.discoveryContext = ctx
 }
 
+// SetPathDisplay sets the function transforming the path of this unit into the path returned by DisplayPath.
+// The path of the unit itself, used to identify it, is unchanged.
+func (u *Unit) SetPathDisplay(display PathDisplayFunc) {
+	u.pathDisplay = display
+}
+
 // Origin returns the origin of the discovery context for this component.
 func (u *Unit) Origin() Origin {
 	if u.discoveryContext == nil {
@@ -218,6 +234,34 @@ func (u *Unit) AddDependent(dependent Component) {
 	dependent.ensureDependency(u)
 }
 
+// RemoveDependency removes a dependency from the Unit and vice versa.
+func (u *Unit) RemoveDependency(dependency Component) {
+	u.dropDependency(dependency)
+
+	dependency.dropDependent(u)
+}
+
+// dropDependency removes a dependency from a unit if it's present.
+func (u *Unit) dropDependency(dependency Component) {
+	u.lock()
+	defer u.unlock()
+
+	// Build a new slice, as callers may be iterating over the one returned by Dependencies.
+	u.dependencies = slices.DeleteFunc(slices.Clone(u.dependencies), func(c Component) bool {
+		return c == dependency
+	})
+}
+
+// dropDependent removes a dependent from a unit if it's present.
+func (u *Unit) dropDependent(dependent Component) {
+	u.lock()
+	defer u.unlock()
+
+	u.dependents = slices.DeleteFunc(slices.Clone(u.dependents), func(c Component) bool {
+		return c == dependent
+	})
+}
+
 // Dependencies returns the dependencies of the Unit.
 func (u *Unit) Dependencies() Components {
 	u.rLock()
@@ -257,9 +301,14 @@ func (u *Unit) String() string {
 	)
 }
 
-// DisplayPath returns the path relative to DiscoveryContext.WorkingDir for display purposes.
+// DisplayPath returns the path relative to DiscoveryContext.WorkingDir for display purposes, or the path
+// transformed by the function set with SetPathDisplay, if any.
 // Falls back to the original path if relative path calculation fails or WorkingDir is empty.
 func (u *Unit) DisplayPath() string {
+	if u.pathDisplay != nil {
+		return u.pathDisplay(u.path)
+	}
+
 	if u.discoveryContext == nil || u.discoveryContext.WorkingDir == "" {
 		return u.path
 	}
@@ -311,6 +360,11 @@ func (u *Unit) OutputJSONFile(rootWorkingDir, jsonOutputFolder string) string {
 	return u.planFilePath(rootWorkingDir, jsonOutputFolder, tf.TerraformPlanJSONFile)
 }
 
+// CPUProfileFile returns the CPU profile file location if a profile folder is set.
+func (u *Unit) CPUProfileFile(rootWorkingDir, profileFolder string) string {
+	return u.planFilePath(rootWorkingDir, profileFolder, CPUProfileFileName)
+}
+
 // planFilePath computes the path for plan output files.
 func (u *Unit) planFilePath(rootWorkingDir, outputFolder, fileName string) string {
 	if outputFolder == "" {
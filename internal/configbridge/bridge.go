@@ -141,6 +141,7 @@ func NewRunOptions(opts *options.TerragruntOptions) *run.Options {
 		EngineConfig:                 opts.EngineConfig,
 		EngineOptions:                opts.EngineOptions,
 		Errors:                       opts.Errors,
+		RetryBudget:                  opts.RetryBudget,
 		Experiments:                  opts.Experiments,
 		StrictControls:               opts.StrictControls,
 		FeatureFlags:                 opts.FeatureFlags,
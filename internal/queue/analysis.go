@@ -0,0 +1,481 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+)
+
+// ErrEntryNotFound is returned when a path passed to the queue is not present in it.
+var ErrEntryNotFound = errors.New("entry not found in queue")
+
+// ExclusionImpact describes what would happen to the rest of the queue if a given entry were excluded.
+type ExclusionImpact struct {
+	// Path is the path of the entry whose exclusion is being analyzed.
+	Path string
+	// DirectDependents are the entries that declare a direct dependency on Path.
+	DirectDependents []string
+	// TransitiveDependents are all entries that depend on Path, directly or indirectly.
+	TransitiveDependents []string
+	// CascadeExcluded are the entries that would also be excluded as a consequence of excluding Path.
+	//
+	// This is only populated for "down" commands, where excluding a unit protects it from destruction and
+	// therefore also requires protecting everything it depends on (mirroring prevent_destroy behavior).
+	CascadeExcluded []string
+}
+
+// ImpactOfExcluding reports the blast radius of excluding the entry at the given path.
+//
+// This is a read-only planning tool: the queue is not modified.
+func (q *Queue) ImpactOfExcluding(path string) (ExclusionImpact, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entry := q.entryByPathUnsafe(path)
+	if entry == nil {
+		return ExclusionImpact{}, fmt.Errorf("%w: %s", ErrEntryNotFound, path)
+	}
+
+	impact := ExclusionImpact{
+		Path:                 path,
+		DirectDependents:     q.dependentsOfUnsafe(path),
+		TransitiveDependents: q.transitiveDependentsUnsafe(path),
+	}
+
+	if !entry.IsUp() {
+		impact.CascadeExcluded = q.transitiveDependenciesUnsafe(path)
+	}
+
+	return impact, nil
+}
+
+// dependentsOfUnsafe returns the sorted paths of entries that directly depend on the given path.
+// Should only be called when the caller already holds a lock.
+func (q *Queue) dependentsOfUnsafe(path string) []string {
+	dependents := []string{}
+
+	for _, other := range q.Entries {
+		for _, dep := range other.Component.Dependencies() {
+			if dep.Path() == path {
+				dependents = append(dependents, other.Component.Path())
+				break
+			}
+		}
+	}
+
+	slices.Sort(dependents)
+
+	return dependents
+}
+
+// transitiveDependentsUnsafe returns the sorted paths of all entries that depend on the given path,
+// directly or indirectly. Should only be called when the caller already holds a lock.
+func (q *Queue) transitiveDependentsUnsafe(path string) []string {
+	visited := map[string]bool{path: true}
+	pending := []string{path}
+	result := []string{}
+
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		for _, dependent := range q.dependentsOfUnsafe(current) {
+			if visited[dependent] {
+				continue
+			}
+
+			visited[dependent] = true
+			result = append(result, dependent)
+			pending = append(pending, dependent)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// transitiveDependenciesUnsafe returns the sorted paths of all queue entries the given path depends on,
+// directly or indirectly. Dependencies that are not part of the queue are skipped.
+// Should only be called when the caller already holds a lock.
+func (q *Queue) transitiveDependenciesUnsafe(path string) []string {
+	visited := map[string]bool{path: true}
+	pending := []string{path}
+	result := []string{}
+
+	for len(pending) > 0 {
+		current := q.entryByPathUnsafe(pending[0])
+		pending = pending[1:]
+
+		if current == nil {
+			continue
+		}
+
+		for _, dep := range current.Component.Dependencies() {
+			if visited[dep.Path()] || q.entryByPathUnsafe(dep.Path()) == nil {
+				continue
+			}
+
+			visited[dep.Path()] = true
+			result = append(result, dep.Path())
+			pending = append(pending, dep.Path())
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// Waves groups the queue entries into dependency layers.
+//
+// For "up" commands, an entry is placed one layer after the deepest of its dependencies in the queue.
+// For "down" commands, an entry is placed one layer after the deepest of its dependents in the queue.
+// Entries in the same wave do not depend on each other and may run concurrently, and every entry
+// only depends on entries in earlier waves. Entries within a wave keep their queue order.
+func (q *Queue) Waves() []Entries {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	levels := q.levelsUnsafe()
+
+	var waves []Entries
+
+	for _, e := range q.Entries {
+		level := levels[e.Component.Path()]
+		for len(waves) <= level {
+			waves = append(waves, Entries{})
+		}
+
+		waves[level] = append(waves[level], e)
+	}
+
+	return waves
+}
+
+// GraphDepth returns the number of dependency layers of the queue, i.e. the number of waves returned by Waves,
+// computed without any depth limit, so that callers capping the dependency depth, e.g. with
+// Discovery.WithMaxDependencyDepth, can check that their cap covers the whole graph.
+func (q *Queue) GraphDepth() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	depth := 0
+
+	for _, level := range q.levelsUnsafe() {
+		depth = max(depth, level+1)
+	}
+
+	return depth
+}
+
+// FirstUnfinishedWave returns the index of the first wave with an entry that did not succeed or get skipped,
+// e.g. to resume a staged run from a queue reloaded with UnmarshalGraph. It returns the number of waves when
+// every entry is done.
+func (q *Queue) FirstUnfinishedWave() int {
+	waves := q.Waves()
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	for i, wave := range waves {
+		for _, e := range wave {
+			if !isSatisfied(e.Status) {
+				return i
+			}
+		}
+	}
+
+	return len(waves)
+}
+
+// levelsUnsafe computes the wave index of every entry in the queue, keyed by path.
+// Should only be called when the caller already holds a lock.
+func (q *Queue) levelsUnsafe() map[string]int {
+	levels := make(map[string]int, len(q.Entries))
+
+	var levelOf func(e *Entry, depth int) int
+
+	levelOf = func(e *Entry, depth int) int {
+		if level, ok := levels[e.Component.Path()]; ok {
+			return level
+		}
+
+		// The queue is guaranteed to be acyclic, but guard against unbounded recursion anyway.
+		if depth > len(q.Entries) {
+			return 0
+		}
+
+		level := 0
+
+		if e.IsUp() {
+			for _, dep := range e.Component.Dependencies() {
+				depEntry := q.entryByPathUnsafe(dep.Path())
+				if depEntry == nil || !depEntry.IsUp() {
+					continue
+				}
+
+				level = max(level, levelOf(depEntry, depth+1)+1)
+			}
+		} else {
+			for _, other := range q.Entries {
+				if other.IsUp() || !slices.ContainsFunc(other.Component.Dependencies(), func(dep component.Component) bool {
+					return dep.Path() == e.Component.Path()
+				}) {
+					continue
+				}
+
+				level = max(level, levelOf(other, depth+1)+1)
+			}
+		}
+
+		levels[e.Component.Path()] = level
+
+		return level
+	}
+
+	for _, e := range q.Entries {
+		levelOf(e, 0)
+	}
+
+	return levels
+}
+
+// IsolatedEntries returns the sorted paths of the entries that have no dependencies and no dependents.
+//
+// Isolated entries are often the sign of a forgotten dependency block, but they may be intentional.
+func (q *Queue) IsolatedEntries() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	dependedOn := make(map[string]bool, len(q.Entries))
+
+	for _, e := range q.Entries {
+		for _, dep := range e.Component.Dependencies() {
+			dependedOn[dep.Path()] = true
+		}
+	}
+
+	isolated := []string{}
+
+	for _, e := range q.Entries {
+		if len(e.Component.Dependencies()) == 0 && !dependedOn[e.Component.Path()] {
+			isolated = append(isolated, e.Component.Path())
+		}
+	}
+
+	slices.Sort(isolated)
+
+	return isolated
+}
+
+// HighFanOutEntries returns the paths of the entries that more than threshold entries directly depend on,
+// ordered from the largest to the smallest fan-out, then by path.
+//
+// Such entries are potential bottlenecks of the run: every entry depending on them waits for them, and
+// finishing them makes all of those entries ready at once. This is an advisory analysis.
+func (q *Queue) HighFanOutEntries(threshold int) []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	fanOut := q.fanOutUnsafe()
+	high := []string{}
+
+	for _, e := range q.Entries {
+		if fanOut[e.Component.Path()] > threshold {
+			high = append(high, e.Component.Path())
+		}
+	}
+
+	slices.SortFunc(high, func(a, b string) int {
+		if fanOut[a] != fanOut[b] {
+			return fanOut[b] - fanOut[a]
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return high
+}
+
+// FanOut returns the number of entries directly depending on each entry of the queue, keyed by path. Entries
+// that no entry depends on are left out.
+func (q *Queue) FanOut() map[string]int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.fanOutUnsafe()
+}
+
+// fanOutUnsafe returns the number of entries directly depending on each entry of the queue.
+// Should only be called when the caller already holds a lock.
+func (q *Queue) fanOutUnsafe() map[string]int {
+	fanOut := make(map[string]int, len(q.Entries))
+
+	for _, e := range q.Entries {
+		for _, dep := range e.Component.Dependencies() {
+			fanOut[dep.Path()]++
+		}
+	}
+
+	return fanOut
+}
+
+// DiamondDependencies returns the diamonds of the queue: an entry D with two direct dependencies B and C
+// that both depend, directly or indirectly, on the same entry A, while neither of B and C depends on the
+// other. Each diamond is listed as [A, B, C, D], with B and C in path order, and the diamonds are sorted.
+//
+// B and C may run concurrently, after A and before D, so diamonds are worth reviewing for races when B
+// and C write to a shared resource. Only the closest shared dependency of B and C is listed, not the
+// dependencies it has itself. This is an advisory analysis: most diamonds are harmless.
+func (q *Queue) DiamondDependencies() [][]string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	transitive := make(map[string][]string, len(q.Entries))
+
+	dependenciesOf := func(path string) []string {
+		if deps, ok := transitive[path]; ok {
+			return deps
+		}
+
+		deps := q.transitiveDependenciesUnsafe(path)
+		transitive[path] = deps
+
+		return deps
+	}
+
+	diamonds := [][]string{}
+
+	for _, e := range q.Entries {
+		var direct []string
+
+		for _, dep := range e.Component.Dependencies() {
+			if q.entryByPathUnsafe(dep.Path()) != nil && !slices.Contains(direct, dep.Path()) {
+				direct = append(direct, dep.Path())
+			}
+		}
+
+		slices.Sort(direct)
+
+		for i, left := range direct {
+			for _, right := range direct[i+1:] {
+				leftDeps, rightDeps := dependenciesOf(left), dependenciesOf(right)
+
+				// A dependency between the two branches orders them, so they cannot race.
+				if slices.Contains(leftDeps, right) || slices.Contains(rightDeps, left) {
+					continue
+				}
+
+				shared := []string{}
+
+				for _, dep := range leftDeps {
+					if slices.Contains(rightDeps, dep) {
+						shared = append(shared, dep)
+					}
+				}
+
+				for _, top := range shared {
+					closest := !slices.ContainsFunc(shared, func(other string) bool {
+						return other != top && slices.Contains(dependenciesOf(other), top)
+					})
+					if closest {
+						diamonds = append(diamonds, []string{top, left, right, e.Component.Path()})
+					}
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(diamonds, func(a, b []string) int {
+		return slices.Compare(a, b)
+	})
+
+	return diamonds
+}
+
+// Progress is a snapshot of how many queue entries are in each phase of their lifecycle.
+type Progress struct {
+	// Total is the number of entries in the queue.
+	Total int
+	// Finished is the number of entries in a terminal state.
+	Finished int
+	// Running is the number of entries that are running.
+	Running int
+	// Waiting is the number of entries that have not started yet.
+	Waiting int
+}
+
+// Progress returns a consistent snapshot of the progress of the queue.
+func (q *Queue) Progress() Progress {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	p := Progress{Total: len(q.Entries)}
+
+	for _, e := range q.Entries {
+		switch {
+		case isTerminal(e.Status):
+			p.Finished++
+		case e.Status == StatusRunning:
+			p.Running++
+		default:
+			p.Waiting++
+		}
+	}
+
+	return p
+}
+
+// StuckEntry describes an entry that has not started and the entries it is still waiting on.
+type StuckEntry struct {
+	// Path is the path of the waiting entry.
+	Path string
+	// UnmetDependencies are the entries that must complete before Path can run: its dependencies for
+	// "up" commands, and its dependents for "down" commands.
+	UnmetDependencies []string
+}
+
+// StuckEntries returns the entries that have not started yet, sorted by path, along with the entries
+// each of them is still waiting on.
+func (q *Queue) StuckEntries() []StuckEntry {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	var stuck []StuckEntry
+
+	for _, e := range q.Entries {
+		if isTerminalOrRunning(e.Status) {
+			continue
+		}
+
+		waitingOn := []string{}
+
+		if e.IsUp() {
+			for _, dep := range e.Component.Dependencies() {
+				if depEntry := q.entryByPathUnsafe(dep.Path()); depEntry != nil && !isSatisfied(depEntry.Status) {
+					waitingOn = append(waitingOn, dep.Path())
+				}
+			}
+		} else {
+			for _, dependent := range q.dependentsOfUnsafe(e.Component.Path()) {
+				if depEntry := q.entryByPathUnsafe(dependent); depEntry != nil && !isSatisfied(depEntry.Status) {
+					waitingOn = append(waitingOn, dependent)
+				}
+			}
+		}
+
+		slices.Sort(waitingOn)
+
+		stuck = append(stuck, StuckEntry{Path: e.Component.Path(), UnmetDependencies: waitingOn})
+	}
+
+	slices.SortFunc(stuck, func(a, b StuckEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return stuck
+}
@@ -0,0 +1,322 @@
+package queue_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpactOfExcluding(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	//   <- D
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgA)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC, cfgD})
+	require.NoError(t, err)
+
+	impact, err := q.ImpactOfExcluding("A")
+	require.NoError(t, err)
+
+	assert.Equal(t, "A", impact.Path)
+	assert.Equal(t, []string{"B", "D"}, impact.DirectDependents)
+	assert.Equal(t, []string{"B", "C", "D"}, impact.TransitiveDependents)
+	assert.Empty(t, impact.CascadeExcluded)
+
+	impact, err = q.ImpactOfExcluding("C")
+	require.NoError(t, err)
+	assert.Empty(t, impact.DirectDependents)
+	assert.Empty(t, impact.TransitiveDependents)
+}
+
+func TestImpactOfExcluding_DestroyCascades(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	for _, cfg := range []*component.Unit{cfgA, cfgB, cfgC} {
+		cfg.SetDiscoveryContext(&component.DiscoveryContext{Cmd: "destroy"})
+	}
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+	require.NoError(t, err)
+
+	impact, err := q.ImpactOfExcluding("C")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, impact.CascadeExcluded)
+}
+
+func TestImpactOfExcluding_UnknownPath(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewQueue(component.Components{component.NewUnit("A")})
+	require.NoError(t, err)
+
+	_, err = q.ImpactOfExcluding("missing")
+	require.ErrorIs(t, err, queue.ErrEntryNotFound)
+}
+
+func TestWaves(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- D
+	//   <- C <-/
+	// E
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgA)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgB)
+	cfgD.AddDependency(cfgC)
+
+	cfgE := component.NewUnit("E")
+
+	q, err := queue.NewQueue(component.Components{cfgD, cfgC, cfgB, cfgA, cfgE})
+	require.NoError(t, err)
+
+	waves := q.Waves()
+	require.Len(t, waves, 3)
+
+	assert.Equal(t, []string{"A", "E"}, wavePaths(waves[0]))
+	assert.Equal(t, []string{"B", "C"}, wavePaths(waves[1]))
+	assert.Equal(t, []string{"D"}, wavePaths(waves[2]))
+}
+
+func TestWaves_Destroy(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	for _, cfg := range []*component.Unit{cfgA, cfgB, cfgC} {
+		cfg.SetDiscoveryContext(&component.DiscoveryContext{Cmd: "destroy"})
+	}
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+	require.NoError(t, err)
+
+	waves := q.Waves()
+	require.Len(t, waves, 3)
+
+	assert.Equal(t, []string{"C"}, wavePaths(waves[0]))
+	assert.Equal(t, []string{"B"}, wavePaths(waves[1]))
+	assert.Equal(t, []string{"A"}, wavePaths(waves[2]))
+}
+
+func TestGraphDepth(t *testing.T) {
+	t.Parallel()
+
+	// A linear chain of 50 units, each depending on the previous one.
+	const chainLength = 50
+
+	units := make(component.Components, 0, chainLength)
+
+	for i := range chainLength {
+		unit := component.NewUnit(fmt.Sprintf("unit-%02d", i))
+		if i > 0 {
+			unit.AddDependency(units[i-1])
+		}
+
+		units = append(units, unit)
+	}
+
+	q, err := queue.NewQueue(units)
+	require.NoError(t, err)
+
+	assert.Equal(t, chainLength, q.GraphDepth())
+	assert.Len(t, q.Waves(), q.GraphDepth())
+
+	empty, err := queue.NewQueue(component.Components{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.GraphDepth())
+}
+
+func TestFirstUnfinishedWave(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	//   <- D
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgA)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC, cfgD})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, q.FirstUnfinishedWave())
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+	q.SetEntryStatus(q.EntryByPath("D"), queue.StatusSkipped)
+	assert.Equal(t, 1, q.FirstUnfinishedWave())
+
+	q.SetEntryStatus(q.EntryByPath("B"), queue.StatusSucceeded)
+	q.SetEntryStatus(q.EntryByPath("C"), queue.StatusSucceeded)
+	assert.Equal(t, 3, q.FirstUnfinishedWave())
+}
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+	require.NoError(t, err)
+
+	assert.Equal(t, queue.Progress{Total: 3, Waiting: 3}, q.Progress())
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+	q.SetEntryStatus(q.EntryByPath("B"), queue.StatusRunning)
+
+	assert.Equal(t, queue.Progress{Total: 3, Finished: 1, Running: 1, Waiting: 1}, q.Progress())
+}
+
+func TestStuckEntries(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+	require.NoError(t, err)
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusRunning)
+
+	assert.Equal(t, []queue.StuckEntry{
+		{Path: "B", UnmetDependencies: []string{"A"}},
+		{Path: "C", UnmetDependencies: []string{"B"}},
+	}, q.StuckEntries())
+}
+
+func TestIsolatedEntries(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, C, D <- external
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(component.NewUnit("external"))
+
+	q, err := queue.NewQueue(component.Components{cfgD, cfgC, cfgB, cfgA})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"C"}, q.IsolatedEntries())
+}
+
+func TestHighFanOutEntries(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, C, D and B <- C, D.
+	cfgA := component.NewUnit("A")
+
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgA)
+	cfgC.AddDependency(cfgB)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgA)
+	cfgD.AddDependency(cfgB)
+
+	q, err := queue.NewQueue(component.Components{cfgD, cfgC, cfgB, cfgA})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B"}, q.HighFanOutEntries(1))
+	assert.Equal(t, []string{"A"}, q.HighFanOutEntries(2))
+	assert.Empty(t, q.HighFanOutEntries(3))
+	assert.Equal(t, map[string]int{"A": 3, "B": 2}, q.FanOut())
+}
+
+func TestDiamondDependencies(t *testing.T) {
+	t.Parallel()
+
+	// Z <- A <- B <- D
+	//        <- C <-
+	// E depends on B and on F, which depends on B itself: the branches are ordered, so this is no diamond.
+	cfgZ := component.NewUnit("Z")
+
+	cfgA := component.NewUnit("A")
+	cfgA.AddDependency(cfgZ)
+
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgA)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgC)
+	cfgD.AddDependency(cfgB)
+
+	cfgF := component.NewUnit("F")
+	cfgF.AddDependency(cfgB)
+
+	cfgE := component.NewUnit("E")
+	cfgE.AddDependency(cfgB)
+	cfgE.AddDependency(cfgF)
+
+	q, err := queue.NewQueue(component.Components{cfgE, cfgD, cfgF, cfgC, cfgB, cfgA, cfgZ})
+	require.NoError(t, err)
+
+	// Only the closest shared dependency A is listed, not Z.
+	assert.Equal(t, [][]string{{"A", "B", "C", "D"}}, q.DiamondDependencies())
+}
+
+func wavePaths(entries queue.Entries) []string {
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Component.Path())
+	}
+
+	return paths
+}
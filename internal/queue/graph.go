@@ -0,0 +1,257 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+)
+
+// graphVersion is the version of the serialized graph format written by MarshalGraph.
+const graphVersion = 1
+
+// ErrUnsupportedGraphVersion is returned when unmarshaling a graph written in an unknown format.
+var ErrUnsupportedGraphVersion = errors.New("unsupported graph version")
+
+// graph is the serialized form of a queue.
+type graph struct {
+	Version                int          `json:"version"`
+	FailFast               bool         `json:"fail_fast,omitempty"`
+	IgnoreDependencyOrder  bool         `json:"ignore_dependency_order,omitempty"`
+	IgnoreDependencyErrors bool         `json:"ignore_dependency_errors,omitempty"`
+	Entries                []graphEntry `json:"entries"`
+}
+
+// graphEntry is the serialized form of a queue entry and its component.
+type graphEntry struct {
+	Path         string         `json:"path"`
+	Kind         component.Kind `json:"kind"`
+	Status       Status         `json:"status"`
+	External     bool           `json:"external,omitempty"`
+	Excluded     bool           `json:"excluded,omitempty"`
+	Cmd          string         `json:"cmd,omitempty"`
+	Args         []string       `json:"args,omitempty"`
+	Dependencies []string       `json:"dependencies,omitempty"`
+}
+
+// MarshalGraph serializes the topology of the queue: the entries in queue order, their statuses,
+// their dependencies and the flags that affect scheduling.
+//
+// Runtime configuration such as parsed Terragrunt configs is not included.
+func (q *Queue) MarshalGraph() ([]byte, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	g := graph{
+		Version:                graphVersion,
+		FailFast:               q.FailFast,
+		IgnoreDependencyOrder:  q.IgnoreDependencyOrder,
+		IgnoreDependencyErrors: q.IgnoreDependencyErrors,
+		Entries:                make([]graphEntry, 0, len(q.Entries)),
+	}
+
+	for _, e := range q.Entries {
+		entry := graphEntry{
+			Path:     e.Component.Path(),
+			Kind:     e.Component.Kind(),
+			Status:   e.Status,
+			External: e.Component.External(),
+		}
+
+		if unit, ok := e.Component.(*component.Unit); ok {
+			entry.Excluded = unit.Excluded()
+		}
+
+		if dc := e.Component.DiscoveryContext(); dc != nil {
+			entry.Cmd = dc.Cmd
+			entry.Args = dc.Args
+		}
+
+		for _, dep := range e.Component.Dependencies() {
+			entry.Dependencies = append(entry.Dependencies, dep.Path())
+		}
+
+		g.Entries = append(g.Entries, entry)
+	}
+
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// UnmarshalGraph rebuilds a queue from the output of MarshalGraph.
+//
+// The entries keep their serialized order and statuses. Dependencies on paths that are not part of the
+// serialized queue are rebuilt as bare units outside the queue. The resulting components carry no
+// configuration, so the queue supports read-only analyses but cannot be run.
+func UnmarshalGraph(data []byte) (*Queue, error) {
+	var g graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+
+	if g.Version != graphVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedGraphVersion, g.Version)
+	}
+
+	q := &Queue{
+		Entries:                make(Entries, 0, len(g.Entries)),
+		FailFast:               g.FailFast,
+		IgnoreDependencyOrder:  g.IgnoreDependencyOrder,
+		IgnoreDependencyErrors: g.IgnoreDependencyErrors,
+	}
+
+	components := make(map[string]component.Component, len(g.Entries))
+
+	for _, ge := range g.Entries {
+		if _, ok := components[ge.Path]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrEntryExists, ge.Path)
+		}
+
+		c := newGraphComponent(ge)
+		components[ge.Path] = c
+
+		q.Entries = append(q.Entries, &Entry{Component: c, Status: ge.Status})
+	}
+
+	for _, ge := range g.Entries {
+		c := components[ge.Path]
+
+		for _, depPath := range ge.Dependencies {
+			dep, ok := components[depPath]
+			if !ok {
+				dep = component.NewUnit(depPath)
+				components[depPath] = dep
+			}
+
+			c.AddDependency(dep)
+		}
+	}
+
+	return q, nil
+}
+
+// newGraphComponent creates the bare component described by a serialized entry.
+func newGraphComponent(ge graphEntry) component.Component {
+	var c component.Component
+
+	if ge.Kind == component.StackKind {
+		c = component.NewStack(ge.Path)
+	} else {
+		unit := component.NewUnit(ge.Path)
+		unit.SetExcluded(ge.Excluded)
+		c = unit
+	}
+
+	if ge.External {
+		c.SetExternal()
+	}
+
+	if ge.Cmd != "" || len(ge.Args) > 0 {
+		c.SetDiscoveryContext(&component.DiscoveryContext{Cmd: ge.Cmd, Args: ge.Args})
+	}
+
+	return c
+}
+
+// CloneForCommand builds a new queue over bare copies of the entries' components, discovered for the
+// given command and arguments instead of their own. The copies keep the dependencies between entries,
+// while dependencies outside of the queue are copied as bare units that are not queued.
+//
+// This allows running the same graph in the other direction, e.g. a destroy before an apply, without
+// touching the components or statuses of the original queue.
+func (q *Queue) CloneForCommand(cmd string, args ...string) (*Queue, error) {
+	q.mu.RLock()
+
+	clones := make(map[string]component.Component, len(q.Entries))
+	originals := make(component.Components, 0, len(q.Entries))
+
+	for _, e := range q.Entries {
+		clone := newGraphComponent(graphEntry{
+			Path:     e.Component.Path(),
+			Kind:     e.Component.Kind(),
+			External: e.Component.External(),
+			Cmd:      cmd,
+			Args:     args,
+		})
+
+		if dc := e.Component.DiscoveryContext(); dc != nil {
+			dcCopy := dc.Copy()
+			dcCopy.Cmd = cmd
+			dcCopy.Args = args
+			clone.SetDiscoveryContext(dcCopy)
+		}
+
+		clones[e.Component.Path()] = clone
+		originals = append(originals, e.Component)
+	}
+
+	q.mu.RUnlock()
+
+	discovered := make(component.Components, 0, len(originals))
+
+	for _, original := range originals {
+		clone := clones[original.Path()]
+
+		for _, dep := range original.Dependencies() {
+			depClone, ok := clones[dep.Path()]
+			if !ok {
+				depClone = component.NewUnit(dep.Path())
+				clones[dep.Path()] = depClone
+			}
+
+			clone.AddDependency(depClone)
+		}
+
+		discovered = append(discovered, clone)
+	}
+
+	clone, err := NewQueue(discovered)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.FailFast = q.FailFast
+	clone.IgnoreDependencyOrder = q.IgnoreDependencyOrder
+	clone.IgnoreDependencyErrors = q.IgnoreDependencyErrors
+
+	return clone, nil
+}
+
+// TransitiveReduction removes the dependency edges between queue entries that are implied by other edges,
+// e.g. the direct dependency of A on C when A also depends on B and B depends on C, and returns the
+// number of removed edges.
+//
+// Reachability between entries is unchanged, so the queue schedules the entries in the same order, while
+// exports of the graph get less cluttered. Dependencies on components outside of the queue are kept, as
+// they are not reachable through other entries. The components of the entries are modified in place.
+func (q *Queue) TransitiveReduction() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	removed := 0
+
+	for _, e := range q.Entries {
+		deps := e.Component.Dependencies()
+
+		for _, dep := range deps {
+			if q.entryByPathUnsafe(dep.Path()) == nil {
+				continue
+			}
+
+			redundant := slices.ContainsFunc(deps, func(other component.Component) bool {
+				return other != dep &&
+					slices.Contains(q.transitiveDependenciesUnsafe(other.Path()), dep.Path())
+			})
+			if !redundant {
+				continue
+			}
+
+			e.Component.RemoveDependency(dep)
+
+			removed++
+		}
+	}
+
+	return removed
+}
@@ -0,0 +1,159 @@
+package queue_test
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalGraphRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	//   <- D
+	// B also depends on an external unit X that is not in the queue.
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+	cfgB.AddDependency(component.NewUnit("X"))
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgA)
+	cfgD.SetExcluded(true)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC, cfgD})
+	require.NoError(t, err)
+
+	q.FailFast = true
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+
+	data, err := q.MarshalGraph()
+	require.NoError(t, err)
+
+	reloaded, err := queue.UnmarshalGraph(data)
+	require.NoError(t, err)
+
+	assert.True(t, reloaded.FailFast)
+	assert.Equal(t, q.Components().Paths(), reloaded.Components().Paths())
+	assert.Equal(t, queue.StatusSucceeded, reloaded.EntryByPath("A").Status)
+	assert.Nil(t, reloaded.EntryByPath("X"))
+	assert.ElementsMatch(t, []string{"A", "X"}, reloaded.EntryByPath("B").Component.Dependencies().Paths())
+
+	unitD, ok := reloaded.EntryByPath("D").Component.(*component.Unit)
+	require.True(t, ok)
+	assert.True(t, unitD.Excluded())
+
+	// Read-only analyses produce the same results on the reloaded queue.
+	want, err := q.ImpactOfExcluding("A")
+	require.NoError(t, err)
+
+	got, err := reloaded.ImpactOfExcluding("A")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	require.Len(t, reloaded.Waves(), len(q.Waves()))
+
+	for i, wave := range q.Waves() {
+		assert.Equal(t, wavePaths(wave), wavePaths(reloaded.Waves()[i]))
+	}
+
+	again, err := reloaded.MarshalGraph()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+func TestUnmarshalGraph_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := queue.UnmarshalGraph([]byte(`{"version": 99, "entries": []}`))
+	require.ErrorIs(t, err, queue.ErrUnsupportedGraphVersion)
+
+	_, err = queue.UnmarshalGraph([]byte(`{"version": 1, "entries": [{"path": "A"}, {"path": "A"}]}`))
+	require.ErrorIs(t, err, queue.ErrEntryExists)
+}
+
+func TestCloneForCommand(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+
+	for _, cfg := range []*component.Unit{cfgA, cfgB, cfgC} {
+		cfg.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: "/root", Cmd: "apply"})
+	}
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+	require.NoError(t, err)
+
+	destroy, err := q.CloneForCommand("destroy")
+	require.NoError(t, err)
+
+	waves := destroy.Waves()
+	require.Len(t, waves, 3)
+	assert.Equal(t, []string{"C"}, wavePaths(waves[0]))
+	assert.Equal(t, []string{"A"}, wavePaths(waves[2]))
+
+	clonedA := destroy.EntryByPath("A").Component
+	assert.NotSame(t, cfgA, clonedA)
+	assert.Equal(t, "destroy", clonedA.DiscoveryContext().Cmd)
+	assert.Equal(t, "/root", clonedA.DiscoveryContext().WorkingDir)
+
+	// The original queue and its components are untouched.
+	destroy.SetEntryStatus(destroy.EntryByPath("C"), queue.StatusSucceeded)
+	assert.Equal(t, queue.StatusReady, q.EntryByPath("C").Status)
+	assert.Equal(t, "apply", cfgA.DiscoveryContext().Cmd)
+	assert.Len(t, cfgA.Dependents(), 1)
+	assert.Equal(t, []string{"A"}, wavePaths(q.Waves()[0]))
+}
+
+func TestTransitiveReduction(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C, and C also depends on A directly.
+	// D depends on A and B, and on an external unit X that B depends on as well. The edge to X is kept,
+	// as X is not part of the queue.
+	cfgA := component.NewUnit("A")
+	cfgX := component.NewUnit("X")
+
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+	cfgB.AddDependency(cfgX)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgB)
+	cfgC.AddDependency(cfgA)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgA)
+	cfgD.AddDependency(cfgB)
+	cfgD.AddDependency(cfgX)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC, cfgD})
+	require.NoError(t, err)
+
+	wavesBefore := q.Waves()
+
+	assert.Equal(t, 2, q.TransitiveReduction())
+
+	assert.Equal(t, []string{"B"}, cfgC.Dependencies().Paths())
+	assert.ElementsMatch(t, []string{"B", "X"}, cfgD.Dependencies().Paths())
+	assert.ElementsMatch(t, []string{"A", "X"}, cfgB.Dependencies().Paths())
+	assert.Equal(t, []string{"B"}, cfgA.Dependents().Paths())
+
+	// Scheduling is unchanged.
+	assert.Equal(t, wavesBefore, q.Waves())
+
+	// The reduced graph has no redundant edges left.
+	assert.Zero(t, q.TransitiveReduction())
+}
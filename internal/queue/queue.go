@@ -24,6 +24,7 @@ package queue
 
 import (
 	"errors"
+	"fmt"
 	"slices"
 	"sort"
 	"sync"
@@ -58,6 +59,7 @@ const (
 	StatusSucceeded
 	StatusFailed
 	StatusEarlyExit // Terminal status set on Entries in case of fail fast mode
+	StatusSkipped   // Terminal status set on Entries that were deliberately not run
 )
 
 // UpdateBlocked updates the status of the entry to blocked, if it is blocked.
@@ -180,6 +182,15 @@ func (q *Queue) Components() component.Components {
 	return result
 }
 
+// Snapshot returns a copy of the entries of the queue, which is safe to range over while entries are added to
+// a running queue. The entries themselves are shared with the queue.
+func (q *Queue) Snapshot() Entries {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return slices.Clone(q.Entries)
+}
+
 // EntryByPath returns the entry with the given config path, or nil if not found.
 func (q *Queue) EntryByPath(path string) *Entry {
 	q.mu.RLock()
@@ -188,6 +199,20 @@ func (q *Queue) EntryByPath(path string) *Entry {
 	return q.entryByPathUnsafe(path)
 }
 
+// EntryStatus returns the status of the entry with the given config path, read under the queue lock so that it is
+// safe while the queue is running, and false if not found.
+func (q *Queue) EntryStatus(path string) (Status, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entry := q.entryByPathUnsafe(path)
+	if entry == nil {
+		return StatusPending, false
+	}
+
+	return entry.Status, true
+}
+
 // entryByPathUnsafe returns the entry with the given config path without locking.
 // Should only be called when the caller already holds a lock.
 func (q *Queue) entryByPathUnsafe(path string) *Entry {
@@ -302,6 +327,31 @@ func (q *Queue) GetReadyWithDependencies(l log.Logger) []*Entry {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
+	return q.readyUnsafe(l)
+}
+
+// ReadyEntries returns the paths of the entries that can be dispatched right now, in queue order: the entries
+// that have not started yet and whose dependencies (dependents for destroy commands) have all succeeded.
+//
+// This allows an external scheduler to drive the dispatch of the queue itself. Dependencies that are not part
+// of the queue, such as units assumed to be already applied, count as satisfied.
+func (q *Queue) ReadyEntries() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	ready := q.readyUnsafe(nil)
+	paths := make([]string, 0, len(ready))
+
+	for _, e := range ready {
+		paths = append(paths, e.Component.Path())
+	}
+
+	return paths
+}
+
+// readyUnsafe returns the entries that are ready to run, logging why dependencies are considered ready when
+// a logger is given. Should only be called when the caller already holds a read lock.
+func (q *Queue) readyUnsafe(l log.Logger) []*Entry {
 	if q.IgnoreDependencyOrder {
 		out := make([]*Entry, 0, len(q.Entries))
 
@@ -345,7 +395,9 @@ func (q *Queue) areDependenciesReadyUnsafe(l log.Logger, e *Entry) bool {
 	for _, dep := range e.Component.Dependencies() {
 		depEntry := q.entryByPathUnsafe(dep.Path())
 		if depEntry == nil {
-			l.Debugf("Dependency %s is not in queue, considering it ready", dep.Path())
+			if l != nil {
+				l.Debugf("Dependency %s is not in queue, considering it ready", dep.Path())
+			}
 
 			continue
 		}
@@ -360,7 +412,7 @@ func (q *Queue) areDependenciesReadyUnsafe(l log.Logger, e *Entry) bool {
 			continue
 		}
 
-		if depEntry.Status != StatusSucceeded {
+		if !isSatisfied(depEntry.Status) {
 			return false
 		}
 	}
@@ -389,7 +441,7 @@ func (q *Queue) areDependentsReadyUnsafe(e *Entry) bool {
 					continue
 				}
 
-				if other.Status != StatusSucceeded {
+				if !isSatisfied(other.Status) {
 					return false
 				}
 			}
@@ -444,15 +496,38 @@ func (q *Queue) FailEntry(e *Entry) {
 	}
 
 	if e.IsUp() {
-		q.earlyExitDependents(e)
+		q.markDependents(e, StatusEarlyExit)
+		return
+	}
+
+	q.markDependencies(e, StatusEarlyExit)
+}
+
+// SkipEntry marks the entry as skipped, meaning it was deliberately not run.
+//
+// When propagate is true, the entries waiting on this one are skipped as well: its dependents for up commands,
+// and its dependencies for destroy/down commands. Otherwise, a skipped entry satisfies the entries waiting
+// on it, just like a succeeded one.
+func (q *Queue) SkipEntry(e *Entry, propagate bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e.Status = StatusSkipped
+
+	if !propagate {
+		return
+	}
+
+	if e.IsUp() {
+		q.markDependents(e, StatusSkipped)
 		return
 	}
 
-	q.earlyExitDependencies(e)
+	q.markDependencies(e, StatusSkipped)
 }
 
-// earlyExitDependents - Recursively mark all entries that are dependent on this one as early exit.
-func (q *Queue) earlyExitDependents(e *Entry) {
+// markDependents - Recursively mark all entries that are dependent on this one with the given status.
+func (q *Queue) markDependents(e *Entry, status Status) {
 	for _, entry := range q.Entries {
 		if len(entry.Component.Dependencies()) == 0 {
 			continue
@@ -464,9 +539,9 @@ func (q *Queue) earlyExitDependents(e *Entry) {
 					continue
 				}
 
-				entry.Status = StatusEarlyExit
+				entry.Status = status
 
-				q.earlyExitDependents(entry)
+				q.markDependents(entry, status)
 
 				break
 			}
@@ -474,8 +549,8 @@ func (q *Queue) earlyExitDependents(e *Entry) {
 	}
 }
 
-// earlyExitDependencies - Recursively mark all entries that are dependencies on this one as early exit.
-func (q *Queue) earlyExitDependencies(e *Entry) {
+// markDependencies - Recursively mark all entries that are dependencies on this one with the given status.
+func (q *Queue) markDependencies(e *Entry, status Status) {
 	if len(e.Component.Dependencies()) == 0 {
 		return
 	}
@@ -490,8 +565,8 @@ func (q *Queue) earlyExitDependencies(e *Entry) {
 			continue
 		}
 
-		depEntry.Status = StatusEarlyExit
-		q.earlyExitDependencies(depEntry)
+		depEntry.Status = status
+		q.markDependencies(depEntry, status)
 	}
 }
 
@@ -509,6 +584,139 @@ func (q *Queue) Finished() bool {
 	return true
 }
 
+// AllFinished checks if all the given entries are in a terminal state.
+func (q *Queue) AllFinished(entries Entries) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	for _, e := range entries {
+		if !isTerminal(e.Status) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EarlyExitRemaining marks every entry that has not started yet as early exit.
+// Entries that are running or already in a terminal state are left untouched.
+func (q *Queue) EarlyExitRemaining() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, e := range q.Entries {
+		if isTerminalOrRunning(e.Status) {
+			continue
+		}
+
+		e.Status = StatusEarlyExit
+	}
+}
+
+// FailRemaining marks every entry that has not started yet as failed, without propagating the failures,
+// and returns them. Entries that are running or already in a terminal state are left untouched.
+func (q *Queue) FailRemaining() []*Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var failed []*Entry
+
+	for _, e := range q.Entries {
+		if isTerminalOrRunning(e.Status) {
+			continue
+		}
+
+		e.Status = StatusFailed
+		failed = append(failed, e)
+	}
+
+	return failed
+}
+
+// ErrEntryExists is returned when adding an entry whose path is already present in the queue.
+var ErrEntryExists = errors.New("entry already exists in queue")
+
+// ErrCycle is returned when adding an entry would introduce a dependency cycle.
+var ErrCycle = errors.New("dependency cycle")
+
+// AddEntry inserts a new entry for the given component into a queue that may already be running.
+//
+// Every dependency of the component must already be in the queue, and the addition must not introduce a
+// cycle. The entry is added as ready, so it is picked up by GetReadyWithDependencies as soon as its
+// dependencies (or, for "down" commands, its dependents) have succeeded. If those have already failed,
+// the entry is immediately marked as early exit, unless dependency errors are ignored.
+func (q *Queue) AddEntry(cfg component.Component) (*Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.entryByPathUnsafe(cfg.Path()) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEntryExists, cfg.Path())
+	}
+
+	dependencies := map[string]bool{}
+
+	for _, dep := range cfg.Dependencies() {
+		if q.entryByPathUnsafe(dep.Path()) == nil {
+			return nil, fmt.Errorf("%w: %s depends on %s", ErrEntryNotFound, cfg.Path(), dep.Path())
+		}
+
+		dependencies[dep.Path()] = true
+
+		for _, transitive := range q.transitiveDependenciesUnsafe(dep.Path()) {
+			dependencies[transitive] = true
+		}
+	}
+
+	for _, dependent := range q.transitiveDependentsUnsafe(cfg.Path()) {
+		if dependencies[dependent] {
+			return nil, fmt.Errorf("%w: adding %s would create a cycle through %s", ErrCycle, cfg.Path(), dependent)
+		}
+	}
+
+	entry := &Entry{
+		Component: cfg,
+		Status:    StatusReady,
+	}
+
+	if q.blockedByFailureUnsafe(entry) {
+		entry.Status = StatusEarlyExit
+	}
+
+	q.Entries = append(q.Entries, entry)
+
+	return entry, nil
+}
+
+// blockedByFailureUnsafe checks whether the entry can never become ready because an entry it waits on
+// has already failed or exited early. Should only be called when the caller already holds a lock.
+func (q *Queue) blockedByFailureUnsafe(e *Entry) bool {
+	if q.IgnoreDependencyErrors {
+		return false
+	}
+
+	failed := func(status Status) bool {
+		return status == StatusFailed || status == StatusEarlyExit
+	}
+
+	if e.IsUp() {
+		for _, dep := range e.Component.Dependencies() {
+			if depEntry := q.entryByPathUnsafe(dep.Path()); depEntry != nil && failed(depEntry.Status) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, dependent := range q.dependentsOfUnsafe(e.Component.Path()) {
+		if depEntry := q.entryByPathUnsafe(dependent); depEntry != nil && failed(depEntry.Status) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // RemainingDeps Helper to calculate remaining dependencies for an entry.
 func (q *Queue) RemainingDeps(e *Entry) int {
 	if e.Component == nil || len(e.Component.Dependencies()) == 0 {
@@ -522,7 +730,7 @@ func (q *Queue) RemainingDeps(e *Entry) int {
 
 	for _, dep := range e.Component.Dependencies() {
 		depEntry := q.entryByPathUnsafe(dep.Path())
-		if depEntry == nil || depEntry.Status != StatusSucceeded {
+		if depEntry == nil || !isSatisfied(depEntry.Status) {
 			count++
 		}
 	}
@@ -535,13 +743,18 @@ func isTerminal(status Status) bool {
 	switch status {
 	case StatusPending, StatusBlocked, StatusUnsorted, StatusReady, StatusRunning:
 		return false
-	case StatusSucceeded, StatusFailed, StatusEarlyExit:
+	case StatusSucceeded, StatusFailed, StatusEarlyExit, StatusSkipped:
 		return true
 	}
 
 	return false
 }
 
+// isSatisfied returns true if the status allows the entries waiting on it to run.
+func isSatisfied(status Status) bool {
+	return status == StatusSucceeded || status == StatusSkipped
+}
+
 // isTerminalOrRunning returns true if the status is terminal or running.
 func isTerminalOrRunning(status Status) bool {
 	return status == StatusRunning || isTerminal(status)
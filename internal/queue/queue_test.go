@@ -2,6 +2,7 @@ package queue_test
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/gruntwork-io/terragrunt/internal/component"
@@ -1051,3 +1052,203 @@ func TestSetEntryStatus_TerminalGuard(t *testing.T) {
 		})
 	}
 }
+
+func TestAddEntry(t *testing.T) {
+	t.Parallel()
+
+	cfgA := component.NewUnit("A")
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB})
+	require.NoError(t, err)
+
+	l := logger.CreateLogger()
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgA)
+
+	entryC, err := q.AddEntry(cfgC)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusReady, entryC.Status)
+
+	// C only becomes ready once A has succeeded.
+	assert.Equal(t, []string{"A"}, wavePaths(q.GetReadyWithDependencies(l)))
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+	assert.Equal(t, []string{"B", "C"}, wavePaths(q.GetReadyWithDependencies(l)))
+
+	_, err = q.AddEntry(component.NewUnit("C"))
+	require.ErrorIs(t, err, queue.ErrEntryExists)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(component.NewUnit("missing"))
+
+	_, err = q.AddEntry(cfgD)
+	require.ErrorIs(t, err, queue.ErrEntryNotFound)
+}
+
+func TestSnapshot_ConcurrentAddEntry(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewQueue(component.Components{component.NewUnit("A")})
+	require.NoError(t, err)
+
+	snapshot := q.Snapshot()
+
+	var wg sync.WaitGroup
+
+	for i := range 10 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := q.AddEntry(component.NewUnit(fmt.Sprintf("unit-%d", i)))
+			assert.NoError(t, err)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for _, entry := range q.Snapshot() {
+				assert.NotNil(t, entry.Component)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// A snapshot is not affected by the entries added after it was taken.
+	assert.Len(t, snapshot, 1)
+	assert.Len(t, q.Snapshot(), 11)
+}
+
+func TestEntryStatus(t *testing.T) {
+	t.Parallel()
+
+	q, err := queue.NewQueue(component.Components{component.NewUnit("A")})
+	require.NoError(t, err)
+
+	q.FailEntry(q.EntryByPath("A"))
+
+	status, ok := q.EntryStatus("A")
+	assert.True(t, ok)
+	assert.Equal(t, queue.StatusFailed, status)
+
+	_, ok = q.EntryStatus("missing")
+	assert.False(t, ok)
+}
+
+func TestAddEntry_RejectsCycle(t *testing.T) {
+	t.Parallel()
+
+	// A declares a dependency on X, which is not in the queue yet.
+	cfgX := component.NewUnit("X")
+	cfgA := component.NewUnit("A")
+	cfgA.AddDependency(cfgX)
+
+	q, err := queue.NewQueue(component.Components{cfgA})
+	require.NoError(t, err)
+
+	// Adding X with a dependency on A closes the loop.
+	cfgX.AddDependency(cfgA)
+
+	_, err = q.AddEntry(cfgX)
+	require.ErrorIs(t, err, queue.ErrCycle)
+}
+
+func TestAddEntry_FailedDependencyExitsEarly(t *testing.T) {
+	t.Parallel()
+
+	cfgA := component.NewUnit("A")
+
+	q, err := queue.NewQueue(component.Components{cfgA})
+	require.NoError(t, err)
+
+	q.FailEntry(q.EntryByPath("A"))
+
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	entryB, err := q.AddEntry(cfgB)
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusEarlyExit, entryB.Status)
+	assert.True(t, q.Finished())
+}
+
+func TestSkipEntry(t *testing.T) {
+	t.Parallel()
+
+	for _, propagate := range []bool{true, false} {
+		t.Run(fmt.Sprintf("propagate_%t", propagate), func(t *testing.T) {
+			t.Parallel()
+
+			// A -> B -> C
+			cfgA := component.NewUnit("A")
+			cfgB := component.NewUnit("B")
+			cfgB.AddDependency(cfgA)
+
+			cfgC := component.NewUnit("C")
+			cfgC.AddDependency(cfgB)
+
+			q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC})
+			require.NoError(t, err)
+
+			l := logger.CreateLogger()
+
+			q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+			q.SkipEntry(q.EntryByPath("B"), propagate)
+
+			assert.Equal(t, queue.StatusSkipped, q.EntryByPath("B").Status)
+
+			if propagate {
+				assert.Equal(t, queue.StatusSkipped, q.EntryByPath("C").Status)
+				assert.True(t, q.Finished())
+
+				return
+			}
+
+			// A skipped dependency satisfies its dependents.
+			assert.Equal(t, []string{"C"}, wavePaths(q.GetReadyWithDependencies(l)))
+		})
+	}
+}
+
+func TestReadyEntries(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> D, A -> C -> D, where A depends on a unit outside of the queue assumed to be already applied.
+	applied := component.NewUnit("applied")
+
+	cfgA := component.NewUnit("A")
+	cfgA.AddDependency(applied)
+
+	cfgB := component.NewUnit("B")
+	cfgB.AddDependency(cfgA)
+
+	cfgC := component.NewUnit("C")
+	cfgC.AddDependency(cfgA)
+
+	cfgD := component.NewUnit("D")
+	cfgD.AddDependency(cfgB)
+	cfgD.AddDependency(cfgC)
+
+	q, err := queue.NewQueue(component.Components{cfgA, cfgB, cfgC, cfgD})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A"}, q.ReadyEntries())
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusRunning)
+	assert.Empty(t, q.ReadyEntries())
+
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusSucceeded)
+	assert.Equal(t, []string{"B", "C"}, q.ReadyEntries())
+
+	q.SetEntryStatus(q.EntryByPath("B"), queue.StatusSucceeded)
+	q.SetEntryStatus(q.EntryByPath("C"), queue.StatusRunning)
+	assert.Empty(t, q.ReadyEntries())
+
+	q.SetEntryStatus(q.EntryByPath("C"), queue.StatusSucceeded)
+	assert.Equal(t, []string{"D"}, q.ReadyEntries())
+}
@@ -56,8 +56,19 @@ func (cfg *Config) Validate() error {
 
 // GenerateOpenTofuCode generates the OpenTofu/Terraform code for configuring remote state backend.
 func (cfg *Config) GenerateOpenTofuCode(l log.Logger, workingDir string, backendConfig map[string]any) error {
+	codegenConfig, err := cfg.generateConfig(l, backendConfig)
+	if err != nil {
+		return err
+	}
+
+	return codegen.WriteToFile(l, workingDir, codegenConfig)
+}
+
+// generateConfig returns the code generation config of the OpenTofu/Terraform code configuring the remote state
+// backend.
+func (cfg *Config) generateConfig(l log.Logger, backendConfig map[string]any) (*codegen.GenerateConfig, error) {
 	if cfg.Generate == nil {
-		return errors.New(ErrGenerateCalledWithNoGenerateAttr)
+		return nil, errors.New(ErrGenerateCalledWithNoGenerateAttr)
 	}
 
 	switch {
@@ -68,30 +79,28 @@ func (cfg *Config) GenerateOpenTofuCode(l log.Logger, workingDir string, backend
 	default:
 		_, ok := cfg.Encryption[codegen.EncryptionKeyProviderKey].(string)
 		if !ok {
-			return errors.New("key_provider not found in encryption config")
+			return nil, errors.New("key_provider not found in encryption config")
 		}
 	}
 
 	// Convert the IfExists setting to the internal enum representation before calling generate.
 	ifExistsEnum, err := codegen.GenerateConfigExistsFromString(cfg.Generate.IfExists)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	configBytes, err := codegen.RemoteStateConfigToTerraformCode(cfg.BackendName, backendConfig, cfg.Encryption)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	codegenConfig := codegen.GenerateConfig{
+	return &codegen.GenerateConfig{
 		Path:          cfg.Generate.Path,
 		IfExists:      ifExistsEnum,
 		IfExistsStr:   cfg.Generate.IfExists,
 		Contents:      string(configBytes),
 		CommentPrefix: codegen.DefaultCommentPrefix,
-	}
-
-	return codegen.WriteToFile(l, workingDir, &codegenConfig)
+	}, nil
 }
 
 type ConfigFileGenerate struct {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gruntwork-io/terragrunt/internal/codegen"
 	"github.com/gruntwork-io/terragrunt/internal/errors"
 	"github.com/gruntwork-io/terragrunt/internal/hclhelper"
 	"github.com/gruntwork-io/terragrunt/internal/remotestate/backend"
@@ -173,6 +174,13 @@ func (remote *RemoteState) GenerateOpenTofuCode(l log.Logger, workingDir string)
 	return remote.Config.GenerateOpenTofuCode(l, workingDir, backendConfig)
 }
 
+// GenerateConfig returns the code generation config of the OpenTofu/Terraform code GenerateOpenTofuCode writes.
+func (remote *RemoteState) GenerateConfig(l log.Logger) (*codegen.GenerateConfig, error) {
+	backendConfig := remote.backend.GetTFInitArgs(remote.BackendConfig)
+
+	return remote.generateConfig(l, backendConfig)
+}
+
 func (remote *RemoteState) pullState(ctx context.Context, l log.Logger, tfOpts *tf.TFOptions) (string, error) {
 	l.Debugf("Pulling state from %s backend", remote.BackendName)
 
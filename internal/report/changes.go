@@ -0,0 +1,190 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ChangeCounts captures the number of resource changes planned for a run.
+type ChangeCounts struct {
+	Add     int
+	Change  int
+	Destroy int
+}
+
+// Total returns the total number of planned resource changes.
+func (c ChangeCounts) Total() int {
+	return c.Add + c.Change + c.Destroy
+}
+
+// String renders the change counts in a compact form, e.g. "+3 ~1 -0", or "no changes".
+func (c ChangeCounts) String() string {
+	if c.Total() == 0 {
+		return "no changes"
+	}
+
+	return fmt.Sprintf("+%d ~%d -%d", c.Add, c.Change, c.Destroy)
+}
+
+// WithChangeCounts sets the planned resource change counts of a run.
+func WithChangeCounts(counts ChangeCounts) EndOption {
+	return func(run *Run) {
+		run.Changes = &counts
+	}
+}
+
+// ChangeSummary returns the planned resource change counts of every run that has them, keyed by run path.
+func (r *Report) ChangeSummary() map[string]ChangeCounts {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	summary := make(map[string]ChangeCounts, len(r.Runs))
+
+	for _, run := range r.Runs {
+		run.mu.RLock()
+
+		if run.Changes != nil {
+			summary[run.Path] = *run.Changes
+		}
+
+		run.mu.RUnlock()
+	}
+
+	return summary
+}
+
+// OnlyChanged returns a new report with the same settings as this one, holding only the runs whose plan
+// has at least one resource change. Runs without planned change counts are left out as well.
+//
+// The runs are shared with this report, not copied.
+func (r *Report) OnlyChanged() *Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changed := &Report{
+		workingDir:           r.workingDir,
+		pathDisplay:          r.pathDisplay,
+		format:               r.format,
+		shouldColor:          r.shouldColor,
+		showUnitLevelSummary: r.showUnitLevelSummary,
+		Runs:                 make([]*Run, 0, len(r.Runs)),
+	}
+
+	for _, run := range r.Runs {
+		run.mu.RLock()
+		hasChanges := run.Changes != nil && run.Changes.Total() > 0
+		run.mu.RUnlock()
+
+		if hasChanges {
+			changed.Runs = append(changed.Runs, run)
+		}
+	}
+
+	return changed
+}
+
+// WriteChangesCSV writes the planned resource changes and the duration of every run to a writer in CSV
+// format, e.g. for capacity planning in spreadsheets.
+//
+// The columns are Name, Add, Change, Destroy and Duration, the duration being in seconds. The change counts
+// of runs without a plan, e.g. failed or excluded runs, are left empty.
+func (r *Report) WriteChangesCSV(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	csvWriter := csv.NewWriter(w)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write([]string{"Name", "Add", "Change", "Destroy", "Duration"}); err != nil {
+		return err
+	}
+
+	for _, run := range r.Runs {
+		if err := csvWriter.Write(r.changesRecord(run)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changesRecord returns the record of the given run in a changes CSV report.
+func (r *Report) changesRecord(run *Run) []string {
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+
+	record := []string{r.nameOfRun(run), "", "", ""}
+
+	if run.Changes != nil {
+		record[1] = strconv.Itoa(run.Changes.Add)
+		record[2] = strconv.Itoa(run.Changes.Change)
+		record[3] = strconv.Itoa(run.Changes.Destroy)
+	}
+
+	return append(record, junitSeconds(run.Ended.Sub(run.Started)))
+}
+
+// UnitChanges associates the planned resource change counts with the path of a run.
+type UnitChanges struct {
+	Path string
+	ChangeCounts
+}
+
+// ChangeLimitError is returned when the total number of planned resource changes exceeds a limit.
+type ChangeLimitError struct {
+	// Top lists the runs contributing the most changes, largest first.
+	Top   []UnitChanges
+	Total int
+	Limit int
+}
+
+func (e ChangeLimitError) Error() string {
+	contributors := make([]string, 0, len(e.Top))
+	for _, unit := range e.Top {
+		contributors = append(contributors, fmt.Sprintf("%s (%s)", unit.Path, unit.ChangeCounts))
+	}
+
+	return fmt.Sprintf(
+		"%d planned resource changes exceed the limit of %d; top contributors: %s",
+		e.Total, e.Limit, strings.Join(contributors, ", "),
+	)
+}
+
+// CheckChangeLimit returns a ChangeLimitError if the total number of planned resource changes across all runs
+// exceeds the given limit. The error lists at most top runs, ordered by their number of changes.
+func (r *Report) CheckChangeLimit(limit, top int) error {
+	summary := r.ChangeSummary()
+
+	units := make([]UnitChanges, 0, len(summary))
+	total := 0
+
+	for path, counts := range summary {
+		total += counts.Total()
+
+		if counts.Total() > 0 {
+			units = append(units, UnitChanges{Path: path, ChangeCounts: counts})
+		}
+	}
+
+	if total <= limit {
+		return nil
+	}
+
+	slices.SortFunc(units, func(a, b UnitChanges) int {
+		if a.Total() != b.Total() {
+			return b.Total() - a.Total()
+		}
+
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if len(units) > top {
+		units = units[:top]
+	}
+
+	return ChangeLimitError{Total: total, Limit: limit, Top: units}
+}
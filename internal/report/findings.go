@@ -0,0 +1,167 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// sarifVersion is the version of the SARIF format written by WriteFindings.
+	sarifVersion = "2.1.0"
+	// sarifSchema is the JSON schema of the SARIF format written by WriteFindings.
+	sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// FindingRule identifies the kind of a finding.
+type FindingRule string
+
+const (
+	// FindingDestructiveChange is reported for runs whose plan destroys resources.
+	FindingDestructiveChange FindingRule = "destructive-change"
+	// FindingRunFailed is reported for runs that failed.
+	FindingRunFailed FindingRule = "run-failed"
+)
+
+// findingRules describes every rule findings can be reported for, in the order they are listed in the output.
+var findingRules = []sarifRule{
+	{
+		ID:               string(FindingDestructiveChange),
+		ShortDescription: sarifText{Text: "The plan of the unit destroys resources."},
+	},
+	{
+		ID:               string(FindingRunFailed),
+		ShortDescription: sarifText{Text: "The run of the unit failed."},
+	},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ShortDescription sarifText `json:"shortDescription"`
+	ID               string    `json:"id"`
+}
+
+type sarifResult struct {
+	Message   sarifText       `json:"message"`
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteFindings writes the findings of the report to a writer in the SARIF format, so that they can be
+// consumed by security dashboards and used to gate merges.
+//
+// A destructive change finding is reported for every run whose plan destroys resources, and a run failed
+// finding for every failed run. Failures of quarantined units are reported as warnings, every other
+// finding as an error. Each finding is located at the unit directory, relative to the working directory.
+func (r *Report) WriteFindings(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := []sarifResult{}
+
+	for _, run := range r.Runs {
+		results = append(results, r.runFindings(run)...)
+	}
+
+	jsonBytes, err := json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "terragrunt", Rules: findingRules}},
+			Results: results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	jsonBytes = append(jsonBytes, '\n')
+
+	_, err = w.Write(jsonBytes)
+
+	return err
+}
+
+// runFindings returns the findings of the given run.
+func (r *Report) runFindings(run *Run) []sarifResult {
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+
+	name := r.nameOfRun(run)
+
+	var results []sarifResult
+
+	if run.Changes != nil && run.Changes.Destroy > 0 {
+		results = append(results, newSarifResult(
+			FindingDestructiveChange,
+			"error",
+			name,
+			fmt.Sprintf("The plan of unit %s destroys %d resources (%s).", name, run.Changes.Destroy, run.Changes),
+		))
+	}
+
+	if run.Result != ResultFailed {
+		return results
+	}
+
+	level := "error"
+	if run.Reason != nil && *run.Reason == ReasonQuarantined {
+		level = "warning"
+	}
+
+	message := fmt.Sprintf("The run of unit %s failed.", name)
+	if run.Cause != nil {
+		message = fmt.Sprintf("The run of unit %s failed: %s", name, *run.Cause)
+	}
+
+	results = append(results, newSarifResult(FindingRunFailed, level, name, message))
+
+	return results
+}
+
+func newSarifResult(rule FindingRule, level, name, message string) sarifResult {
+	return sarifResult{
+		RuleID:  string(rule),
+		Level:   level,
+		Message: sarifText{Text: message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: name}},
+		}},
+	}
+}
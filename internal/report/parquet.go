@@ -0,0 +1,144 @@
+package report
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	// ParquetSchemaVersion is the version of the schema of the Parquet reports written by WriteParquet.
+	// It is bumped whenever a column is changed or removed, so that downstream pipelines can evolve.
+	ParquetSchemaVersion = 1
+	// ParquetSchemaVersionKey is the key of the file metadata holding the schema version of a Parquet report.
+	ParquetSchemaVersionKey = "terragrunt.report.schema_version"
+)
+
+// ParquetRun is a row of a Parquet report.
+type ParquetRun struct {
+	Started    time.Time `parquet:"started,timestamp(millisecond)"`
+	Ended      time.Time `parquet:"ended,timestamp(millisecond)"`
+	Reason     *string   `parquet:"reason,optional"`
+	Cause      *string   `parquet:"cause,optional"`
+	Add        *int64    `parquet:"add,optional"`
+	Change     *int64    `parquet:"change,optional"`
+	Destroy    *int64    `parquet:"destroy,optional"`
+	Name       string    `parquet:"name"`
+	Result     string    `parquet:"result"`
+	Ref        string    `parquet:"ref"`
+	Cmd        string    `parquet:"cmd"`
+	Args       []string  `parquet:"args,list"`
+	DurationMS int64     `parquet:"duration_ms"`
+}
+
+// parquetSchema is the schema of the Parquet reports, following the fields of ParquetRun.
+var parquetSchema = []parquetSchemaNode{
+	{name: "report", group: true, root: true, numChildren: 13}, //nolint:mnd
+	{name: "started", typ: parquetInt64, repetition: parquetRequired, logicalType: parquetLogicalTimestampMillis},
+	{name: "ended", typ: parquetInt64, repetition: parquetRequired, logicalType: parquetLogicalTimestampMillis},
+	{name: "reason", typ: parquetByteArray, repetition: parquetOptional, logicalType: parquetLogicalString},
+	{name: "cause", typ: parquetByteArray, repetition: parquetOptional, logicalType: parquetLogicalString},
+	{name: "add", typ: parquetInt64, repetition: parquetOptional},
+	{name: "change", typ: parquetInt64, repetition: parquetOptional},
+	{name: "destroy", typ: parquetInt64, repetition: parquetOptional},
+	{name: "name", typ: parquetByteArray, repetition: parquetRequired, logicalType: parquetLogicalString},
+	{name: "result", typ: parquetByteArray, repetition: parquetRequired, logicalType: parquetLogicalString},
+	{name: "ref", typ: parquetByteArray, repetition: parquetRequired, logicalType: parquetLogicalString},
+	{name: "cmd", typ: parquetByteArray, repetition: parquetRequired, logicalType: parquetLogicalString},
+	{name: "args", group: true, repetition: parquetRequired, numChildren: 1, logicalType: parquetLogicalList},
+	{name: "list", group: true, repetition: parquetRepeated, numChildren: 1},
+	{name: "element", typ: parquetByteArray, repetition: parquetRequired, logicalType: parquetLogicalString},
+	{name: "duration_ms", typ: parquetInt64, repetition: parquetRequired},
+}
+
+// WriteParquet writes the report to a writer in the Parquet format, with a row per run, e.g. for ingesting
+// the history of runs into a data lake.
+//
+// The version of the schema is recorded in the file metadata under ParquetSchemaVersionKey. The planned
+// resource change counts of runs without a plan are null, as are the reasons and causes of runs without one.
+func (r *Report) WriteParquet(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rows := make([]ParquetRun, 0, len(r.Runs))
+
+	for _, run := range r.Runs {
+		rows = append(rows, r.parquetRun(run))
+	}
+
+	var (
+		started    = &parquetColumn{path: []string{"started"}, typ: parquetInt64}
+		ended      = &parquetColumn{path: []string{"ended"}, typ: parquetInt64}
+		reason     = &parquetColumn{path: []string{"reason"}, typ: parquetByteArray, maxDef: 1}
+		cause      = &parquetColumn{path: []string{"cause"}, typ: parquetByteArray, maxDef: 1}
+		add        = &parquetColumn{path: []string{"add"}, typ: parquetInt64, maxDef: 1}
+		change     = &parquetColumn{path: []string{"change"}, typ: parquetInt64, maxDef: 1}
+		destroy    = &parquetColumn{path: []string{"destroy"}, typ: parquetInt64, maxDef: 1}
+		name       = &parquetColumn{path: []string{"name"}, typ: parquetByteArray}
+		result     = &parquetColumn{path: []string{"result"}, typ: parquetByteArray}
+		ref        = &parquetColumn{path: []string{"ref"}, typ: parquetByteArray}
+		cmd        = &parquetColumn{path: []string{"cmd"}, typ: parquetByteArray}
+		args       = &parquetColumn{path: []string{"args", "list", "element"}, typ: parquetByteArray, maxDef: 1, maxRep: 1}
+		durationMS = &parquetColumn{path: []string{"duration_ms"}, typ: parquetInt64}
+	)
+
+	for _, row := range rows {
+		startedMS, endedMS := row.Started.UnixMilli(), row.Ended.UnixMilli()
+
+		started.appendInt64(&startedMS)
+		ended.appendInt64(&endedMS)
+		reason.appendString(row.Reason)
+		cause.appendString(row.Cause)
+		add.appendInt64(row.Add)
+		change.appendInt64(row.Change)
+		destroy.appendInt64(row.Destroy)
+		name.appendString(&row.Name)
+		result.appendString(&row.Result)
+		ref.appendString(&row.Ref)
+		cmd.appendString(&row.Cmd)
+		args.appendStringList(row.Args)
+		durationMS.appendInt64(&row.DurationMS)
+	}
+
+	return writeParquetFile(
+		w,
+		parquetSchema,
+		[]*parquetColumn{started, ended, reason, cause, add, change, destroy, name, result, ref, cmd, args, durationMS},
+		len(rows),
+		map[string]string{ParquetSchemaVersionKey: strconv.Itoa(ParquetSchemaVersion)},
+	)
+}
+
+// parquetRun returns the row of the given run in a Parquet report.
+func (r *Report) parquetRun(run *Run) ParquetRun {
+	run.mu.RLock()
+	defer run.mu.RUnlock()
+
+	row := ParquetRun{
+		Name:       r.nameOfRun(run),
+		Started:    run.Started,
+		Ended:      run.Ended,
+		DurationMS: run.Ended.Sub(run.Started).Milliseconds(),
+		Result:     string(run.Result),
+		Ref:        run.Ref,
+		Cmd:        run.Cmd,
+		Args:       run.Args,
+	}
+
+	if run.Reason != nil {
+		reason := string(*run.Reason)
+		row.Reason = &reason
+	}
+
+	if run.Cause != nil {
+		cause := string(*run.Cause)
+		row.Cause = &cause
+	}
+
+	if run.Changes != nil {
+		add, change, destroy := int64(run.Changes.Add), int64(run.Changes.Change), int64(run.Changes.Destroy)
+		row.Add, row.Change, row.Destroy = &add, &change, &destroy
+	}
+
+	return row
+}
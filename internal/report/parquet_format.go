@@ -0,0 +1,412 @@
+package report
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"maps"
+	"math/bits"
+	"slices"
+)
+
+// The Parquet reports are written with the subset of the Parquet format they need, to avoid depending on a
+// full Parquet library: a single row group holding a single uncompressed, PLAIN encoded data page per column,
+// described by a footer encoded with the Thrift compact protocol.
+// See https://github.com/apache/parquet-format for the format and its Thrift definitions.
+
+const parquetMagic = "PAR1"
+
+// Physical types of the Parquet format.
+const (
+	parquetInt64     int32 = 2
+	parquetByteArray int32 = 6
+)
+
+// Repetition types of the Parquet format.
+const (
+	parquetRequired int32 = 0
+	parquetOptional int32 = 1
+	parquetRepeated int32 = 2
+)
+
+// Converted types of the Parquet format, still set next to the logical types for older readers.
+const (
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedList            int32 = 3
+	parquetConvertedTimestampMillis int32 = 9
+)
+
+// Encodings of the Parquet format.
+const (
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+)
+
+// Field types of the Thrift compact protocol.
+const (
+	thriftBoolTrue  byte = 1
+	thriftBoolFalse byte = 2
+	thriftI32       byte = 5
+	thriftI64       byte = 6
+	thriftBinary    byte = 8
+	thriftList      byte = 9
+	thriftStruct    byte = 12
+)
+
+// parquetLogicalType is the logical type annotation of a column of the Parquet schema.
+type parquetLogicalType int
+
+const (
+	parquetLogicalNone parquetLogicalType = iota
+	parquetLogicalString
+	parquetLogicalList
+	parquetLogicalTimestampMillis
+)
+
+// parquetSchemaNode is an element of the Parquet schema, flattened depth first as in the file footer.
+type parquetSchemaNode struct {
+	name        string
+	typ         int32
+	repetition  int32
+	numChildren int
+	logicalType parquetLogicalType
+	// group is set for the elements without a physical type, and root for the root of the schema.
+	group bool
+	root  bool
+}
+
+// parquetColumn holds the levels and the PLAIN encoded values of a leaf column of a Parquet file.
+type parquetColumn struct {
+	values    bytes.Buffer
+	path      []string
+	defLevels []int
+	repLevels []int
+	typ       int32
+	maxDef    int
+	maxRep    int
+}
+
+// appendInt64 appends a value, or a null when value is nil, to a column holding INT64 values.
+func (c *parquetColumn) appendInt64(value *int64) {
+	if value == nil {
+		c.appendLevels(0, 0)
+		return
+	}
+
+	c.appendLevels(c.maxDef, 0)
+	_ = binary.Write(&c.values, binary.LittleEndian, *value)
+}
+
+// appendString appends a value, or a null when value is nil, to a column holding BYTE_ARRAY values.
+func (c *parquetColumn) appendString(value *string) {
+	if value == nil {
+		c.appendLevels(0, 0)
+		return
+	}
+
+	c.appendLevels(c.maxDef, 0)
+	c.writeString(*value)
+}
+
+// appendStringList appends a list of values to a column holding the elements of a required list of strings.
+func (c *parquetColumn) appendStringList(values []string) {
+	if len(values) == 0 {
+		c.appendLevels(0, 0)
+		return
+	}
+
+	for i, value := range values {
+		rep := 1
+		if i == 0 {
+			rep = 0
+		}
+
+		c.appendLevels(c.maxDef, rep)
+		c.writeString(value)
+	}
+}
+
+func (c *parquetColumn) appendLevels(def, rep int) {
+	c.defLevels = append(c.defLevels, def)
+	c.repLevels = append(c.repLevels, rep)
+}
+
+func (c *parquetColumn) writeString(value string) {
+	_ = binary.Write(&c.values, binary.LittleEndian, uint32(len(value)))
+	c.values.WriteString(value)
+}
+
+// page returns the body of the data page of the column: its repetition and definition levels, when they
+// can be other than zero, followed by its values.
+func (c *parquetColumn) page() []byte {
+	var page bytes.Buffer
+
+	if c.maxRep > 0 {
+		writeParquetLevels(&page, c.repLevels, c.maxRep)
+	}
+
+	if c.maxDef > 0 {
+		writeParquetLevels(&page, c.defLevels, c.maxDef)
+	}
+
+	page.Write(c.values.Bytes())
+
+	return page.Bytes()
+}
+
+// writeParquetLevels writes the given levels with the RLE encoding, as runs of equal levels, prefixed by their
+// length as data pages of version 1 require.
+func writeParquetLevels(w *bytes.Buffer, levels []int, maxLevel int) {
+	var runs bytes.Buffer
+
+	width := (bits.Len(uint(maxLevel)) + 7) / 8 //nolint:mnd
+
+	for start := 0; start < len(levels); {
+		end := start + 1
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+
+		runs.Write(binary.AppendUvarint(nil, uint64(end-start)<<1))
+
+		for i := range width {
+			runs.WriteByte(byte(levels[start] >> (8 * i))) //nolint:mnd
+		}
+
+		start = end
+	}
+
+	_ = binary.Write(w, binary.LittleEndian, uint32(runs.Len()))
+	w.Write(runs.Bytes())
+}
+
+// writeParquetFile writes a Parquet file holding the given number of rows of the given columns, described by
+// the given schema, whose first element is the root of the schema, and key-value metadata.
+func writeParquetFile(w io.Writer, schema []parquetSchemaNode, columns []*parquetColumn, numRows int, metadata map[string]string) error {
+	var (
+		file      bytes.Buffer
+		totalSize int64
+	)
+
+	file.WriteString(parquetMagic)
+
+	chunks := make([]func(t *thriftWriter), 0, len(columns))
+
+	for _, column := range columns {
+		offset := int64(file.Len())
+		page := column.page()
+
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5, func(t *thriftWriter) {
+			t.i32(1, int32(len(column.defLevels)))
+			t.i32(2, parquetEncodingPlain)
+			t.i32(3, parquetEncodingRLE)
+			t.i32(4, parquetEncodingRLE)
+		})
+		header.endStruct()
+
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+
+		size := int64(header.buf.Len() + len(page))
+		totalSize += size
+
+		chunks = append(chunks, func(t *thriftWriter) {
+			t.i64(2, offset)
+			t.structField(3, func(t *thriftWriter) {
+				t.i32(1, column.typ)
+				t.i32List(2, []int32{parquetEncodingPlain, parquetEncodingRLE})
+				t.stringList(3, column.path)
+				t.i32(4, 0) // UNCOMPRESSED
+				t.i64(5, int64(len(column.defLevels)))
+				t.i64(6, size)
+				t.i64(7, size)
+				t.i64(9, offset)
+			})
+		})
+	}
+
+	metadataKeys := slices.Sorted(maps.Keys(metadata))
+
+	footer := &thriftWriter{}
+	footer.beginStruct()
+	footer.i32(1, 1)
+	footer.structList(2, len(schema), func(t *thriftWriter, i int) {
+		schema[i].write(t)
+	})
+	footer.i64(3, int64(numRows))
+	footer.structList(4, 1, func(t *thriftWriter, _ int) {
+		t.structList(1, len(chunks), func(t *thriftWriter, i int) {
+			chunks[i](t)
+		})
+		t.i64(2, totalSize)
+		t.i64(3, int64(numRows))
+	})
+	footer.structList(5, len(metadataKeys), func(t *thriftWriter, i int) {
+		t.binary(1, metadataKeys[i])
+		t.binary(2, metadata[metadataKeys[i]])
+	})
+	footer.binary(6, "terragrunt")
+	footer.endStruct()
+
+	file.Write(footer.buf.Bytes())
+	_ = binary.Write(&file, binary.LittleEndian, uint32(footer.buf.Len()))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+
+	return err
+}
+
+// write writes the SchemaElement of the node.
+func (node parquetSchemaNode) write(t *thriftWriter) {
+	if !node.group {
+		t.i32(1, node.typ)
+	}
+
+	if !node.root {
+		t.i32(3, node.repetition)
+	}
+
+	t.binary(4, node.name)
+
+	if node.group {
+		t.i32(5, int32(node.numChildren))
+	}
+
+	switch node.logicalType {
+	case parquetLogicalString:
+		t.i32(6, parquetConvertedUTF8)
+		t.structField(10, func(t *thriftWriter) {
+			t.structField(1, func(*thriftWriter) {})
+		})
+	case parquetLogicalList:
+		t.i32(6, parquetConvertedList)
+		t.structField(10, func(t *thriftWriter) {
+			t.structField(3, func(*thriftWriter) {})
+		})
+	case parquetLogicalTimestampMillis:
+		t.i32(6, parquetConvertedTimestampMillis)
+		t.structField(10, func(t *thriftWriter) {
+			t.structField(8, func(t *thriftWriter) {
+				t.boolean(1, true)
+				t.structField(2, func(t *thriftWriter) {
+					t.structField(1, func(*thriftWriter) {})
+				})
+			})
+		})
+	case parquetLogicalNone:
+	}
+}
+
+// thriftWriter encodes structs with the Thrift compact protocol.
+type thriftWriter struct {
+	buf bytes.Buffer
+	// lastIDs holds the id of the last field written in each struct being written, innermost last.
+	lastIDs []int16
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.lastIDs = append(t.lastIDs, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.lastIDs[len(t.lastIDs)-1]
+
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ) //nolint:mnd
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+
+	*last = id
+}
+
+// varint writes a zigzag encoded integer.
+func (t *thriftWriter) varint(v int64) {
+	t.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *thriftWriter) listHeader(size int, elemType byte) {
+	if size < 15 { //nolint:mnd
+		t.buf.WriteByte(byte(size)<<4 | elemType) //nolint:mnd
+		return
+	}
+
+	t.buf.WriteByte(0xf0 | elemType) //nolint:mnd
+	t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+func (t *thriftWriter) writeBinary(v string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) boolean(id int16, v bool) {
+	if v {
+		t.fieldHeader(id, thriftBoolTrue)
+	} else {
+		t.fieldHeader(id, thriftBoolFalse)
+	}
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.fieldHeader(id, thriftBinary)
+	t.writeBinary(v)
+}
+
+func (t *thriftWriter) structField(id int16, fields func(t *thriftWriter)) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+	fields(t)
+	t.endStruct()
+}
+
+func (t *thriftWriter) i32List(id int16, values []int32) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(len(values), thriftI32)
+
+	for _, v := range values {
+		t.varint(int64(v))
+	}
+}
+
+func (t *thriftWriter) stringList(id int16, values []string) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(len(values), thriftBinary)
+
+	for _, v := range values {
+		t.writeBinary(v)
+	}
+}
+
+func (t *thriftWriter) structList(id int16, size int, elem func(t *thriftWriter, i int)) {
+	t.fieldHeader(id, thriftList)
+	t.listHeader(size, thriftStruct)
+
+	for i := range size {
+		t.beginStruct()
+		elem(t, i)
+		t.endStruct()
+	}
+}
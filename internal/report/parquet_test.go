@@ -0,0 +1,319 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestWriteParquet(t *testing.T) {
+	t.Parallel()
+
+	l := logger.CreateLogger()
+	dir := helpers.TmpDirWOSymlinks(t)
+	r := report.NewReport().WithWorkingDir(dir)
+
+	plannedRun := newRun(t, filepath.Join(dir, "planned-run"))
+	plannedRun.Started = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	plannedRun.Cmd = "plan"
+	plannedRun.Args = []string{"-input=false", "-lock=false"}
+	require.NoError(t, r.AddRun(l, plannedRun))
+	require.NoError(t, r.EndRun(l, plannedRun.Path,
+		report.WithChangeCounts(report.ChangeCounts{Add: 3, Change: 1, Destroy: 2}),
+	))
+
+	failedRun := newRun(t, filepath.Join(dir, "failed-run"))
+	require.NoError(t, r.AddRun(l, failedRun))
+	require.NoError(t, r.EndRun(l, failedRun.Path,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(report.ReasonRunError),
+		report.WithCauseRetryBlock("network"),
+	))
+
+	// Pin the durations, which are otherwise bound to the wall clock.
+	plannedRun.Ended = plannedRun.Started.Add(1500 * time.Millisecond)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteParquet(&buf))
+
+	metadata, rows := readParquet(t, buf.Bytes())
+
+	assert.Equal(t, "1", metadata[report.ParquetSchemaVersionKey])
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, "planned-run", rows[0]["name"])
+	assert.Equal(t, string(report.ResultSucceeded), rows[0]["result"])
+	assert.Equal(t, int64(1500), rows[0]["duration_ms"])
+	assert.Equal(t, plannedRun.Started.UnixMilli(), rows[0]["started"])
+	assert.Equal(t, int64(3), rows[0]["add"])
+	assert.Nil(t, rows[0]["reason"])
+	assert.Equal(t, "plan", rows[0]["cmd"])
+	assert.Equal(t, []string{"-input=false", "-lock=false"}, rows[0]["args"])
+
+	assert.Equal(t, "failed-run", rows[1]["name"])
+	assert.Equal(t, string(report.ResultFailed), rows[1]["result"])
+	assert.Equal(t, string(report.ReasonRunError), rows[1]["reason"])
+	assert.Equal(t, "network", rows[1]["cause"])
+	assert.Nil(t, rows[1]["add"])
+	assert.Empty(t, rows[1]["args"])
+}
+
+func TestWriteParquetEmptyReport(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, report.NewReport().WriteParquet(&buf))
+
+	metadata, rows := readParquet(t, buf.Bytes())
+
+	assert.Equal(t, "1", metadata[report.ParquetSchemaVersionKey])
+	assert.Empty(t, rows)
+}
+
+// readParquet reads the key-value metadata and the rows of a Parquet report, keyed by column, decoding the
+// subset of the format WriteParquet writes. The elements of the list columns are collected into []string.
+func readParquet(t *testing.T, data []byte) (map[string]string, []map[string]any) {
+	t.Helper()
+
+	require.Equal(t, "PAR1", string(data[:4]))
+	require.Equal(t, "PAR1", string(data[len(data)-4:]))
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := (&thriftReader{data: data[len(data)-8-footerLen : len(data)-8]}).readStruct()
+
+	metadata := map[string]string{}
+	for _, kv := range footer[5].([]any) {
+		kv := kv.(map[int16]any)
+		metadata[kv[1].(string)] = kv[2].(string)
+	}
+
+	levels := parquetLeafLevels(footer[2].([]any))
+	numRows := int(footer[3].(int64))
+
+	rows := make([]map[string]any, numRows)
+	for i := range rows {
+		rows[i] = map[string]any{}
+	}
+
+	rowGroup := footer[4].([]any)[0].(map[int16]any)
+
+	for _, chunk := range rowGroup[1].([]any) {
+		meta := chunk.(map[int16]any)[3].(map[int16]any)
+
+		var path []string
+		for _, part := range meta[3].([]any) {
+			path = append(path, part.(string))
+		}
+
+		leaf := levels[path[len(path)-1]]
+
+		page := &thriftReader{data: data, pos: int(meta[9].(int64))}
+		header := page.readStruct()
+		numValues := int(header[5].(map[int16]any)[1].(int32))
+
+		var reps, defs []int
+		if leaf.maxRep > 0 {
+			reps = page.readLevels(numValues)
+		}
+
+		if leaf.maxDef > 0 {
+			defs = page.readLevels(numValues)
+		}
+
+		row := -1
+
+		for i := range numValues {
+			if reps == nil || reps[i] == 0 {
+				row++
+			}
+
+			if defs != nil && defs[i] < leaf.maxDef {
+				if leaf.maxRep > 0 {
+					rows[row][path[0]] = []string(nil)
+				} else {
+					rows[row][path[0]] = nil
+				}
+
+				continue
+			}
+
+			var value any
+
+			switch meta[1].(int32) {
+			case 2: // INT64
+				value = int64(binary.LittleEndian.Uint64(page.data[page.pos:]))
+				page.pos += 8
+			case 6: // BYTE_ARRAY
+				size := int(binary.LittleEndian.Uint32(page.data[page.pos:]))
+				value = string(page.data[page.pos+4 : page.pos+4+size])
+				page.pos += 4 + size
+			}
+
+			if leaf.maxRep > 0 {
+				elems, _ := rows[row][path[0]].([]string)
+				rows[row][path[0]] = append(elems, value.(string))
+			} else {
+				rows[row][path[0]] = value
+			}
+		}
+	}
+
+	return metadata, rows
+}
+
+type parquetLevels struct {
+	maxDef int
+	maxRep int
+}
+
+// parquetLeafLevels returns the maximum definition and repetition levels of the leaf columns of the given
+// flattened schema, keyed by name.
+func parquetLeafLevels(schema []any) map[string]parquetLevels {
+	leaves := map[string]parquetLevels{}
+
+	var walk func(i int, levels parquetLevels) int
+
+	walk = func(i int, levels parquetLevels) int {
+		elem := schema[i].(map[int16]any)
+
+		switch elem[3] {
+		case int32(1):
+			levels.maxDef++
+		case int32(2):
+			levels.maxDef++
+			levels.maxRep++
+		}
+
+		children, ok := elem[5].(int32)
+		if !ok {
+			leaves[elem[4].(string)] = levels
+			return i + 1
+		}
+
+		next := i + 1
+		for range children {
+			next = walk(next, levels)
+		}
+
+		return next
+	}
+
+	walk(0, parquetLevels{})
+
+	return leaves
+}
+
+// thriftReader decodes values encoded with the Thrift compact protocol: structs into maps keyed by field id,
+// lists into slices, integers into int32 or int64, and binaries into strings.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.data[r.pos:])
+	r.pos += n
+
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+
+	var last int16
+
+	for {
+		b := r.data[r.pos]
+		r.pos++
+
+		if b == 0 {
+			return fields
+		}
+
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(r.varint())
+		}
+
+		last = id
+
+		switch typ := b & 0x0f; typ {
+		case 1:
+			fields[id] = true
+		case 2:
+			fields[id] = false
+		default:
+			fields[id] = r.readValue(typ)
+		}
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case 5:
+		return int32(r.varint())
+	case 6:
+		return r.varint()
+	case 8:
+		size := int(r.uvarint())
+		r.pos += size
+
+		return string(r.data[r.pos-size : r.pos])
+	case 9:
+		b := r.data[r.pos]
+		r.pos++
+
+		size := int(b >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+
+		values := make([]any, 0, size)
+		for range size {
+			values = append(values, r.readValue(b&0x0f))
+		}
+
+		return values
+	case 12:
+		return r.readStruct()
+	}
+
+	panic("unsupported thrift type")
+}
+
+// readLevels reads the given number of levels encoded as RLE runs of a single byte wide level, prefixed by
+// their length.
+func (r *thriftReader) readLevels(n int) []int {
+	end := r.pos + 4 + int(binary.LittleEndian.Uint32(r.data[r.pos:]))
+	r.pos += 4
+
+	levels := make([]int, 0, n)
+
+	for r.pos < end {
+		count := int(r.uvarint() >> 1)
+		level := int(r.data[r.pos])
+		r.pos++
+
+		for range count {
+			levels = append(levels, level)
+		}
+	}
+
+	return levels
+}
@@ -15,6 +15,7 @@ import (
 // Report captures data for a report/summary.
 type Report struct {
 	workingDir           string
+	pathDisplay          func(path string) string
 	format               Format
 	Runs                 []*Run
 	mu                   sync.RWMutex
@@ -28,13 +29,16 @@ type Run struct {
 	Ended               time.Time
 	Reason              *Reason
 	Cause               *Cause
+	Changes             *ChangeCounts
 	Path                string
 	Result              Result
 	DiscoveryWorkingDir string
 	Ref                 string
 	Cmd                 string
-	Args                []string
-	mu                  sync.RWMutex
+	// TerraformVersion is the implementation and version of OpenTofu/Terraform that ran the unit, e.g. "tofu 1.9.0".
+	TerraformVersion string
+	Args             []string
+	mu               sync.RWMutex
 }
 
 // Result captures the result of a run.
@@ -50,8 +54,12 @@ type Cause string
 type Format string
 
 const (
-	FormatCSV  Format = "csv"
-	FormatJSON Format = "json"
+	FormatCSV     Format = "csv"
+	FormatJSON    Format = "json"
+	FormatJUnit   Format = "junit"
+	FormatSARIF   Format = "sarif"
+	FormatChanges Format = "changes"
+	FormatParquet Format = "parquet"
 )
 
 const (
@@ -62,11 +70,20 @@ const (
 )
 
 const (
-	ReasonRetrySucceeded Reason = "retry succeeded"
-	ReasonErrorIgnored   Reason = "error ignored"
-	ReasonRunError       Reason = "run error"
-	ReasonExcludeBlock   Reason = "exclude block"
-	ReasonAncestorError  Reason = "ancestor error"
+	ReasonRetrySucceeded    Reason = "retry succeeded"
+	ReasonErrorIgnored      Reason = "error ignored"
+	ReasonRunError          Reason = "run error"
+	ReasonExcludeBlock      Reason = "exclude block"
+	ReasonAncestorError     Reason = "ancestor error"
+	ReasonCacheHit          Reason = "cache hit"
+	ReasonUserSkipped       Reason = "user skipped"
+	ReasonAssumedApplied    Reason = "assumed applied"
+	ReasonCancelled         Reason = "cancelled"
+	ReasonSkipPredicate     Reason = "skip predicate"
+	ReasonQuarantined       Reason = "quarantined"
+	ReasonDependencyTimeout Reason = "dependency timeout"
+	ReasonInsufficientDisk  Reason = "insufficient disk"
+	ReasonDeadlineExceeded  Reason = "deadline exceeded"
 )
 
 // NewReport creates a new report.
@@ -96,6 +113,16 @@ func (r *Report) WithWorkingDir(workingDir string) *Report {
 	return r
 }
 
+// WithPathDisplay sets the function transforming the path of each run into the name it is displayed with,
+// e.g. relative to the root of the stack. Runs are still identified by their canonical paths.
+//
+// By default, runs are displayed relative to the working directory.
+func (r *Report) WithPathDisplay(display func(path string) string) *Report {
+	r.pathDisplay = display
+
+	return r
+}
+
 // WithFormat sets the format for the report.
 func (r *Report) WithFormat(format Format) *Report {
 	r.format = format
@@ -143,7 +170,7 @@ func (r *Report) AddRun(l log.Logger, run *Run) error {
 		}
 	}
 
-	l.Debugf("Adding report run %s", run.Path)
+	l.Debugf("Adding report run %s", r.displayPath(run.Path))
 
 	r.Runs = append(r.Runs, run)
 
@@ -178,7 +205,7 @@ func (r *Report) GetRun(path string) (*Run, error) {
 func (r *Report) EnsureRun(l log.Logger, path string, opts ...EndOption) (*Run, error) {
 	run, err := r.GetRun(path)
 	if err == nil {
-		l.Debugf("Report run %s already exists, returning existing run", path)
+		l.Debugf("Report run %s already exists, returning existing run", r.displayPath(path))
 
 		run.mu.Lock()
 		defer run.mu.Unlock()
@@ -194,7 +221,7 @@ func (r *Report) EnsureRun(l log.Logger, path string, opts ...EndOption) (*Run,
 		return run, err
 	}
 
-	l.Debugf("Report run %s not found, creating new run", path)
+	l.Debugf("Report run %s not found, creating new run", r.displayPath(path))
 
 	run, err = NewRun(path)
 	if err != nil {
@@ -252,7 +279,7 @@ func (r *Report) EndRun(l log.Logger, path string, endOptions ...EndOption) erro
 		endOption(run)
 	}
 
-	l.Debugf("Ending report run %s with result %s", path, run.Result)
+	l.Debugf("Ending report run %s with result %s", r.displayPath(path), run.Result)
 
 	return nil
 }
@@ -320,6 +347,14 @@ func WithCauseRunError(name string) EndOption {
 	return withCause(name)
 }
 
+// WithCauseSkipPredicate sets the cause of a run to the reason returned by the skip predicate that excluded it.
+//
+// This function is a wrapper around withCause, just to make sure that authors always use consistent
+// reasons for causes.
+func WithCauseSkipPredicate(reason string) EndOption {
+	return withCause(reason)
+}
+
 // WithDiscoveryWorkingDir sets the discovery working directory for a run.
 // This is used to compute relative paths for units discovered in worktrees.
 func WithDiscoveryWorkingDir(workingDir string) EndOption {
@@ -351,6 +386,13 @@ func WithArgs(args []string) EndOption {
 	}
 }
 
+// WithTerraformVersion sets the implementation and version of OpenTofu/Terraform that ran a run.
+func WithTerraformVersion(terraformVersion string) EndOption {
+	return func(run *Run) {
+		run.TerraformVersion = terraformVersion
+	}
+}
+
 // withCause sets the cause of a run to the name of a particular cause.
 func withCause(name string) EndOption {
 	return func(run *Run) {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -451,6 +452,100 @@ func TestSummarize(t *testing.T) {
 	}
 }
 
+func TestChangeSummary(t *testing.T) {
+	t.Parallel()
+
+	tmp := helpers.TmpDirWOSymlinks(t)
+
+	l := logger.CreateLogger()
+
+	r := report.NewReport()
+
+	changed := newRun(t, filepath.Join(tmp, "changed"))
+	require.NoError(t, r.AddRun(l, changed))
+
+	unchanged := newRun(t, filepath.Join(tmp, "unchanged"))
+	require.NoError(t, r.AddRun(l, unchanged))
+
+	notPlanned := newRun(t, filepath.Join(tmp, "not-planned"))
+	require.NoError(t, r.AddRun(l, notPlanned))
+
+	_, err := r.EnsureRun(l, changed.Path, report.WithChangeCounts(report.ChangeCounts{Add: 3, Change: 1}))
+	require.NoError(t, err)
+
+	_, err = r.EnsureRun(l, unchanged.Path, report.WithChangeCounts(report.ChangeCounts{}))
+	require.NoError(t, err)
+
+	summary := r.ChangeSummary()
+	require.Len(t, summary, 2)
+	assert.Equal(t, "+3 ~1 -0", summary[changed.Path].String())
+	assert.Equal(t, "no changes", summary[unchanged.Path].String())
+}
+
+func TestOnlyChanged(t *testing.T) {
+	t.Parallel()
+
+	tmp := helpers.TmpDirWOSymlinks(t)
+
+	l := logger.CreateLogger()
+
+	r := report.NewReport().WithWorkingDir(tmp)
+
+	for name, counts := range map[string]*report.ChangeCounts{
+		"changed":     {Destroy: 1},
+		"unchanged":   {},
+		"not-planned": nil,
+	} {
+		run := newRun(t, filepath.Join(tmp, name))
+		require.NoError(t, r.AddRun(l, run))
+
+		if counts != nil {
+			_, err := r.EnsureRun(l, run.Path, report.WithChangeCounts(*counts))
+			require.NoError(t, err)
+		}
+	}
+
+	changed := r.OnlyChanged()
+	require.Len(t, changed.Runs, 1)
+	assert.Equal(t, filepath.Join(tmp, "changed"), changed.Runs[0].Path)
+
+	// The original report keeps all of its runs.
+	assert.Len(t, r.Runs, 3)
+}
+
+func TestCheckChangeLimit(t *testing.T) {
+	t.Parallel()
+
+	tmp := helpers.TmpDirWOSymlinks(t)
+
+	l := logger.CreateLogger()
+
+	r := report.NewReport()
+
+	for name, counts := range map[string]report.ChangeCounts{
+		"small":  {Add: 1},
+		"medium": {Add: 2, Change: 3},
+		"large":  {Destroy: 10},
+	} {
+		run := newRun(t, filepath.Join(tmp, name))
+		require.NoError(t, r.AddRun(l, run))
+
+		_, err := r.EnsureRun(l, run.Path, report.WithChangeCounts(counts))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, r.CheckChangeLimit(16, 2))
+
+	err := r.CheckChangeLimit(15, 2)
+
+	var limitErr report.ChangeLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, 16, limitErr.Total)
+	require.Len(t, limitErr.Top, 2)
+	assert.Equal(t, filepath.Join(tmp, "large"), limitErr.Top[0].Path)
+	assert.Equal(t, filepath.Join(tmp, "medium"), limitErr.Top[1].Path)
+}
+
 func TestWriteCSV(t *testing.T) {
 	t.Parallel()
 
@@ -783,7 +878,16 @@ const ExpectedSchema = `{
           "error ignored",
           "run error",
           "exclude block",
-          "ancestor error"
+          "ancestor error",
+          "cache hit",
+          "user skipped",
+          "assumed applied",
+          "cancelled",
+          "skip predicate",
+          "quarantined",
+          "dependency timeout",
+          "insufficient disk",
+          "deadline exceeded"
         ]
       },
       "Cause": {
@@ -812,6 +916,9 @@ const ExpectedSchema = `{
           "type": "string"
         },
         "type": "array"
+      },
+      "TerraformVersion": {
+        "type": "string"
       }
     },
     "additionalProperties": false,
@@ -1557,6 +1664,38 @@ func TestWriteCSVWithDiscoveryWorkingDir(t *testing.T) {
 	assert.Equal(t, "module/unit", records[1][0], "Run name should be relative to DiscoveryWorkingDir, not report.workingDir")
 }
 
+// TestWriteJSONWithPathDisplay verifies that runs are named with the path display function when set.
+func TestWriteJSONWithPathDisplay(t *testing.T) {
+	t.Parallel()
+
+	l := logger.CreateLogger()
+
+	workingDir := helpers.TmpDirWOSymlinks(t)
+	unitPath := filepath.Join(workingDir, "live", "prod", "app")
+
+	r := report.NewReport().WithWorkingDir(workingDir).WithPathDisplay(func(path string) string {
+		return "prod/" + filepath.Base(path)
+	})
+
+	r.AddRun(l, newRun(t, unitPath))
+	r.EndRun(l, unitPath, report.WithResult(report.ResultSucceeded))
+
+	// The run is still identified by its canonical path.
+	run, err := r.GetRun(unitPath)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultSucceeded, run.Result)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.WriteJSON(&buf))
+
+	var runs []report.JSONRun
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &runs))
+	require.Len(t, runs, 1)
+	assert.Equal(t, "prod/app", runs[0].Name)
+}
+
 // TestParseJSONRuns verifies that JSON report data can be parsed from bytes.
 func TestParseJSONRuns(t *testing.T) {
 	t.Parallel()
@@ -2062,3 +2201,193 @@ func newRun(t *testing.T, name string) *report.Run {
 
 	return run
 }
+
+func TestWriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	l := logger.CreateLogger()
+	dir := helpers.TmpDirWOSymlinks(t)
+	r := report.NewReport().WithWorkingDir(dir)
+
+	successRun := newRun(t, filepath.Join(dir, "success-run"))
+	require.NoError(t, r.AddRun(l, successRun))
+	require.NoError(t, r.EndRun(l, successRun.Path))
+
+	failedRun := newRun(t, filepath.Join(dir, "failed-run"))
+	require.NoError(t, r.AddRun(l, failedRun))
+	require.NoError(t, r.EndRun(l, failedRun.Path,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(report.ReasonRunError),
+		report.WithCauseRunError("exit status 1"),
+	))
+
+	earlyExitRun := newRun(t, filepath.Join(dir, "early-exit-run"))
+	require.NoError(t, r.AddRun(l, earlyExitRun))
+	require.NoError(t, r.EndRun(l, earlyExitRun.Path,
+		report.WithResult(report.ResultEarlyExit),
+		report.WithReason(report.ReasonAncestorError),
+		report.WithCauseAncestorExit("failed-run"),
+	))
+
+	excludedRun := newRun(t, filepath.Join(dir, "excluded-run"))
+	require.NoError(t, r.AddRun(l, excludedRun))
+	require.NoError(t, r.EndRun(l, excludedRun.Path,
+		report.WithResult(report.ResultExcluded),
+		report.WithReason(report.ReasonAssumedApplied),
+	))
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJUnit(&buf))
+
+	var suites struct {
+		Suites []struct {
+			Name      string `xml:"name,attr"`
+			TestCases []struct {
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Text    string `xml:",chardata"`
+				} `xml:"failure"`
+				Skipped *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"skipped"`
+				Name string `xml:"name,attr"`
+			} `xml:"testcase"`
+			Tests    int `xml:"tests,attr"`
+			Failures int `xml:"failures,attr"`
+			Skipped  int `xml:"skipped,attr"`
+		} `xml:"testsuite"`
+	}
+
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	assert.Equal(t, 4, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	require.Len(t, suite.TestCases, 4)
+
+	assert.Equal(t, "success-run", suite.TestCases[0].Name)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	assert.Nil(t, suite.TestCases[0].Skipped)
+
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Equal(t, "run error", suite.TestCases[1].Failure.Message)
+	assert.Equal(t, "exit status 1", suite.TestCases[1].Failure.Text)
+
+	require.NotNil(t, suite.TestCases[2].Failure)
+	assert.Equal(t, "ancestor error", suite.TestCases[2].Failure.Message)
+
+	require.NotNil(t, suite.TestCases[3].Skipped)
+	assert.Equal(t, "assumed applied", suite.TestCases[3].Skipped.Message)
+}
+
+func TestWriteFindings(t *testing.T) {
+	t.Parallel()
+
+	l := logger.CreateLogger()
+	dir := helpers.TmpDirWOSymlinks(t)
+	r := report.NewReport().WithWorkingDir(dir)
+
+	destroyRun := newRun(t, filepath.Join(dir, "destroy-run"))
+	require.NoError(t, r.AddRun(l, destroyRun))
+	require.NoError(t, r.EndRun(l, destroyRun.Path,
+		report.WithChangeCounts(report.ChangeCounts{Add: 1, Destroy: 2}),
+	))
+
+	addRun := newRun(t, filepath.Join(dir, "add-run"))
+	require.NoError(t, r.AddRun(l, addRun))
+	require.NoError(t, r.EndRun(l, addRun.Path, report.WithChangeCounts(report.ChangeCounts{Add: 3})))
+
+	failedRun := newRun(t, filepath.Join(dir, "failed-run"))
+	require.NoError(t, r.AddRun(l, failedRun))
+	require.NoError(t, r.EndRun(l, failedRun.Path,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(report.ReasonRunError),
+		report.WithCauseRunError("exit status 1"),
+	))
+
+	quarantinedRun := newRun(t, filepath.Join(dir, "quarantined-run"))
+	require.NoError(t, r.AddRun(l, quarantinedRun))
+	require.NoError(t, r.EndRun(l, quarantinedRun.Path,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(report.ReasonQuarantined),
+	))
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteFindings(&buf))
+
+	var findings struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &findings))
+	assert.Equal(t, "2.1.0", findings.Version)
+	require.Len(t, findings.Runs, 1)
+
+	results := findings.Runs[0].Results
+	require.Len(t, results, 3)
+
+	assert.Equal(t, string(report.FindingDestructiveChange), results[0].RuleID)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Equal(t, "destroy-run", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Contains(t, results[0].Message.Text, "destroys 2 resources")
+
+	assert.Equal(t, string(report.FindingRunFailed), results[1].RuleID)
+	assert.Equal(t, "error", results[1].Level)
+	assert.Equal(t, "The run of unit failed-run failed: exit status 1", results[1].Message.Text)
+
+	assert.Equal(t, string(report.FindingRunFailed), results[2].RuleID)
+	assert.Equal(t, "warning", results[2].Level)
+	assert.Equal(t, "quarantined-run", results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestWriteChangesCSV(t *testing.T) {
+	t.Parallel()
+
+	l := logger.CreateLogger()
+	dir := helpers.TmpDirWOSymlinks(t)
+	r := report.NewReport().WithWorkingDir(dir)
+
+	plannedRun := newRun(t, filepath.Join(dir, "planned-run"))
+	plannedRun.Started = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, r.AddRun(l, plannedRun))
+	require.NoError(t, r.EndRun(l, plannedRun.Path,
+		report.WithChangeCounts(report.ChangeCounts{Add: 3, Change: 1, Destroy: 2}),
+	))
+
+	failedRun := newRun(t, filepath.Join(dir, "failed-run"))
+	require.NoError(t, r.AddRun(l, failedRun))
+	require.NoError(t, r.EndRun(l, failedRun.Path, report.WithResult(report.ResultFailed)))
+
+	// Pin the durations, which are otherwise bound to the wall clock.
+	plannedRun.Ended = plannedRun.Started.Add(1500 * time.Millisecond)
+	failedRun.Ended = failedRun.Started
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteChangesCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Name", "Add", "Change", "Destroy", "Duration"},
+		{"planned-run", "3", "1", "2", "1.500"},
+		{"failed-run", "", "", "", "0.000"},
+	}, records)
+}
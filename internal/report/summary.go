@@ -17,6 +17,7 @@ type Summary struct {
 	lastRunEnd           *time.Time
 	padder               string
 	workingDir           string
+	pathDisplay          func(path string) string
 	runs                 []*Run
 	UnitsSucceeded       int
 	UnitsFailed          int
@@ -30,6 +31,7 @@ type Summary struct {
 func (r *Report) Summarize() *Summary {
 	summary := &Summary{
 		workingDir:           r.workingDir,
+		pathDisplay:          r.pathDisplay,
 		shouldColor:          r.shouldColor,
 		showUnitLevelSummary: r.showUnitLevelSummary,
 		padder:               ".",
@@ -223,10 +225,7 @@ func (s *Summary) writeUnitLevelSummary(w io.Writer, colorizer *Colorizer) error
 	maxUnitNameLength := 0
 
 	for _, run := range s.runs {
-		name := run.Path
-		if s.workingDir != "" {
-			name = strings.TrimPrefix(name, s.workingDir+string(os.PathSeparator))
-		}
+		name := s.nameOfRun(run)
 
 		if len(name) > maxUnitNameLength {
 			maxUnitNameLength = len(name)
@@ -339,10 +338,7 @@ func (s *Summary) writeUnitLevelSummary(w io.Writer, colorizer *Colorizer) error
 func (s *Summary) writeUnitDuration(w io.Writer, run *Run, colorizer *Colorizer, unitColorizer func(string) string) error {
 	duration := run.Ended.Sub(run.Started)
 
-	name := run.Path
-	if s.workingDir != "" {
-		name = strings.TrimPrefix(name, s.workingDir+string(os.PathSeparator))
-	}
+	name := s.nameOfRun(run)
 
 	padding := s.unitDurationPadding(name, colorizer)
 
@@ -403,10 +399,7 @@ func (s *Summary) unitDurationPadding(name string, colorizer *Colorizer) string
 	maxUnitNameLength := 0
 
 	for _, run := range s.runs {
-		runName := run.Path
-		if s.workingDir != "" {
-			runName = strings.TrimPrefix(runName, s.workingDir+string(os.PathSeparator))
-		}
+		runName := s.nameOfRun(run)
 
 		if len(runName) > maxUnitNameLength {
 			maxUnitNameLength = len(runName)
@@ -438,3 +431,17 @@ func (s *Summary) unitDurationPadding(name string, colorizer *Colorizer) string
 
 	return colorizer.paddingColorizer(padding)
 }
+
+// nameOfRun returns the name a run is displayed with in the summary, its path transformed by the path display
+// function when set, or relative to the working directory otherwise.
+func (s *Summary) nameOfRun(run *Run) string {
+	if s.pathDisplay != nil {
+		return s.pathDisplay(run.Path)
+	}
+
+	if s.workingDir != "" {
+		return strings.TrimPrefix(run.Path, s.workingDir+string(os.PathSeparator))
+	}
+
+	return run.Path
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
@@ -30,7 +31,7 @@ type JSONRun struct {
 	// Ended is the time when the run ended.
 	Ended time.Time `json:"Ended" jsonschema:"required"`
 	// Reason is the reason for the run result, if any.
-	Reason *string `json:"Reason,omitempty" jsonschema:"enum=retry succeeded,enum=error ignored,enum=run error,enum=exclude block,enum=ancestor error"`
+	Reason *string `json:"Reason,omitempty" jsonschema:"enum=retry succeeded,enum=error ignored,enum=run error,enum=exclude block,enum=ancestor error,enum=cache hit,enum=user skipped,enum=assumed applied,enum=cancelled,enum=skip predicate,enum=quarantined,enum=dependency timeout,enum=insufficient disk,enum=deadline exceeded"`
 	// Cause is the cause of the run result, if any.
 	Cause *string `json:"Cause,omitempty"`
 	// Name is the name of the run.
@@ -43,6 +44,8 @@ type JSONRun struct {
 	Cmd string `json:"Cmd,omitempty"`
 	// Args are the terraform CLI arguments.
 	Args []string `json:"Args,omitempty"`
+	// TerraformVersion is the implementation and version of OpenTofu/Terraform that ran the unit.
+	TerraformVersion string `json:"TerraformVersion,omitempty"`
 }
 
 // JSONRuns is a slice of JSONRun entries with helper methods.
@@ -87,6 +90,21 @@ func (runs JSONRuns) FindByName(name string) *JSONRun {
 	return nil
 }
 
+// Durations returns how long each succeeded run took, keyed by run name.
+func (runs JSONRuns) Durations() map[string]time.Duration {
+	durations := make(map[string]time.Duration, len(runs))
+
+	for _, run := range runs {
+		if run.Result != string(ResultSucceeded) || run.Ended.Before(run.Started) {
+			continue
+		}
+
+		durations[run.Name] = run.Ended.Sub(run.Started)
+	}
+
+	return durations
+}
+
 // Names returns a slice of all run names.
 // Useful for debugging and assertions in tests.
 func (runs JSONRuns) Names() []string {
@@ -225,12 +243,21 @@ func validateJSONReport(data []byte) error {
 }
 
 // WriteToFile writes the report to a file.
+//
+// The report is written to a temporary file next to the given one first, which then replaces it, so that
+// readers never see a partially written report.
 func (r *Report) WriteToFile(path string) error {
-	tmpFile, err := os.CreateTemp("", "terragrunt-report-*")
+	if r.workingDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(r.workingDir, path)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
 	if err != nil {
 		return err
 	}
 
+	defer os.Remove(tmpFile.Name()) //nolint:errcheck
+
 	r.mu.Lock()
 	r.SortRuns()
 	r.mu.Unlock()
@@ -240,6 +267,14 @@ func (r *Report) WriteToFile(path string) error {
 		err = r.WriteCSV(tmpFile)
 	case FormatJSON:
 		err = r.WriteJSON(tmpFile)
+	case FormatJUnit:
+		err = r.WriteJUnit(tmpFile)
+	case FormatSARIF:
+		err = r.WriteFindings(tmpFile)
+	case FormatChanges:
+		err = r.WriteChangesCSV(tmpFile)
+	case FormatParquet:
+		err = r.WriteParquet(tmpFile)
 	default:
 		return fmt.Errorf("unsupported format: %s", r.format)
 	}
@@ -252,10 +287,6 @@ func (r *Report) WriteToFile(path string) error {
 		return fmt.Errorf("failed to close report file: %w", err)
 	}
 
-	if r.workingDir != "" && !filepath.IsAbs(path) {
-		path = filepath.Join(r.workingDir, path)
-	}
-
 	return util.MoveFile(tmpFile.Name(), path)
 }
 
@@ -287,7 +318,7 @@ func (r *Report) WriteCSV(w io.Writer) error {
 		defer run.mu.RUnlock()
 
 		workingDir := effectiveWorkingDir(run, r.workingDir)
-		name := nameOfPath(run.Path, workingDir)
+		name := r.nameOfRun(run)
 
 		started := run.Started.Format(time.RFC3339)
 		ended := run.Ended.Format(time.RFC3339)
@@ -341,16 +372,17 @@ func (r *Report) WriteJSON(w io.Writer) error {
 		defer run.mu.RUnlock()
 
 		workingDir := effectiveWorkingDir(run, r.workingDir)
-		name := nameOfPath(run.Path, workingDir)
+		name := r.nameOfRun(run)
 
 		jsonRun := JSONRun{
-			Name:    name,
-			Started: run.Started,
-			Ended:   run.Ended,
-			Ref:     run.Ref,
-			Cmd:     run.Cmd,
-			Args:    run.Args,
-			Result:  string(run.Result),
+			Name:             name,
+			Started:          run.Started,
+			Ended:            run.Ended,
+			Ref:              run.Ref,
+			Cmd:              run.Cmd,
+			Args:             run.Args,
+			Result:           string(run.Result),
+			TerraformVersion: run.TerraformVersion,
 		}
 
 		if run.Reason != nil {
@@ -382,6 +414,122 @@ func (r *Report) WriteJSON(w io.Writer) error {
 	return err
 }
 
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds one test case per run of the report.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+}
+
+type junitTestCase struct {
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report to a writer in JUnit XML format, so that CI systems can display the result of
+// each unit natively. Every run is a test case: failed and early exit runs are failures, excluded runs are
+// skipped, and the reason and cause of the run are used as the message.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	suite := junitTestSuite{
+		Name:      "terragrunt",
+		Tests:     len(r.Runs),
+		TestCases: make([]junitTestCase, 0, len(r.Runs)),
+	}
+
+	var total time.Duration
+
+	for _, run := range r.Runs {
+		run.mu.RLock()
+		defer run.mu.RUnlock()
+
+		workingDir := effectiveWorkingDir(run, r.workingDir)
+
+		var duration time.Duration
+		if !run.Started.IsZero() && !run.Ended.IsZero() {
+			duration = run.Ended.Sub(run.Started)
+		}
+
+		total += duration
+
+		testCase := junitTestCase{
+			Name:      r.nameOfRun(run),
+			ClassName: run.Cmd,
+			Time:      junitSeconds(duration),
+		}
+
+		message := &junitMessage{}
+
+		if run.Reason != nil {
+			message.Message = string(*run.Reason)
+		}
+
+		if run.Cause != nil {
+			message.Text = string(*run.Cause)
+			if run.Reason != nil && *run.Reason == ReasonAncestorError && workingDir != "" {
+				message.Text = strings.TrimPrefix(message.Text, workingDir+string(os.PathSeparator))
+			}
+		}
+
+		switch run.Result {
+		case ResultFailed, ResultEarlyExit:
+			if message.Message == "" {
+				message.Message = string(run.Result)
+			}
+
+			testCase.Failure = message
+			suite.Failures++
+		case ResultExcluded:
+			testCase.Skipped = message
+			suite.Skipped++
+		case ResultSucceeded:
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// junitSeconds formats a duration as the number of seconds expected by JUnit time attributes.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
 // WriteSchemaToFile writes a JSON schema for the report to a file.
 func (r *Report) WriteSchemaToFile(path string) error {
 	tmpFile, err := os.CreateTemp("", "terragrunt-schema-*")
@@ -449,6 +597,26 @@ func nameOfPath(path string, workingDir string) string {
 	return path
 }
 
+// nameOfRun returns the name the run is displayed with in the report, its path transformed by the path display
+// function of the report when set, or relative to its working directory otherwise.
+func (r *Report) nameOfRun(run *Run) string {
+	if r.pathDisplay != nil {
+		return r.pathDisplay(run.Path)
+	}
+
+	return nameOfPath(run.Path, effectiveWorkingDir(run, r.workingDir))
+}
+
+// displayPath returns the path of a run as it is logged, transformed by the path display function of the
+// report when set.
+func (r *Report) displayPath(path string) string {
+	if r.pathDisplay != nil {
+		return r.pathDisplay(path)
+	}
+
+	return path
+}
+
 // effectiveWorkingDir returns the working directory to use for path computation.
 // If the run has a DiscoveryWorkingDir set (for worktree scenarios), use that.
 // Otherwise, fall back to the report's workingDir.
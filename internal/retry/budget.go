@@ -0,0 +1,47 @@
+package retry
+
+import "sync/atomic"
+
+// Budget bounds the total number of retries shared by every unit of a run, on top of the attempts
+// allowed for each unit, so that a widespread outage cannot trigger an unbounded number of retries.
+// A nil Budget is unlimited.
+type Budget struct {
+	remaining  atomic.Int64
+	maxRetries int
+}
+
+// NewBudget creates a budget of maxRetries retries.
+func NewBudget(maxRetries int) *Budget {
+	b := &Budget{maxRetries: maxRetries}
+	b.remaining.Store(int64(maxRetries))
+
+	return b
+}
+
+// Take consumes one retry from the budget. It returns false, without consuming anything, once the budget
+// is exhausted.
+func (b *Budget) Take() bool {
+	if b == nil {
+		return true
+	}
+
+	for {
+		remaining := b.remaining.Load()
+		if remaining <= 0 {
+			return false
+		}
+
+		if b.remaining.CompareAndSwap(remaining, remaining-1) {
+			return true
+		}
+	}
+}
+
+// Max returns the total number of retries of the budget.
+func (b *Budget) Max() int {
+	if b == nil {
+		return 0
+	}
+
+	return b.maxRetries
+}
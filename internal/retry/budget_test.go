@@ -0,0 +1,48 @@
+package retry_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gruntwork-io/terragrunt/internal/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	budget := retry.NewBudget(5)
+
+	var (
+		wg    sync.WaitGroup
+		taken atomic.Int64
+	)
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if budget.Take() {
+				taken.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(5), taken.Load())
+	assert.False(t, budget.Take())
+	assert.Equal(t, 5, budget.Max())
+}
+
+func TestBudget_Nil(t *testing.T) {
+	t.Parallel()
+
+	var budget *retry.Budget
+
+	assert.True(t, budget.Take())
+	assert.Equal(t, 0, budget.Max())
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"context"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+)
+
+type unitPathContextKey struct{}
+
+// ContextWithUnitPath returns a context carrying the path of the unit being run.
+func ContextWithUnitPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, unitPathContextKey{}, path)
+}
+
+// UnitPathFromContext returns the path of the unit being run, or an empty string when the context is not
+// scoped to a unit.
+func UnitPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(unitPathContextKey{}).(string)
+
+	return path
+}
+
+// UnitContextFunc adds values scoped to a unit to the context it runs with, e.g. a trace ID or credentials.
+type UnitContextFunc func(ctx context.Context, unit *component.Unit) context.Context
+
+// WithUnitContext sets a function adding values to the context of every unit. The values are visible to
+// every call made for the unit: the already applied check, the run itself and the JSON plan conversion.
+// The functions set by several options are applied in order.
+func WithUnitContext(unitContext UnitContextFunc) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		prev := runner.unitContext
+		if prev == nil {
+			runner.unitContext = unitContext
+			return
+		}
+
+		runner.unitContext = func(ctx context.Context, unit *component.Unit) context.Context {
+			return unitContext(prev(ctx, unit), unit)
+		}
+	}
+}
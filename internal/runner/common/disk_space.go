@@ -0,0 +1,40 @@
+package common
+
+import (
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// WithMinFreeDisk makes the UnitRunner check the free disk space of the working directory of a unit right before
+// it runs, and fail the unit with an InsufficientDiskError without running it when less than minBytes are free.
+// This fails fast with a clear reason instead of leaving half-written state or plan files behind once the disk
+// fills up mid-run.
+//
+// A failure to read the free disk space is only logged, and the unit runs as usual.
+func WithMinFreeDisk(minBytes uint64) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.minFreeDisk = minBytes
+	}
+}
+
+// checkFreeDisk returns an InsufficientDiskError when the working directory of the unit has less free disk
+// space than the configured minimum.
+func (runner *UnitRunner) checkFreeDisk(l log.Logger, opts *options.TerragruntOptions) error {
+	free, err := freeDiskBytes(opts.WorkingDir)
+	if err != nil {
+		l.Warnf("Failed to check the free disk space of unit %s: %v", runner.Unit.DisplayPath(), err)
+		return nil
+	}
+
+	if free < runner.minFreeDisk {
+		return errors.New(InsufficientDiskError{
+			UnitPath: runner.Unit.Path(),
+			Dir:      opts.WorkingDir,
+			Free:     free,
+			Min:      runner.minFreeDisk,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package common
+
+import "golang.org/x/sys/unix"
+
+// freeDiskBytes returns the number of bytes available to unprivileged users on the file system of the directory.
+func freeDiskBytes(dir string) (uint64, error) {
+	var stat unix.Statfs_t
+
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil //nolint:gosec,unconvert
+}
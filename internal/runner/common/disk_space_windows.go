@@ -0,0 +1,21 @@
+//go:build windows
+
+package common
+
+import "golang.org/x/sys/windows"
+
+// freeDiskBytes returns the number of bytes available to the current user on the volume of the directory.
+func freeDiskBytes(dir string) (uint64, error) {
+	dirPtr, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+
+	if err := windows.GetDiskFreeSpaceEx(dirPtr, &free, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return free, nil
+}
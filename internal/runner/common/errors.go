@@ -0,0 +1,54 @@
+package common
+
+import "fmt"
+
+// JSONConversionCancelledError is returned when the run is cancelled while a unit's plan is converted to JSON.
+type JSONConversionCancelledError struct {
+	Err      error
+	UnitPath string
+}
+
+func (e JSONConversionCancelledError) Error() string {
+	return fmt.Sprintf("converting the plan of unit %s to JSON was cancelled: %v", e.UnitPath, e.Err)
+}
+
+func (e JSONConversionCancelledError) Unwrap() error {
+	return e.Err
+}
+
+// ReportingError is returned with strict reporting when the result of a unit cannot be recorded in the report.
+type ReportingError struct {
+	Err      error
+	UnitPath string
+}
+
+func (e ReportingError) Error() string {
+	return fmt.Sprintf("failed to record the result of unit %s in the report: %v", e.UnitPath, e.Err)
+}
+
+func (e ReportingError) Unwrap() error {
+	return e.Err
+}
+
+// StalePlanError is returned when the outputs of the dependencies of a unit changed since its plan was made,
+// so that applying the plan would apply changes computed from outdated inputs.
+type StalePlanError struct {
+	UnitPath string
+}
+
+func (e StalePlanError) Error() string {
+	return fmt.Sprintf("the plan of unit %s is stale: the outputs of its dependencies changed since it was planned, plan it again", e.UnitPath)
+}
+
+// InsufficientDiskError is returned when the working directory of a unit has less free disk space than required
+// to run it.
+type InsufficientDiskError struct {
+	UnitPath string
+	Dir      string
+	Free     uint64
+	Min      uint64
+}
+
+func (e InsufficientDiskError) Error() string {
+	return fmt.Sprintf("not running unit %s: only %d bytes are free in %s, at least %d are required", e.UnitPath, e.Free, e.Dir, e.Min)
+}
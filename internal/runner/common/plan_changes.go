@@ -0,0 +1,47 @@
+package common
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/report"
+)
+
+// planJSON is the subset of the `show -json` plan representation needed to count resource changes.
+type planJSON struct {
+	ResourceChanges []struct {
+		Change struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// ParsePlanChanges counts the resource changes in a JSON plan produced by `show -json`.
+// Replacements are counted as one addition and one destruction, matching the Terraform plan summary.
+func ParsePlanChanges(data []byte) (report.ChangeCounts, error) {
+	var plan planJSON
+
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return report.ChangeCounts{}, err
+	}
+
+	counts := report.ChangeCounts{}
+
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+
+		if slices.Contains(actions, "create") {
+			counts.Add++
+		}
+
+		if slices.Contains(actions, "delete") {
+			counts.Destroy++
+		}
+
+		if slices.Contains(actions, "update") {
+			counts.Change++
+		}
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,39 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+)
+
+func TestParsePlanChanges(t *testing.T) {
+	t.Parallel()
+
+	plan := `{
+		"resource_changes": [
+			{"change": {"actions": ["create"]}},
+			{"change": {"actions": ["create"]}},
+			{"change": {"actions": ["update"]}},
+			{"change": {"actions": ["delete"]}},
+			{"change": {"actions": ["delete", "create"]}},
+			{"change": {"actions": ["no-op"]}},
+			{"change": {"actions": ["read"]}}
+		]
+	}`
+
+	counts, err := common.ParsePlanChanges([]byte(plan))
+	require.NoError(t, err)
+	assert.Equal(t, report.ChangeCounts{Add: 3, Change: 1, Destroy: 2}, counts)
+	assert.Equal(t, "+3 ~1 -2", counts.String())
+
+	counts, err = common.ParsePlanChanges([]byte(`{"format_version": "1.2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "no changes", counts.String())
+
+	_, err = common.ParsePlanChanges([]byte("not json"))
+	require.Error(t, err)
+}
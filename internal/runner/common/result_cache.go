@@ -0,0 +1,211 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/runner/runcfg"
+	"github.com/gruntwork-io/terragrunt/internal/util"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// ResultCache records the input hashes of units that ran successfully, so that units whose inputs have not
+// changed since their last successful run can be skipped.
+type ResultCache interface {
+	// Lookup reports whether the unit at the given path last succeeded with the given input hash.
+	Lookup(path, hash string) (bool, error)
+	// Store records that the unit at the given path succeeded with the given input hash.
+	Store(path, hash string) error
+}
+
+// unitInputs captures the parts of a unit run that determine its result.
+type unitInputs struct {
+	Config  *runcfg.RunConfig `json:"config"`
+	Files   string            `json:"files"`
+	Command string            `json:"command"`
+	Source  string            `json:"source"`
+	Args    []string          `json:"args"`
+}
+
+// UnitInputHash computes a hash of the inputs relevant to a unit run: the command and arguments, the resolved
+// configuration of the unit, including its inputs, remote state, generate blocks and includes, the contents of
+// its configuration file and of the files it includes, and the contents of its Terraform source when it is a
+// local directory. Remote sources are only hashed by their address.
+//
+// The outputs of the dependencies of the unit are only covered through its resolved inputs, the UnitRunner
+// additionally hashes the outputs of all its dependencies.
+func UnitInputHash(opts *options.TerragruntOptions, cfg *runcfg.RunConfig) (string, error) {
+	inputs := unitInputs{
+		Command: opts.TerraformCommand,
+		Source:  opts.Source,
+		Config:  cfg,
+	}
+
+	if opts.TerraformCliArgs != nil {
+		inputs.Args = opts.TerraformCliArgs.Slice()
+	}
+
+	if inputs.Source == "" && cfg != nil {
+		inputs.Source = cfg.Terraform.Source
+	}
+
+	configFiles := []string{opts.TerragruntConfigPath}
+
+	if cfg != nil {
+		for _, include := range cfg.ProcessedIncludes {
+			configFiles = append(configFiles, include.Path)
+		}
+	}
+
+	files := sha256.New()
+
+	for _, path := range slices.Sorted(slices.Values(configFiles)) {
+		if err := hashFile(files, path); err != nil {
+			return "", err
+		}
+	}
+
+	// A unit without a source runs the Terraform files next to its configuration.
+	if sourceDir := localSourceDir(opts, inputs.Source); sourceDir != "" {
+		if err := hashTree(files, sourceDir); err != nil {
+			return "", err
+		}
+	}
+
+	inputs.Files = hex.EncodeToString(files.Sum(nil))
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// localSourceDir returns the directory holding the Terraform source of the unit, or an empty string when the
+// source is not a local directory. Only the part before a "//" subdirectory separator is returned, as the
+// module may refer to files outside of the subdirectory.
+func localSourceDir(opts *options.TerragruntOptions, source string) string {
+	configDir := filepath.Dir(opts.TerragruntConfigPath)
+
+	if source == "" {
+		return configDir
+	}
+
+	root, _, _ := strings.Cut(source, "//")
+	if root == "" {
+		return ""
+	}
+
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(configDir, root)
+	}
+
+	if !util.IsDir(root) {
+		return ""
+	}
+
+	return root
+}
+
+// hashFile writes the path and contents of the given file to the hash. Missing files are skipped.
+func hashFile(hash io.Writer, path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(hash, "%s\n%d\n", path, len(data))
+	hash.Write(data) //nolint:errcheck
+
+	return nil
+}
+
+// hashTree writes the relative path and contents of every file below the given directory to the hash, in
+// lexical order, skipping the directories that never hold sources, such as the Terragrunt cache.
+func hashTree(hash io.Writer, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return util.SkipDirIfIgnorable(d.Name())
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "%s\n%d\n", filepath.ToSlash(rel), len(data))
+		hash.Write(data) //nolint:errcheck
+
+		return nil
+	})
+}
+
+// cacheable reports whether the result of the unit run may be taken from the result cache. Runs converting
+// their plan to JSON always run, as the JSON plan and the resource changes recorded in the report can only
+// come from the run itself.
+func (runner *UnitRunner) cacheable(opts *options.TerragruntOptions) bool {
+	if !producesPlan(opts.TerraformCommand) {
+		return true
+	}
+
+	return runner.Unit.OutputJSONFile(opts.RootWorkingDir, opts.JSONOutputFolder) == "" && !opts.KeepJSONInMemoryOnly
+}
+
+// inputHash returns the key of the unit run in the result cache: its UnitInputHash combined with the outputs of
+// all its dependencies. It returns an empty hash, bypassing the cache, when the outputs of a dependency can't
+// be read, e.g. because it was never applied.
+func (runner *UnitRunner) inputHash(
+	ctx context.Context,
+	l log.Logger,
+	opts *options.TerragruntOptions,
+	cfg *runcfg.RunConfig,
+) (string, error) {
+	hash, err := UnitInputHash(opts, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if len(runner.Unit.Dependencies()) == 0 {
+		return hash, nil
+	}
+
+	depHash, err := runner.dependencyOutputsHash(ctx, l, opts)
+	if err != nil {
+		l.Debugf("Not using the result cache for unit %s: %v", runner.Unit.DisplayPath(), err)
+		return "", nil
+	}
+
+	sum := sha256.Sum256([]byte(hash + "\n" + depHash))
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,191 @@
+package common_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/codegen"
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runcfg"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+type staticCache struct {
+	stored map[string]string
+	hit    bool
+}
+
+func (c *staticCache) Lookup(_, _ string) (bool, error) {
+	return c.hit, nil
+}
+
+func (c *staticCache) Store(path, hash string) error {
+	c.stored[path] = hash
+	return nil
+}
+
+func TestUnitInputHash_ChangesWithInputs(t *testing.T) {
+	t.Parallel()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(t.TempDir(), "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.TerraformCommand = "apply"
+
+	first, err := common.UnitInputHash(opts, &runcfg.RunConfig{Inputs: map[string]any{"a": 1}})
+	require.NoError(t, err)
+
+	same, err := common.UnitInputHash(opts, &runcfg.RunConfig{Inputs: map[string]any{"a": 1}})
+	require.NoError(t, err)
+
+	changed, err := common.UnitInputHash(opts, &runcfg.RunConfig{Inputs: map[string]any{"a": 2}})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, changed)
+}
+
+func TestUnitRunner_ResultCacheHitSkipsRun(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	cache := &staticCache{hit: true, stored: map[string]string{}}
+	r := report.NewReport()
+	unit := component.NewUnit(unitDir)
+
+	runner := common.NewUnitRunner(unit, common.WithResultCache(cache))
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, &runcfg.RunConfig{}, nil))
+
+	assert.Equal(t, common.Finished, runner.Status)
+	assert.Empty(t, cache.stored)
+
+	run, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultSucceeded, run.Result)
+	require.NotNil(t, run.Reason)
+	assert.Equal(t, report.ReasonCacheHit, *run.Reason)
+}
+
+func TestUnitInputHash_ChangesWithFilesAndConfig(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "modules", "vpc")
+	rootConfig := filepath.Join(rootDir, "root.hcl")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDir, ".terraform"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), []byte(`# v1`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte(`# v1`), 0o644))
+	require.NoError(t, os.WriteFile(rootConfig, []byte(`# v1`), 0o644))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.TerraformCommand = "apply"
+
+	cfg := func() *runcfg.RunConfig {
+		return &runcfg.RunConfig{
+			Terraform:         runcfg.TerraformConfig{Source: "../modules//vpc"},
+			ProcessedIncludes: map[string]runcfg.IncludeConfig{"root": {Name: "root", Path: rootConfig}},
+		}
+	}
+
+	hash := func(cfg *runcfg.RunConfig) string {
+		h, err := common.UnitInputHash(opts, cfg)
+		require.NoError(t, err)
+
+		return h
+	}
+
+	first := hash(cfg())
+	assert.Equal(t, first, hash(cfg()))
+
+	// Files of the Terraform cache of the module are not part of its source.
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, ".terraform", "state"), []byte(`{}`), 0o644))
+	assert.Equal(t, first, hash(cfg()))
+
+	withGenerate := cfg()
+	withGenerate.GenerateConfigs = map[string]codegen.GenerateConfig{"provider": {Path: "provider.tf", Contents: "x"}}
+	assert.NotEqual(t, first, hash(withGenerate))
+
+	changes := map[string]string{
+		"module source": filepath.Join(moduleDir, "main.tf"),
+		"unit config":   filepath.Join(unitDir, "terragrunt.hcl"),
+		"included file": rootConfig,
+	}
+
+	for name, path := range changes {
+		before := hash(cfg())
+
+		require.NoError(t, os.WriteFile(path, []byte(`# changed `+name), 0o644))
+		assert.NotEqual(t, before, hash(cfg()), name)
+	}
+}
+
+func TestUnitRunner_ResultCacheStoresAfterJSONConversion(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	// The plan succeeds, but its conversion to JSON fails.
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) touch tfplan.tfplan ;;
+  show) exit 1 ;;
+esac
+`), 0o755))
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	newOpts := func(jsonDir string) *options.TerragruntOptions {
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.TerraformCommand = "plan"
+		opts.TerraformCliArgs = iacargs.New("plan")
+		opts.JSONOutputFolder = jsonDir
+
+		return opts
+	}
+
+	// A hit is ignored when the plan is converted to JSON, and the failed conversion is not cached.
+	cache := &staticCache{hit: true, stored: map[string]string{}}
+	runner := common.NewUnitRunner(unit, common.WithResultCache(cache))
+
+	require.Error(t, runner.Run(t.Context(), thlogger.CreateLogger(), newOpts(filepath.Join(rootDir, "json")), nil, cfg, nil))
+	assert.Empty(t, cache.stored)
+
+	// Without JSON conversion, the successful plan is cached.
+	cache.hit = false
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), newOpts(""), nil, cfg, nil))
+	assert.Contains(t, cache.stored, unitDir)
+}
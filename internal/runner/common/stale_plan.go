@@ -0,0 +1,126 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/configbridge"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// DependencyOutputsHashFile is the name of the file written next to the plan file of a unit in the output
+// folder, holding a hash of the outputs of its dependencies at the time it was planned.
+const DependencyOutputsHashFile = "tfplan.dependencies.sha256"
+
+// WithStalePlanCheck makes the UnitRunner record a hash of the outputs of the dependencies of a unit, as read
+// right before it is planned into the output folder, and fail the unit with a StalePlanError without applying it when the outputs
+// of its dependencies changed by the time the plan is applied.
+//
+// Only the outputs read through dependency blocks are compared, and plans without a recorded hash, e.g. plans
+// made before the check was enabled, are applied as usual.
+func WithStalePlanCheck() UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.stalePlanCheck = true
+	}
+}
+
+// dependencyOutputsHashFile returns the path of the file holding the hash of the dependency outputs of the
+// unit, or an empty path when no output folder is set.
+func (runner *UnitRunner) dependencyOutputsHashFile(opts *options.TerragruntOptions) string {
+	planFile := runner.Unit.OutputFile(opts.RootWorkingDir, opts.OutputFolder)
+	if planFile == "" {
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(planFile), DependencyOutputsHashFile)
+}
+
+// dependencyOutputsHash returns a hash of the outputs of the dependencies of the unit, in dependency path order.
+// Outputs read while parsing the configuration of the unit are taken from the output cache of the run, the
+// others are fetched from the state of the dependency. It fails when the outputs of a dependency can't be read.
+func (runner *UnitRunner) dependencyOutputsHash(ctx context.Context, l log.Logger, opts *options.TerragruntOptions) (string, error) {
+	deps := slices.Clone(runner.Unit.Dependencies())
+
+	slices.SortFunc(deps, func(a, b component.Component) int {
+		return strings.Compare(a.Path(), b.Path())
+	})
+
+	ctx, pctx := configbridge.NewParsingContext(ctx, l, opts)
+
+	hash := sha256.New()
+
+	for _, dep := range deps {
+		configPath := config.GetDefaultConfigPath(dep.Path())
+		if unit, ok := dep.(*component.Unit); ok && unit.ConfigFile() != "" {
+			configPath = filepath.Join(dep.Path(), unit.ConfigFile())
+		}
+
+		outputs, err := config.DependencyOutputJSON(ctx, pctx, l, configPath)
+		if err != nil {
+			return "", errors.Errorf("failed to read the outputs of dependency %s of unit %s: %w", dep.Path(), runner.Unit.Path(), err)
+		}
+
+		hash.Write([]byte(dep.Path() + "\n"))
+		hash.Write(outputs)
+		hash.Write([]byte("\n"))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordDependencyOutputs writes the given hash of the dependency outputs of the unit next to its plan file.
+// A failure to write it is only logged, as it only disables the check for this plan.
+func (runner *UnitRunner) recordDependencyOutputs(l log.Logger, opts *options.TerragruntOptions, hash string) {
+	hashFile := runner.dependencyOutputsHashFile(opts)
+	if hashFile == "" {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hashFile), os.ModePerm); err != nil {
+		l.Warnf("Failed to record dependency outputs of unit %s: %v", runner.Unit.DisplayPath(), err)
+		return
+	}
+
+	if err := os.WriteFile(hashFile, []byte(hash), os.ModePerm); err != nil {
+		l.Warnf("Failed to record dependency outputs of unit %s: %v", runner.Unit.DisplayPath(), err)
+	}
+}
+
+// checkStalePlan returns a StalePlanError when the dependency outputs of the unit differ from the ones recorded
+// when it was planned.
+func (runner *UnitRunner) checkStalePlan(ctx context.Context, l log.Logger, opts *options.TerragruntOptions) error {
+	hashFile := runner.dependencyOutputsHashFile(opts)
+	if hashFile == "" {
+		return nil
+	}
+
+	recorded, err := os.ReadFile(hashFile)
+	if os.IsNotExist(err) {
+		l.Debugf("Not checking the plan of unit %s for staleness, no dependency outputs were recorded", runner.Unit.DisplayPath())
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	current, err := runner.dependencyOutputsHash(ctx, l, opts)
+	if err != nil {
+		return err
+	}
+
+	if string(recorded) != current {
+		return errors.New(StalePlanError{UnitPath: runner.Unit.Path()})
+	}
+
+	return nil
+}
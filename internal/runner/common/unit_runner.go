@@ -3,11 +3,15 @@ package common
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime/pprof"
+	"sync"
 
 	"github.com/gruntwork-io/terragrunt/internal/component"
 	"github.com/gruntwork-io/terragrunt/internal/configbridge"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
 	"github.com/gruntwork-io/terragrunt/internal/iacargs"
 	"github.com/gruntwork-io/terragrunt/internal/report"
 	"github.com/gruntwork-io/terragrunt/internal/runner/run"
@@ -30,19 +34,174 @@ const (
 
 // UnitRunner handles the logic for running a single component.Unit.
 type UnitRunner struct {
-	Err    error
-	Unit   *component.Unit
-	Status UnitStatus
+	Err         error
+	Unit        *component.Unit
+	resultCache ResultCache
+	transform   OptionsTransform
+	// cpuProfileDir is the folder under which a CPU profile of each unit run is written, if set.
+	cpuProfileDir string
+	Status        UnitStatus
+	// quarantined holds the paths of the units whose failures are reported as quarantined.
+	quarantined map[string]bool
+	// alreadyApplied decides right before a unit runs whether it is already applied, if set.
+	alreadyApplied AlreadyAppliedFunc
+	// unitContext adds values scoped to the unit to the context it runs with, if set.
+	unitContext UnitContextFunc
+	// onSuccess is called after the unit ran successfully, if set.
+	onSuccess UnitSuccessFunc
+	// failOnSuccessError makes an error returned by onSuccess fail the unit.
+	failOnSuccessError bool
+	// planFileResolver overrides the plan file converted to JSON, if set.
+	planFileResolver PlanFileResolver
+	// syncOutputs makes the JSON plan output be flushed to stable storage before the unit is reported finished.
+	syncOutputs bool
+	// strictReporting makes a failure to record the result of the unit in the report fail the unit.
+	strictReporting bool
+	// stalePlanCheck records the dependency outputs of planned units, and fails applies when they changed.
+	stalePlanCheck bool
+	// minFreeDisk is the number of bytes that must be free in the working directory of the unit, if positive.
+	minFreeDisk uint64
+	// jsonOnlyForDepended skips the JSON plan conversion of units that no other unit depends on.
+	jsonOnlyForDepended bool
+}
+
+// UnitRunnerOption configures a UnitRunner.
+type UnitRunnerOption func(*UnitRunner)
+
+// WithResultCache makes the UnitRunner skip units whose inputs are unchanged since their last successful run,
+// as recorded in the given cache. The inputs cover the resolved configuration, the configuration and source
+// files and the outputs of the dependencies of the unit, see UnitInputHash. Runs converting their plan to JSON
+// are never skipped, and a run is only recorded once it fully succeeded.
+func WithResultCache(cache ResultCache) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.resultCache = cache
+	}
+}
+
+// OptionsTransform adjusts the options of a unit right before it runs.
+// It receives a clone of the unit's options, so it never mutates shared state, and returns the options to run with.
+// Returning nil options runs the unit with the received clone.
+type OptionsTransform func(unit *component.Unit, opts *options.TerragruntOptions) (*options.TerragruntOptions, error)
+
+// WithOptionsTransform sets a transform that is applied to the options of the unit before it runs.
+// An error returned by the transform fails the unit without running it.
+func WithOptionsTransform(transform OptionsTransform) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.transform = transform
+	}
+}
+
+// WithCPUProfileDir captures a CPU profile of the unit run and writes it under the given folder,
+// at the unit's path relative to the working directory.
+//
+// CPU profiles are process-wide, so only one unit can be profiled at a time, even across concurrent runs of
+// the process: the units started while another one is being profiled are not profiled.
+func WithCPUProfileDir(dir string) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.cpuProfileDir = dir
+	}
+}
+
+// WithSyncedOutputs makes the UnitRunner fsync the JSON plan output of the unit before reporting it finished.
+//
+// The output file is always written before the unit run returns, so dependents are never scheduled before
+// it exists. Syncing additionally guarantees the file survives a crash of the host once the unit is finished,
+// at the cost of a slower write.
+func WithSyncedOutputs() UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.syncOutputs = true
+	}
+}
+
+// WithJSONOutputOnlyForDependedUnits makes the UnitRunner convert the plan of a unit to JSON only when another
+// unit reads its outputs, skipping the costly show call for the leaf units whose outputs nothing consumes.
+// Dependents that only order after the unit, through a dependencies block or a dependency block with
+// skip_outputs, still wait for it but do not count as reading its outputs.
+// The resource changes of the skipped units are not recorded in the report.
+func WithJSONOutputOnlyForDependedUnits() UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.jsonOnlyForDepended = true
+	}
+}
+
+// PlanFileResolver returns the plan file of a unit to convert to JSON, given the options the unit ran with,
+// e.g. after decrypting or relocating the plan written by the run. Returning an empty path skips the conversion.
+type PlanFileResolver func(ctx context.Context, unit *component.Unit, opts *options.TerragruntOptions) (string, error)
+
+// WithPlanFileResolver sets a function that resolves the plan file read by the show call converting the plan
+// of a unit to JSON, instead of the plan file the unit run writes to. A relative path is resolved against the
+// working directory of the unit run. An error returned by the function fails the unit.
+func WithPlanFileResolver(resolver PlanFileResolver) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.planFileResolver = resolver
+	}
+}
+
+// WithStrictReporting makes the UnitRunner fail the unit with a ReportingError when its result cannot be
+// recorded in the report, e.g. for teams relying on the report for compliance. By default, such a failure
+// is only logged and the result is missing from the report.
+func WithStrictReporting() UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.strictReporting = true
+	}
+}
+
+// WithQuarantinedUnits reports the failures of the units at the given absolute paths as quarantined
+// instead of as run errors.
+func WithQuarantinedUnits(paths ...string) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		if runner.quarantined == nil {
+			runner.quarantined = make(map[string]bool, len(paths))
+		}
+
+		for _, path := range paths {
+			runner.quarantined[filepath.Clean(path)] = true
+		}
+	}
+}
+
+// AlreadyAppliedFunc decides whether a unit is already applied, e.g. by checking a deployment record.
+type AlreadyAppliedFunc func(ctx context.Context, unit *component.Unit) (bool, error)
+
+// WithAlreadyApplied sets a function that is called right before the unit runs to decide whether it is
+// already applied. A unit that is already applied is not run, and is reported as excluded because it is
+// assumed to be applied, as with units assumed to be applied when the run is set up.
+// An error returned by the function fails the unit without running it.
+func WithAlreadyApplied(alreadyApplied AlreadyAppliedFunc) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.alreadyApplied = alreadyApplied
+	}
+}
+
+// UnitSuccessFunc is called after a unit ran successfully, with the options it ran with, e.g. to replicate
+// its state to a secondary backend after an apply.
+type UnitSuccessFunc func(ctx context.Context, unit *component.Unit, opts *options.TerragruntOptions) error
+
+// WithOnSuccess sets a function that is called after every successful run of a unit, before the units
+// waiting on it are started. The function can tell apart the commands from opts.TerraformCommand.
+// An error returned by the function fails the unit when failUnit is set, and is only logged otherwise.
+func WithOnSuccess(onSuccess UnitSuccessFunc, failUnit bool) UnitRunnerOption {
+	return func(runner *UnitRunner) {
+		runner.onSuccess = onSuccess
+		runner.failOnSuccessError = failUnit
+	}
 }
 
 // NewUnitRunner creates a UnitRunner from a component.Unit.
-func NewUnitRunner(unit *component.Unit) *UnitRunner {
-	return &UnitRunner{
+func NewUnitRunner(unit *component.Unit, opts ...UnitRunnerOption) *UnitRunner {
+	runner := &UnitRunner{
 		Unit:   unit,
 		Status: Waiting,
 	}
+
+	for _, opt := range opts {
+		opt(runner)
+	}
+
+	return runner
 }
 
+// runTerragrunt runs the unit and returns the options it ran with, after the options transform if any.
 func (runner *UnitRunner) runTerragrunt(
 	ctx context.Context,
 	l log.Logger,
@@ -50,13 +209,13 @@ func (runner *UnitRunner) runTerragrunt(
 	r *report.Report,
 	cfg *runcfg.RunConfig,
 	credsGetter *creds.Getter,
-) error {
+) (*options.TerragruntOptions, error) {
 	l.Debugf("Running %s", util.RelPathForLog(opts.RootWorkingDir, runner.Unit.Path(), opts.Writers.LogShowAbsPaths))
 
 	defer func() {
 		// Flush buffered output for this unit, if the writer supports it.
 		if err := component.FlushOutput(runner.Unit, opts.Writers.Writer); err != nil {
-			l.Errorf("Error flushing output for unit %s: %v", runner.Unit.Path(), err)
+			l.Errorf("Error flushing output for unit %s: %v", runner.Unit.DisplayPath(), err)
 		}
 	}()
 
@@ -79,10 +238,28 @@ func (runner *UnitRunner) runTerragrunt(
 		}
 
 		if _, err := r.EnsureRun(l, unitPath, ensureOpts...); err != nil {
-			return err
+			return opts, err
 		}
 	}
 
+	if runner.transform != nil {
+		cloned := opts.Clone()
+
+		transformed, err := runner.transform(runner.Unit, cloned)
+		if err != nil {
+			err = errors.Errorf("options transform for unit %s failed: %w", runner.Unit.Path(), err)
+
+			return opts, runner.endRunFailed(l, r, err)
+		}
+
+		// Transforms that only modify the clone in place may return nil.
+		if transformed == nil {
+			transformed = cloned
+		}
+
+		opts = transformed
+	}
+
 	// Use a unit-scoped detailed exit code so retries in this unit don't clobber global state
 	globalExitCode := tf.DetailedExitCodeFromContext(ctx)
 
@@ -90,8 +267,14 @@ func (runner *UnitRunner) runTerragrunt(
 
 	ctx = tf.ContextWithDetailedExitCode(ctx, unitExitCode)
 
+	runner.recordTerraformVersion(ctx, l, r, opts)
+
+	stopProfile := runner.startCPUProfile(l, opts)
+
 	runErr := run.Run(ctx, l, configbridge.NewRunOptions(opts), r, cfg, credsGetter)
 
+	stopProfile()
+
 	// Store the unit exit code in the global map using the unit path as key.
 	if globalExitCode != nil {
 		unitPath := runner.Unit.Path()
@@ -105,29 +288,180 @@ func (runner *UnitRunner) runTerragrunt(
 		unitPath = filepath.Clean(unitPath)
 
 		if runErr != nil {
-			if endErr := r.EndRun(
-				l,
-				unitPath,
-				report.WithResult(report.ResultFailed),
-				report.WithReason(report.ReasonRunError),
-				report.WithCauseRunError(runErr.Error()),
-			); endErr != nil {
-				l.Errorf("Error ending run for unit %s: %v", unitPath, endErr)
-			}
+			runErr = runner.endRunFailed(l, r, runErr)
 		} else {
 			if endErr := r.EndRun(
 				l,
 				unitPath,
 				report.WithResult(report.ResultSucceeded),
 			); endErr != nil {
+				if runner.strictReporting {
+					return opts, errors.New(ReportingError{UnitPath: unitPath, Err: endErr})
+				}
+
 				l.Errorf("Error ending run for unit %s: %v", unitPath, endErr)
 			}
 		}
 	}
 
+	return opts, runErr
+}
+
+// cpuProfiling is held while a unit run is profiled. The CPU profiler is process-wide, so it is owned by at
+// most one unit run of the process at a time, across concurrent and nested runs.
+var cpuProfiling sync.Mutex
+
+// startCPUProfile starts capturing a CPU profile of the unit run, if enabled, and returns a function stopping it.
+// Failing to profile a unit never fails its run.
+//
+// The profile is written to a temporary file moved in place once complete, so that a run that cannot profile
+// the unit never truncates or removes a profile of the unit written by another run.
+func (runner *UnitRunner) startCPUProfile(l log.Logger, opts *options.TerragruntOptions) func() {
+	noop := func() {}
+
+	if runner.cpuProfileDir == "" {
+		return noop
+	}
+
+	if !cpuProfiling.TryLock() {
+		l.Warnf("Not profiling unit %s, another unit is already being profiled", runner.Unit.DisplayPath())
+		return noop
+	}
+
+	profileFile := runner.Unit.CPUProfileFile(opts.RootWorkingDir, runner.cpuProfileDir)
+
+	if err := os.MkdirAll(filepath.Dir(profileFile), os.ModePerm); err != nil {
+		cpuProfiling.Unlock()
+		l.Warnf("Failed to create CPU profile directory for unit %s: %v", runner.Unit.DisplayPath(), err)
+
+		return noop
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(profileFile), filepath.Base(profileFile)+".*")
+	if err != nil {
+		cpuProfiling.Unlock()
+		l.Warnf("Failed to create CPU profile for unit %s: %v", runner.Unit.DisplayPath(), err)
+
+		return noop
+	}
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		cpuProfiling.Unlock()
+		l.Warnf("Not profiling unit %s, the CPU profiler is already in use: %v", runner.Unit.DisplayPath(), err)
+
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return noop
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		cpuProfiling.Unlock()
+
+		if err := f.Close(); err != nil {
+			l.Warnf("Failed to write CPU profile for unit %s: %v", runner.Unit.DisplayPath(), err)
+			_ = os.Remove(f.Name())
+
+			return
+		}
+
+		if err := os.Rename(f.Name(), profileFile); err != nil {
+			l.Warnf("Failed to write CPU profile for unit %s: %v", runner.Unit.DisplayPath(), err)
+			_ = os.Remove(f.Name())
+		}
+	}
+}
+
+// endRunFailed ends the report run of the unit as failed with the given error, and returns the error the unit
+// fails with.
+func (runner *UnitRunner) endRunFailed(l log.Logger, r *report.Report, runErr error) error {
+	if r == nil {
+		return runErr
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	var diskErr InsufficientDiskError
+
+	reason := report.ReasonRunError
+	if errors.As(runErr, &diskErr) {
+		reason = report.ReasonInsufficientDisk
+	}
+
+	if runner.quarantined[unitPath] {
+		reason = report.ReasonQuarantined
+	}
+
+	if endErr := r.EndRun(
+		l,
+		unitPath,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(reason),
+		report.WithCauseRunError(runErr.Error()),
+	); endErr != nil {
+		return runner.reportingFailure(l, unitPath, runErr, endErr)
+	}
+
 	return runErr
 }
 
+// endRunCancelled ends the report run of the unit as failed because the run was cancelled, or because its
+// deadline passed, and returns the error the unit fails with, starting with the given one.
+func (runner *UnitRunner) endRunCancelled(l log.Logger, r *report.Report, ctxErr, err error) error {
+	if r == nil {
+		return err
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	reason := report.ReasonCancelled
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		reason = report.ReasonDeadlineExceeded
+	}
+
+	if endErr := r.EndRun(
+		l,
+		unitPath,
+		report.WithResult(report.ResultFailed),
+		report.WithReason(reason),
+		report.WithCauseRunError(ctxErr.Error()),
+	); endErr != nil {
+		return runner.reportingFailure(l, unitPath, err, endErr)
+	}
+
+	return err
+}
+
+// reportingFailure returns the error the failing unit at the given path fails with when its result cannot be
+// recorded in the report: the error joined with a ReportingError under strict reporting, or the error alone
+// after logging the reporting failure otherwise.
+func (runner *UnitRunner) reportingFailure(l log.Logger, unitPath string, err, reportErr error) error {
+	if !runner.strictReporting {
+		l.Errorf("Error ending run for unit %s: %v", unitPath, reportErr)
+		return err
+	}
+
+	return errors.Join(err, errors.New(ReportingError{UnitPath: unitPath, Err: reportErr}))
+}
+
+// failBeforeRun records the unit as failed with the given error in the report when it fails before its run
+// started, so that the unit appears in the report even though the run never added it, and returns the error the
+// unit fails with.
+func (runner *UnitRunner) failBeforeRun(l log.Logger, r *report.Report, err error) error {
+	if r != nil {
+		if _, ensureErr := r.EnsureRun(l, filepath.Clean(runner.Unit.Path())); ensureErr != nil {
+			if runner.strictReporting {
+				return errors.Join(err, errors.New(ReportingError{UnitPath: filepath.Clean(runner.Unit.Path()), Err: ensureErr}))
+			}
+
+			l.Errorf("Error ensuring run for unit %s: %v", runner.Unit.DisplayPath(), ensureErr)
+		}
+	}
+
+	return runner.endRunFailed(l, r, err)
+}
+
 // Run executes a component.Unit right now.
 func (runner *UnitRunner) Run(
 	ctx context.Context,
@@ -143,50 +477,394 @@ func (runner *UnitRunner) Run(
 		return nil
 	}
 
-	if err := runner.runTerragrunt(ctx, l, opts, r, cfg, credsGetter); err != nil {
-		return err
+	// The run of the unit and the JSON conversion of its plan share the same unit-scoped context.
+	ctx = ContextWithUnitPath(ctx, runner.Unit.Path())
+	if runner.unitContext != nil {
+		ctx = runner.unitContext(ctx, runner.Unit)
 	}
 
-	// convert terragrunt output to json
-	if runner.Unit.OutputJSONFile(opts.RootWorkingDir, opts.JSONOutputFolder) != "" {
-		jsonLogger, jsonOptions, err := opts.CloneWithConfigPath(
-			l,
-			opts.TerragruntConfigPath,
-		)
+	if runner.alreadyApplied != nil {
+		applied, err := runner.alreadyApplied(ctx, runner.Unit)
 		if err != nil {
-			return err
+			return runner.failBeforeRun(l, r, errors.Errorf("already applied check for unit %s failed: %w", runner.Unit.Path(), err))
 		}
 
-		stdout := bytes.Buffer{}
-		jsonOptions.ForwardTFStdout = true
-		jsonOptions.JSONLogFormat = false
-		jsonOptions.Writers.Writer = &stdout
-		jsonOptions.TerraformCommand = tf.CommandNameShow
-		planFile := runner.Unit.PlanFile(
-			opts.RootWorkingDir, opts.OutputFolder, opts.JSONOutputFolder, opts.TerraformCommand,
-		)
-		jsonOptions.TerraformCliArgs = iacargs.New(tf.CommandNameShow, "-json", planFile)
+		if applied {
+			return runner.skipAlreadyApplied(l, r)
+		}
+	}
 
-		// Use an ad-hoc report to avoid polluting the main report
-		adhocReport := report.NewReport()
+	var inputHash string
 
-		runOpts := configbridge.NewRunOptions(jsonOptions)
-		if err := run.Run(ctx, jsonLogger, runOpts, adhocReport, cfg, credsGetter); err != nil {
+	if runner.resultCache != nil && runner.cacheable(opts) {
+		hash, err := runner.inputHash(ctx, l, opts, cfg)
+		if err != nil {
 			return err
 		}
 
-		// save the json output to the file plan file
-		outputFile := runner.Unit.OutputJSONFile(opts.RootWorkingDir, opts.JSONOutputFolder)
-		jsonDir := filepath.Dir(outputFile)
+		if hash != "" {
+			hit, err := runner.resultCache.Lookup(runner.Unit.Path(), hash)
+			if err != nil {
+				return err
+			}
 
-		if err := os.MkdirAll(jsonDir, os.ModePerm); err != nil {
-			return err
+			if hit {
+				return runner.skipCached(l, r)
+			}
 		}
 
-		if err := os.WriteFile(outputFile, stdout.Bytes(), os.ModePerm); err != nil {
+		inputHash = hash
+	}
+
+	if runner.minFreeDisk > 0 {
+		if err := runner.checkFreeDisk(l, opts); err != nil {
+			return runner.failBeforeRun(l, r, err)
+		}
+	}
+
+	if runner.stalePlanCheck && opts.TerraformCommand == tf.CommandNameApply {
+		if err := runner.checkStalePlan(ctx, l, opts); err != nil {
+			return runner.failBeforeRun(l, r, err)
+		}
+	}
+
+	// The outputs the plan is based on are hashed before it is made, and only recorded once it succeeded.
+	var depOutputsHash string
+
+	if runner.stalePlanCheck && opts.TerraformCommand == tf.CommandNamePlan {
+		hash, err := runner.dependencyOutputsHash(ctx, l, opts)
+		if err != nil {
+			return runner.failBeforeRun(l, r, err)
+		}
+
+		depOutputsHash = hash
+	}
+
+	// The JSON conversion must see the same working and download directories as the unit run,
+	// so it continues with the options the unit actually ran with.
+	opts, err := runner.runTerragrunt(ctx, l, opts, r, cfg, credsGetter)
+	if err != nil {
+		return err
+	}
+
+	if runner.stalePlanCheck && opts.TerraformCommand == tf.CommandNamePlan {
+		runner.recordDependencyOutputs(l, opts, depOutputsHash)
+	}
+
+	if runner.onSuccess != nil {
+		if err := runner.onSuccess(ctx, runner.Unit, opts); err != nil {
+			err = errors.Errorf("on success hook for unit %s failed: %w", runner.Unit.Path(), err)
+
+			if runner.failOnSuccessError {
+				return runner.endRunFailed(l, r, err)
+			}
+
+			l.Warnf("%v", err)
+		}
+	}
+
+	if err := runner.convertPlanToJSON(ctx, l, opts, r, cfg, credsGetter); err != nil {
+		return err
+	}
+
+	// The result is only cached once the whole run, including the JSON conversion, succeeded.
+	if runner.resultCache != nil && inputHash != "" {
+		if err := runner.resultCache.Store(runner.Unit.Path(), inputHash); err != nil {
+			l.Warnf("Failed to update result cache for unit %s: %v", runner.Unit.DisplayPath(), err)
+		}
+	}
+
+	return nil
+}
+
+// convertPlanToJSON converts the plan of the unit to JSON when a JSON output folder is set or the JSON plans
+// are kept in memory, writes it to the JSON output folder and records the resource changes in the report.
+func (runner *UnitRunner) convertPlanToJSON(
+	ctx context.Context,
+	l log.Logger,
+	opts *options.TerragruntOptions,
+	r *report.Report,
+	cfg *runcfg.RunConfig,
+	credsGetter *creds.Getter,
+) error {
+	if runner.Unit.OutputJSONFile(opts.RootWorkingDir, opts.JSONOutputFolder) == "" && !opts.KeepJSONInMemoryOnly {
+		return nil
+	}
+
+	// Commands such as destroy or output have no plan to convert.
+	if !producesPlan(opts.TerraformCommand) {
+		return nil
+	}
+
+	if runner.jsonOnlyForDepended && !runner.outputsRead() {
+		l.Debugf("Skipping JSON conversion for unit %s, no unit reads its outputs", runner.Unit.DisplayPath())
+		return nil
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return runner.endRunCancelled(l, r, ctxErr, errors.New(JSONConversionCancelledError{UnitPath: runner.Unit.Path(), Err: ctxErr}))
+	}
+
+	planFile := runner.Unit.PlanFile(
+		opts.RootWorkingDir, opts.OutputFolder, opts.JSONOutputFolder, opts.TerraformCommand,
+	)
+
+	if runner.planFileResolver != nil {
+		resolved, err := runner.planFileResolver(ctx, runner.Unit, opts)
+		if err != nil {
+			err = errors.Errorf("plan file resolver for unit %s failed: %w", runner.Unit.Path(), err)
+
+			return runner.endRunFailed(l, r, err)
+		}
+
+		planFile = resolved
+	}
+
+	// The command may not have produced a plan file, e.g. an apply without -out.
+	// A relative plan file lives in the unit's working directory, which is only known to the run itself.
+	if planFile == "" || (filepath.IsAbs(planFile) && !util.FileExists(planFile)) {
+		l.Debugf("Skipping JSON conversion for unit %s, plan file %s does not exist", runner.Unit.DisplayPath(), planFile)
+		return nil
+	}
+
+	jsonLogger, jsonOptions, err := jsonConversionOptions(l, opts)
+	if err != nil {
+		return err
+	}
+
+	stdout := bytes.Buffer{}
+	jsonOptions.ForwardTFStdout = true
+	jsonOptions.JSONLogFormat = false
+	jsonOptions.Writers.Writer = &stdout
+	jsonOptions.TerraformCommand = tf.CommandNameShow
+	jsonOptions.TerraformCliArgs = iacargs.New(tf.CommandNameShow, "-json", planFile)
+
+	// Use an ad-hoc report to avoid polluting the main report
+	adhocReport := report.NewReport()
+
+	runOpts := configbridge.NewRunOptions(jsonOptions)
+	if err := run.Run(ctx, jsonLogger, runOpts, adhocReport, cfg, credsGetter); err != nil {
+		// The show command fails when the run is cancelled, report the cancellation instead.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return runner.endRunCancelled(l, r, ctxErr, errors.New(JSONConversionCancelledError{UnitPath: runner.Unit.Path(), Err: ctxErr}))
+		}
+
+		return err
+	}
+
+	// The changes are parsed from the captured output, so the file is only written when it is wanted.
+	if !opts.KeepJSONInMemoryOnly {
+		if err := runner.writeOutputJSON(opts, stdout.Bytes()); err != nil {
 			return err
 		}
 	}
 
+	runner.recordChanges(l, r, stdout.Bytes())
+
 	return nil
 }
+
+// writeOutputJSON saves the JSON representation of the plan of the unit to its file under the JSON output folder.
+func (runner *UnitRunner) writeOutputJSON(opts *options.TerragruntOptions, planJSON []byte) error {
+	outputFile := runner.Unit.OutputJSONFile(opts.RootWorkingDir, opts.JSONOutputFolder)
+
+	if err := os.MkdirAll(filepath.Dir(outputFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	return writeOutputFile(outputFile, planJSON, runner.syncOutputs)
+}
+
+// jsonConversionOptions clones the options of the unit run for the show call converting its plan to JSON.
+//
+// Cloning with the config path resets the working directory to the directory of the configuration, so the
+// working and download directories of the unit run are restored: the show call must run against the same
+// .terraform directory as the run that produced the plan.
+func jsonConversionOptions(l log.Logger, opts *options.TerragruntOptions) (log.Logger, *options.TerragruntOptions, error) {
+	jsonLogger, jsonOptions, err := opts.CloneWithConfigPath(l, opts.TerragruntConfigPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jsonOptions.WorkingDir = opts.WorkingDir
+	jsonOptions.DownloadDir = opts.DownloadDir
+
+	return jsonLogger, jsonOptions, nil
+}
+
+// producesPlan reports whether the given command works with a plan file that can be converted to JSON.
+func producesPlan(command string) bool {
+	switch command {
+	case tf.CommandNamePlan, tf.CommandNameApply, tf.CommandNameShow:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputsRead returns true if a dependent of the unit reads its outputs through a dependency block, as opposed
+// to only ordering after it. Dependents whose configuration is not known are assumed to read them.
+func (runner *UnitRunner) outputsRead() bool {
+	unitPath := util.ResolvePath(runner.Unit.Path())
+
+	for _, dependent := range runner.Unit.Dependents() {
+		unit, ok := dependent.(*component.Unit)
+		if !ok {
+			return true
+		}
+
+		cfg := unit.Config()
+		if cfg == nil {
+			return true
+		}
+
+		for _, dep := range cfg.TerragruntDependencies {
+			if depPath, ok := dep.OutputsPath(unit.Path()); ok && util.ResolvePath(depPath) == unitPath {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// recordChanges stores the resource change counts of the JSON plan on the unit's report run.
+func (runner *UnitRunner) recordChanges(l log.Logger, r *report.Report, planJSON []byte) {
+	if r == nil {
+		return
+	}
+
+	counts, err := ParsePlanChanges(planJSON)
+	if err != nil {
+		l.Warnf("Failed to parse JSON plan for unit %s: %v", runner.Unit.DisplayPath(), err)
+		return
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	if _, err := r.EnsureRun(l, unitPath, report.WithChangeCounts(counts)); err != nil {
+		l.Errorf("Error recording changes for unit %s: %v", unitPath, err)
+	}
+}
+
+// recordTerraformVersion records the implementation and version of OpenTofu/Terraform running the unit in its
+// report run. They are detected in the working directory of the unit rather than taken from the options, which
+// hold the version of the whole run, as the version manager file of a unit may select another binary. The
+// detection is cached per binary and version manager files, and its failures are only logged.
+func (runner *UnitRunner) recordTerraformVersion(ctx context.Context, l log.Logger, r *report.Report, opts *options.TerragruntOptions) {
+	if r == nil {
+		return
+	}
+
+	_, ver, impl, err := run.PopulateTFVersion(ctx, l, opts.WorkingDir, opts.VersionManagerFileName, configbridge.TFRunOptsFromOpts(opts))
+	if err != nil {
+		l.Debugf("Failed to detect the OpenTofu/Terraform version of unit %s: %v", runner.Unit.DisplayPath(), err)
+		return
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	if _, err := r.EnsureRun(l, unitPath, report.WithTerraformVersion(fmt.Sprintf("%s %s", impl, ver))); err != nil {
+		l.Errorf("Error recording OpenTofu/Terraform version for unit %s: %v", unitPath, err)
+	}
+}
+
+// skipCached marks the unit as finished due to a result cache hit, without running it.
+func (runner *UnitRunner) skipCached(l log.Logger, r *report.Report) error {
+	l.Infof("Skipping unit %s: inputs unchanged since last successful run", runner.Unit.DisplayPath())
+
+	runner.Status = Finished
+
+	if r == nil {
+		return nil
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	if _, err := r.EnsureRun(l, unitPath); err != nil {
+		if runner.strictReporting {
+			return errors.New(ReportingError{UnitPath: unitPath, Err: err})
+		}
+
+		l.Errorf("Error ensuring run for unit %s: %v", unitPath, err)
+
+		return nil
+	}
+
+	if err := r.EndRun(
+		l,
+		unitPath,
+		report.WithResult(report.ResultSucceeded),
+		report.WithReason(report.ReasonCacheHit),
+	); err != nil {
+		if runner.strictReporting {
+			return errors.New(ReportingError{UnitPath: unitPath, Err: err})
+		}
+
+		l.Errorf("Error ending run for unit %s: %v", unitPath, err)
+	}
+
+	return nil
+}
+
+// skipAlreadyApplied marks the unit as finished because it is already applied, without running it.
+func (runner *UnitRunner) skipAlreadyApplied(l log.Logger, r *report.Report) error {
+	l.Infof("Skipping unit %s: already applied", runner.Unit.DisplayPath())
+
+	runner.Status = Finished
+
+	if r == nil {
+		return nil
+	}
+
+	unitPath := filepath.Clean(runner.Unit.Path())
+
+	if _, err := r.EnsureRun(l, unitPath); err != nil {
+		if runner.strictReporting {
+			return errors.New(ReportingError{UnitPath: unitPath, Err: err})
+		}
+
+		l.Errorf("Error ensuring run for unit %s: %v", unitPath, err)
+
+		return nil
+	}
+
+	if err := r.EndRun(
+		l,
+		unitPath,
+		report.WithResult(report.ResultExcluded),
+		report.WithReason(report.ReasonAssumedApplied),
+	); err != nil {
+		if runner.strictReporting {
+			return errors.New(ReportingError{UnitPath: unitPath, Err: err})
+		}
+
+		l.Errorf("Error ending run for unit %s: %v", unitPath, err)
+	}
+
+	return nil
+}
+
+// writeOutputFile writes the data to the given file, flushing it to stable storage before returning if sync is set.
+func writeOutputFile(path string, data []byte, sync bool) error {
+	if !sync {
+		return os.WriteFile(path, data, os.ModePerm)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,849 @@
+package common_test
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+	"github.com/gruntwork-io/terragrunt/internal/runner/run"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runcfg"
+	"github.com/gruntwork-io/terragrunt/internal/tfimpl"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestUnitRunner_OptionsTransformErrorFailsUnit(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.TerraformCommand = "apply"
+
+	r := report.NewReport()
+	unit := component.NewUnit(unitDir)
+
+	var received *options.TerragruntOptions
+
+	transform := func(u *component.Unit, unitOpts *options.TerragruntOptions) (*options.TerragruntOptions, error) {
+		received = unitOpts
+		unitOpts.TerraformCommand = "destroy"
+
+		return nil, assert.AnError
+	}
+
+	runner := common.NewUnitRunner(unit, common.WithOptionsTransform(transform))
+
+	err = runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, &runcfg.RunConfig{}, nil)
+	require.ErrorIs(t, err, assert.AnError)
+
+	// The transform works on a clone, so the shared options are untouched.
+	require.NotNil(t, received)
+	assert.NotSame(t, opts, received)
+	assert.Equal(t, "apply", opts.TerraformCommand)
+
+	run, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultFailed, run.Result)
+}
+
+func TestUnitRunner_CPUProfileWrittenPerUnit(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	unitDir := filepath.Join(rootDir, "app")
+	profileDir := filepath.Join(rootDir, "profiles")
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	runner := common.NewUnitRunner(unit, common.WithCPUProfileDir(profileDir))
+
+	// The run itself is expected to fail, as there is nothing to run; the profile is captured regardless.
+	_ = runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, &runcfg.RunConfig{}, nil)
+
+	assert.FileExists(t, filepath.Join(profileDir, "app", component.CPUProfileFileName))
+}
+
+//nolint:paralleltest // The CPU profiler is process-wide.
+func TestUnitRunner_CPUProfileInUseKeepsPreviousProfile(t *testing.T) {
+	rootDir := t.TempDir()
+	unitDir := filepath.Join(rootDir, "app")
+	profileDir := filepath.Join(rootDir, "profiles")
+	profileFile := filepath.Join(profileDir, "app", component.CPUProfileFileName)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(profileFile), 0o755))
+	require.NoError(t, os.WriteFile(profileFile, []byte("previous"), 0o644))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	// Another user of the process-wide CPU profiler, e.g. another run of the same unit.
+	require.NoError(t, pprof.StartCPUProfile(io.Discard))
+	defer pprof.StopCPUProfile()
+
+	runner := common.NewUnitRunner(unit, common.WithCPUProfileDir(profileDir))
+	_ = runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, &runcfg.RunConfig{}, nil)
+
+	contents, err := os.ReadFile(profileFile)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(contents))
+
+	entries, err := os.ReadDir(filepath.Dir(profileFile))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary profile should be removed")
+}
+
+func TestUnitRunner_JSONConversionUsesUnitDownloadDir(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+	downloadDir := filepath.Join(rootDir, "custom-download-dir")
+	jsonDir := filepath.Join(rootDir, "json")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	// The fake binary only finds the plan file in the directory the plan was written to.
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) touch tfplan.tfplan ;;
+  show) [ -f "$3" ] || { echo "plan file $3 not found in $(pwd)" >&2; exit 1; }; echo '{"resource_changes":[]}' ;;
+esac
+`), 0o755))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = tfPath
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+	opts.JSONOutputFolder = jsonDir
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	// The unit is run with a download dir that differs from the default one next to its configuration.
+	transform := func(u *component.Unit, unitOpts *options.TerragruntOptions) (*options.TerragruntOptions, error) {
+		unitOpts.DownloadDir = downloadDir
+		return unitOpts, nil
+	}
+
+	runner := common.NewUnitRunner(unit, common.WithOptionsTransform(transform))
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, cfg, nil))
+	assert.FileExists(t, filepath.Join(jsonDir, "app", "tfplan.json"))
+	assert.NoDirExists(t, filepath.Join(unitDir, ".terragrunt-cache"))
+}
+
+func TestUnitRunner_JSONOutputOnlyForDependedUnits(t *testing.T) {
+	t.Parallel()
+
+	skipOutputs := true
+
+	testCases := []struct {
+		// consumerCfg is the configuration of the unit depending on the planned unit, if any.
+		consumerCfg *config.TerragruntConfig
+		name        string
+		depended    bool
+		wantJSON    bool
+	}{
+		{name: "no dependents"},
+		{name: "dependent with unknown configuration", depended: true, wantJSON: true},
+		{
+			name:     "dependent reading outputs",
+			depended: true,
+			wantJSON: true,
+			consumerCfg: &config.TerragruntConfig{
+				TerragruntDependencies: config.Dependencies{
+					{Name: "app", ConfigPath: cty.StringVal("../app")},
+				},
+			},
+		},
+		{
+			name:     "ordering only dependent",
+			depended: true,
+			consumerCfg: &config.TerragruntConfig{
+				Dependencies: &config.ModuleDependencies{Paths: []string{"../app"}},
+			},
+		},
+		{
+			name:     "dependent skipping outputs",
+			depended: true,
+			consumerCfg: &config.TerragruntConfig{
+				TerragruntDependencies: config.Dependencies{
+					{Name: "app", ConfigPath: cty.StringVal("../app"), SkipOutputs: &skipOutputs},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		rootDir := helpers.TmpDirWOSymlinks(t)
+		unitDir := filepath.Join(rootDir, "app")
+		moduleDir := filepath.Join(rootDir, "module")
+		jsonDir := filepath.Join(rootDir, "json")
+
+		require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+		require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+		tfPath := filepath.Join(rootDir, "tofu")
+		require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) touch tfplan.tfplan ;;
+  show) echo '{"resource_changes":[]}' ;;
+esac
+`), 0o755))
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.TerraformCommand = "plan"
+		opts.TerraformCliArgs = iacargs.New("plan")
+		opts.JSONOutputFolder = jsonDir
+
+		unit := component.NewUnit(unitDir)
+		unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+		if tc.depended {
+			component.NewUnit(filepath.Join(rootDir, "consumer")).WithConfig(tc.consumerCfg).AddDependency(unit)
+		}
+
+		runner := common.NewUnitRunner(unit, common.WithJSONOutputOnlyForDependedUnits())
+		cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+		require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, cfg, nil))
+
+		if tc.wantJSON {
+			assert.FileExists(t, filepath.Join(jsonDir, "app", "tfplan.json"), tc.name)
+		} else {
+			assert.NoFileExists(t, filepath.Join(jsonDir, "app", "tfplan.json"), tc.name)
+		}
+	}
+}
+
+func TestUnitRunner_KeepJSONInMemoryOnly(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+	jsonDir := filepath.Join(rootDir, "json")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) touch tfplan.tfplan ;;
+  show) echo '{"resource_changes":[{"change":{"actions":["create"]}},{"change":{"actions":["update"]}}]}' ;;
+esac
+`), 0o755))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = tfPath
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+	opts.JSONOutputFolder = jsonDir
+	opts.KeepJSONInMemoryOnly = true
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	r := report.NewReport()
+	runner := common.NewUnitRunner(unit)
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, cfg, nil))
+	assert.NoFileExists(t, filepath.Join(jsonDir, "app", "tfplan.json"))
+	assert.Equal(t, map[string]report.ChangeCounts{unitDir: {Add: 1, Change: 1}}, r.ChangeSummary())
+}
+
+func TestUnitRunner_RecordsTerraformVersion(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	moduleDir := filepath.Join(rootDir, "module")
+
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	// The fake binary acts as a version manager shim, running the version of the .terraform-version file of
+	// its working directory.
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v$(cat .terraform-version)" ;;
+esac
+`), 0o755))
+
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+	ctx := run.WithRunVersionCache(t.Context())
+	r := report.NewReport()
+
+	units := map[string]string{"app": "1.9.0", "db": "1.8.5"}
+
+	for name, unitVersion := range units {
+		unitDir := filepath.Join(rootDir, name)
+
+		require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, ".terraform-version"), []byte(unitVersion), 0o644))
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.TerraformCommand = "plan"
+		opts.TerraformCliArgs = iacargs.New("plan")
+		// The version detected for the whole run, e.g. in the root working directory, does not apply to the unit.
+		opts.TerraformVersion = version.Must(version.NewVersion("1.5.7"))
+		opts.TofuImplementation = tfimpl.Terraform
+
+		unit := component.NewUnit(unitDir)
+		unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+		require.NoError(t, common.NewUnitRunner(unit).Run(ctx, thlogger.CreateLogger(), opts, r, cfg, nil))
+	}
+
+	for name, unitVersion := range units {
+		unitRun, err := r.GetRun(filepath.Join(rootDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, "tofu "+unitVersion, unitRun.TerraformVersion)
+	}
+}
+
+func TestUnitRunner_PlanFileResolver(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+	jsonDir := filepath.Join(rootDir, "json")
+	decrypted := filepath.Join(rootDir, "decrypted.tfplan")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	// The show call echoes the plan file it reads.
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) touch tfplan.tfplan ;;
+  show) echo "{\"resource_changes\":[],\"plan_file\":\"$3\"}" ;;
+esac
+`), 0o755))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = tfPath
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+	opts.JSONOutputFolder = jsonDir
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	resolver := func(ctx context.Context, u *component.Unit, unitOpts *options.TerragruntOptions) (string, error) {
+		if err := os.WriteFile(decrypted, nil, 0o644); err != nil {
+			return "", err
+		}
+
+		return decrypted, nil
+	}
+
+	runner := common.NewUnitRunner(unit, common.WithPlanFileResolver(resolver))
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, cfg, nil))
+
+	planJSON, err := os.ReadFile(filepath.Join(jsonDir, "app", "tfplan.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(planJSON), decrypted)
+
+	// A failing resolver fails the unit.
+	failing := func(ctx context.Context, u *component.Unit, unitOpts *options.TerragruntOptions) (string, error) {
+		return "", assert.AnError
+	}
+
+	runner = common.NewUnitRunner(unit, common.WithPlanFileResolver(failing))
+
+	err = runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, cfg, nil)
+	require.ErrorIs(t, err, assert.AnError)
+}
+
+func TestUnitRunner_StalePlanCheck(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	depDir := filepath.Join(rootDir, "dep")
+	moduleDir := filepath.Join(rootDir, "module")
+	outDir := filepath.Join(rootDir, "out")
+	depOutputsFile := filepath.Join(rootDir, "dep-outputs.json")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(depDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(depDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(depDir, "main.tf"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	// The fake binary prints the outputs of the dependency from a file, so they are fetched from its "state".
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  output) cat "`+depOutputsFile+`" ;;
+esac
+`), 0o755))
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+	unit.AddDependency(component.NewUnit(depDir))
+
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	// run runs the unit with the given command in a fresh run, where no dependency outputs were read yet.
+	run := func(command, depOutputs string, r *report.Report) error {
+		require.NoError(t, os.WriteFile(depOutputsFile, []byte(depOutputs), 0o644))
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.OutputFolder = outDir
+		opts.TerraformCommand = command
+		opts.TerraformCliArgs = iacargs.New(command)
+
+		runner := common.NewUnitRunner(unit, common.WithStalePlanCheck())
+
+		return runner.Run(config.WithConfigValues(t.Context()), thlogger.CreateLogger(), opts, r, cfg, nil)
+	}
+
+	require.NoError(t, run("plan", `{"vpc_id":{"value":"vpc-1"}}`, nil))
+	assert.FileExists(t, filepath.Join(outDir, "app", common.DependencyOutputsHashFile))
+
+	// The plan is applied as long as the outputs of the dependency are unchanged.
+	require.NoError(t, run("apply", `{"vpc_id":{"value":"vpc-1"}}`, nil))
+
+	r := report.NewReport()
+	err := run("apply", `{"vpc_id":{"value":"vpc-2"}}`, r)
+
+	var staleErr common.StalePlanError
+	require.ErrorAs(t, err, &staleErr)
+	assert.Equal(t, unitDir, staleErr.UnitPath)
+
+	reportRun, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultFailed, reportRun.Result)
+
+	// Outputs read while parsing the unit are taken from the output cache of the run.
+	ctx := config.WithConfigValues(t.Context())
+	config.CacheDependencyOutputJSON(ctx, filepath.Join(depDir, "terragrunt.hcl"), []byte(`{"vpc_id":{"value":"vpc-1"}}`))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = tfPath
+	opts.OutputFolder = outDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+
+	runner := common.NewUnitRunner(unit, common.WithStalePlanCheck())
+	require.NoError(t, runner.Run(ctx, thlogger.CreateLogger(), opts, nil, cfg, nil))
+}
+
+func TestUnitRunner_StalePlanCheckMissingOutputs(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+	outDir := filepath.Join(rootDir, "out")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+esac
+`), 0o755))
+
+	// The dependency has no configuration, so its outputs can't be read.
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+	unit.AddDependency(component.NewUnit(filepath.Join(rootDir, "missing")))
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = tfPath
+	opts.OutputFolder = outDir
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+
+	r := report.NewReport()
+	runner := common.NewUnitRunner(unit, common.WithStalePlanCheck())
+
+	err = runner.Run(config.WithConfigValues(t.Context()), thlogger.CreateLogger(), opts, r,
+		&runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}, nil)
+	require.ErrorContains(t, err, "failed to read the outputs of dependency")
+	assert.NoFileExists(t, filepath.Join(outDir, "app", common.DependencyOutputsHashFile))
+
+	reportRun, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultFailed, reportRun.Result)
+}
+
+func TestUnitRunner_MinFreeDisk(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	unitDir := filepath.Join(rootDir, "app")
+	moduleDir := filepath.Join(rootDir, "module")
+	ranFile := filepath.Join(rootDir, "ran")
+
+	require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+	require.NoError(t, os.MkdirAll(moduleDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), nil, 0o644))
+
+	tfPath := filepath.Join(rootDir, "tofu")
+	require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  apply) touch `+ranFile+` ;;
+esac
+`), 0o755))
+
+	unit := component.NewUnit(unitDir)
+	unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	cfg := &runcfg.RunConfig{Terraform: runcfg.TerraformConfig{Source: moduleDir}}
+
+	// run applies the unit, requiring the given number of free bytes.
+	run := func(minBytes uint64, r *report.Report) error {
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.TerraformCommand = "apply"
+		opts.TerraformCliArgs = iacargs.New("apply")
+
+		runner := common.NewUnitRunner(unit, common.WithMinFreeDisk(minBytes))
+
+		return runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, cfg, nil)
+	}
+
+	r := report.NewReport()
+	err := run(math.MaxUint64, r)
+
+	var diskErr common.InsufficientDiskError
+	require.ErrorAs(t, err, &diskErr)
+	assert.Equal(t, unitDir, diskErr.UnitPath)
+	assert.NoFileExists(t, ranFile)
+
+	reportRun, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultFailed, reportRun.Result)
+	require.NotNil(t, reportRun.Reason)
+	assert.Equal(t, report.ReasonInsufficientDisk, *reportRun.Reason)
+
+	require.NoError(t, run(1, nil))
+	assert.FileExists(t, ranFile)
+}
+
+func TestUnitRunner_AlreadyApplied(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.TerraformCommand = "apply"
+
+	unit := component.NewUnit(unitDir)
+
+	var checked *component.Unit
+
+	alreadyApplied := func(ctx context.Context, u *component.Unit) (bool, error) {
+		checked = u
+		return true, nil
+	}
+
+	r := report.NewReport()
+	runner := common.NewUnitRunner(unit, common.WithAlreadyApplied(alreadyApplied))
+
+	// The unit has no configuration, so it would fail if it ran.
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, &runcfg.RunConfig{}, nil))
+	assert.Same(t, unit, checked)
+	assert.Equal(t, common.Finished, runner.Status)
+
+	run, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultExcluded, run.Result)
+	require.NotNil(t, run.Reason)
+	assert.Equal(t, report.ReasonAssumedApplied, *run.Reason)
+}
+
+func TestUnitRunner_AlreadyAppliedErrorFailsUnit(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	alreadyApplied := func(ctx context.Context, u *component.Unit) (bool, error) {
+		return false, assert.AnError
+	}
+
+	r := report.NewReport()
+	runner := common.NewUnitRunner(component.NewUnit(unitDir), common.WithAlreadyApplied(alreadyApplied))
+
+	err = runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, &runcfg.RunConfig{}, nil)
+	require.ErrorIs(t, err, assert.AnError)
+
+	run, err := r.GetRun(unitDir)
+	require.NoError(t, err)
+	assert.Equal(t, report.ResultFailed, run.Result)
+}
+
+func TestUnitRunner_StrictReporting(t *testing.T) {
+	t.Parallel()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(t.TempDir(), "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	alreadyApplied := func(ctx context.Context, u *component.Unit) (bool, error) {
+		return true, nil
+	}
+
+	for _, strict := range []bool{false, true} {
+		runnerOpts := []common.UnitRunnerOption{common.WithAlreadyApplied(alreadyApplied)}
+		if strict {
+			runnerOpts = append(runnerOpts, common.WithStrictReporting())
+		}
+
+		// The report only accepts absolute paths, so the result of this unit cannot be recorded.
+		runner := common.NewUnitRunner(component.NewUnit("app"), runnerOpts...)
+
+		err := runner.Run(t.Context(), thlogger.CreateLogger(), opts, report.NewReport(), &runcfg.RunConfig{}, nil)
+
+		if !strict {
+			require.NoError(t, err)
+			continue
+		}
+
+		var reportingErr common.ReportingError
+		require.ErrorAs(t, err, &reportingErr)
+		require.ErrorIs(t, err, report.ErrPathMustBeAbsolute)
+		assert.Equal(t, "app", reportingErr.UnitPath)
+	}
+}
+
+func TestUnitRunner_StrictReportingOfFailure(t *testing.T) {
+	t.Parallel()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(t.TempDir(), "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	alreadyApplied := func(ctx context.Context, u *component.Unit) (bool, error) {
+		return false, assert.AnError
+	}
+
+	for _, strict := range []bool{false, true} {
+		runnerOpts := []common.UnitRunnerOption{common.WithAlreadyApplied(alreadyApplied)}
+		if strict {
+			runnerOpts = append(runnerOpts, common.WithStrictReporting())
+		}
+
+		// The report only accepts absolute paths, so the failure of this unit cannot be recorded.
+		runner := common.NewUnitRunner(component.NewUnit("app"), runnerOpts...)
+
+		err := runner.Run(t.Context(), thlogger.CreateLogger(), opts, report.NewReport(), &runcfg.RunConfig{}, nil)
+		require.ErrorIs(t, err, assert.AnError)
+
+		var reportingErr common.ReportingError
+
+		if !strict {
+			assert.NotErrorAs(t, err, &reportingErr)
+			continue
+		}
+
+		require.ErrorAs(t, err, &reportingErr)
+		require.ErrorIs(t, err, report.ErrPathMustBeAbsolute)
+		assert.Equal(t, "app", reportingErr.UnitPath)
+	}
+}
+
+func TestUnitRunner_UnitContext(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	type (
+		traceKey struct{}
+		authKey  struct{}
+	)
+
+	unitContext := func(ctx context.Context, u *component.Unit) context.Context {
+		return context.WithValue(ctx, traceKey{}, "trace-"+filepath.Base(u.Path()))
+	}
+
+	// Functions set by several options are all applied.
+	authContext := func(ctx context.Context, _ *component.Unit) context.Context {
+		return context.WithValue(ctx, authKey{}, "token")
+	}
+
+	var (
+		unitPath string
+		trace    any
+		auth     any
+	)
+
+	alreadyApplied := func(ctx context.Context, u *component.Unit) (bool, error) {
+		unitPath = common.UnitPathFromContext(ctx)
+		trace = ctx.Value(traceKey{})
+		auth = ctx.Value(authKey{})
+
+		return true, nil
+	}
+
+	runner := common.NewUnitRunner(
+		component.NewUnit(unitDir),
+		common.WithUnitContext(unitContext),
+		common.WithUnitContext(authContext),
+		common.WithAlreadyApplied(alreadyApplied),
+	)
+
+	require.NoError(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil, &runcfg.RunConfig{}, nil))
+	assert.Equal(t, unitDir, unitPath)
+	assert.Equal(t, "trace-"+filepath.Base(unitDir), trace)
+	assert.Equal(t, "token", auth)
+	assert.Empty(t, common.UnitPathFromContext(t.Context()))
+}
+
+func TestUnitRunner_OnSuccess(t *testing.T) {
+	t.Parallel()
+
+	for _, failUnit := range []bool{false, true} {
+		rootDir := helpers.TmpDirWOSymlinks(t)
+		unitDir := filepath.Join(rootDir, "app")
+
+		require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "main.tf"), nil, 0o644))
+
+		tfPath := filepath.Join(rootDir, "tofu")
+		require.NoError(t, os.WriteFile(tfPath, []byte(`#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+esac
+`), 0o755))
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(unitDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = tfPath
+		opts.TerraformCommand = "apply"
+		opts.TerraformCliArgs = iacargs.New("apply")
+
+		var command string
+
+		onSuccess := func(ctx context.Context, u *component.Unit, unitOpts *options.TerragruntOptions) error {
+			command = unitOpts.TerraformCommand
+			return assert.AnError
+		}
+
+		r := report.NewReport()
+		unit := component.NewUnit(unitDir)
+		unit.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+		runner := common.NewUnitRunner(unit, common.WithOnSuccess(onSuccess, failUnit))
+
+		err = runner.Run(t.Context(), thlogger.CreateLogger(), opts, r, &runcfg.RunConfig{}, nil)
+		assert.Equal(t, "apply", command)
+
+		run, runErr := r.GetRun(unitDir)
+		require.NoError(t, runErr)
+
+		if !failUnit {
+			require.NoError(t, err)
+			assert.Equal(t, report.ResultSucceeded, run.Result)
+
+			continue
+		}
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, report.ResultFailed, run.Result)
+	}
+}
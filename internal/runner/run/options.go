@@ -20,6 +20,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/internal/remotestate"
 	"github.com/gruntwork-io/terragrunt/internal/remotestate/backend"
 	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/retry"
 	"github.com/gruntwork-io/terragrunt/internal/shell"
 	"github.com/gruntwork-io/terragrunt/internal/strict"
 	"github.com/gruntwork-io/terragrunt/internal/telemetry"
@@ -43,6 +44,7 @@ type Options struct {
 	EngineConfig                 *engine.EngineConfig
 	EngineOptions                *engine.EngineOptions
 	Errors                       *errorconfig.Config
+	RetryBudget                  *retry.Budget
 	FeatureFlags                 *xsync.MapOf[string, string]
 	Telemetry                    *telemetry.Options
 	SourceMap                    map[string]string
@@ -305,6 +307,16 @@ func (o *Options) RunWithErrorHandling(
 				return err
 			}
 
+			if !o.RetryBudget.Take() {
+				l.Warnf(
+					"Encountered retryable error: %s\nNot retrying, retry budget exhausted (%d retries across the run)",
+					action.RetryBlockName,
+					o.RetryBudget.Max(),
+				)
+
+				return err
+			}
+
 			l.Warnf(
 				"Encountered retryable error: %s\nAttempt %d of %d. Waiting %d second(s) before retrying...",
 				action.RetryBlockName,
@@ -18,6 +18,7 @@ import (
 
 	"github.com/gruntwork-io/terragrunt/internal/codegen"
 	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/experiment"
 	"github.com/gruntwork-io/terragrunt/internal/iacargs"
 	"github.com/gruntwork-io/terragrunt/internal/iam"
 	"github.com/gruntwork-io/terragrunt/internal/remotestate"
@@ -76,6 +77,31 @@ var ModuleRegex = regexp.MustCompile(`module[[:blank:]]+".+"`)
 // concurrent source downloads from racing on the same cache directory (see DownloadTerraformSource).
 var sourceChangeLocks = sync.Map{}
 
+// workingDirLocks is a map of per-working-directory mutexes that serializes the runs of units resolving to the same
+// working directory, which would otherwise corrupt each other's .terraform directory.
+// This is a safety net for misconfigurations, dependency ordering already keeps well-configured units apart.
+var workingDirLocks = sync.Map{}
+
+// lockWorkingDir acquires the lock of the canonical form of the given working directory and returns the function
+// releasing it. A warning is logged when another unit holds the lock, since it usually signals a config bug.
+func lockWorkingDir(l log.Logger, workingDir string) func() {
+	key := filepath.Clean(workingDir)
+	if resolved, err := filepath.EvalSymlinks(key); err == nil {
+		key = resolved
+	}
+
+	rawLock, _ := workingDirLocks.LoadOrStore(key, &sync.Mutex{})
+	lock := rawLock.(*sync.Mutex)
+
+	if !lock.TryLock() {
+		l.Warnf("Another unit is running in working directory %s, waiting for it to finish. "+
+			"Units sharing a working directory usually have a misconfigured source or download_dir.", key)
+		lock.Lock()
+	}
+
+	return lock.Unlock
+}
+
 // Run downloads terraform source if necessary, then runs terraform with the given options and CLI args.
 // This will forward all the args and extra_arguments directly to Terraform.
 func Run(
@@ -146,6 +172,16 @@ func Run(
 		return err
 	}
 
+	// Lock the working directory before downloading the source into it, since the download replaces the files of
+	// a unit running in the same working directory.
+	source, err := tf.NewSource(l, sourceURL, opts.DownloadDir, opts.WorkingDir, opts.Experiments.Evaluate(experiment.Symlinks))
+	if err != nil {
+		return err
+	}
+
+	unlockWorkingDir := lockWorkingDir(l, source.WorkingDir)
+	defer unlockWorkingDir()
+
 	// Always download/copy source to cache directory for consistency.
 	// When no source is specified, sourceURL will be "." (current directory).
 	err = telemetry.TelemeterFromContext(ctx).Collect(ctx, "download_terraform_source", map[string]any{
@@ -32,12 +32,18 @@ var TerraformVersionRegex = regexp.MustCompile(`^(\S+)\s(v?\d+\.\d+\.\d+)`)
 const versionParts = 3
 
 // PopulateTFVersion discovers the currently installed version of OpenTofu/Terraform.
-// It uses a cache keyed by workingDir and versionFiles to avoid repeated invocations.
+// It uses a cache keyed by binary path, workingDir and versionFiles to avoid repeated invocations.
 // Returns the discovered version and implementation type; the caller is responsible
 // for storing them on *options.TerragruntOptions.
 func PopulateTFVersion(ctx context.Context, l log.Logger, workingDir string, versionFiles []string, tfOpts *tf.TFOptions) (log.Logger, *version.Version, tfimpl.Type, error) {
 	versionCache := GetRunVersionCache(ctx)
 	cacheKey := computeVersionFilesCacheKey(workingDir, versionFiles)
+
+	// Units may run different binaries, e.g. through terraform_binary, so each binary is detected separately.
+	if tfOpts != nil && tfOpts.ShellOptions != nil && tfOpts.ShellOptions.TFPath != "" {
+		cacheKey = tfOpts.ShellOptions.TFPath + "|" + cacheKey
+	}
+
 	l.Debugf("using cache key for version files: %s", cacheKey)
 
 	if cachedOutput, found := versionCache.Get(ctx, cacheKey); found {
@@ -0,0 +1,96 @@
+package run
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/run/creds"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runcfg"
+	"github.com/gruntwork-io/terragrunt/internal/tf"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	workingDir := t.TempDir()
+
+	buf := new(bytes.Buffer)
+	l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+	unlock := lockWorkingDir(l, workingDir)
+
+	// A different directory is not serialized with the locked one.
+	lockWorkingDir(l, filepath.Join(workingDir, "other"))()
+	assert.Empty(t, buf.String())
+
+	acquired := make(chan struct{})
+
+	go func() {
+		// The same directory, spelled differently, waits for the first lock to be released.
+		defer lockWorkingDir(l, workingDir+string(filepath.Separator)+".")()
+
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		require.FailNow(t, "working directory lock acquired while held by another unit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	<-acquired
+
+	assert.Contains(t, buf.String(), "Another unit is running in working directory")
+}
+
+func TestRunLocksWorkingDirBeforeDownload(t *testing.T) {
+	t.Parallel()
+
+	unitDir := t.TempDir()
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.tf"), nil, 0o644))
+
+	buf := new(bytes.Buffer)
+	l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+	opts := &Options{
+		TerragruntConfigPath:         filepath.Join(unitDir, "terragrunt.hcl"),
+		OriginalTerragruntConfigPath: filepath.Join(unitDir, "terragrunt.hcl"),
+		WorkingDir:                   unitDir,
+		DownloadDir:                  filepath.Join(unitDir, ".terragrunt-cache"),
+		Source:                       sourceDir,
+		TerraformCliArgs:             iacargs.New("plan"),
+	}
+
+	source, err := tf.NewSource(l, sourceDir, opts.DownloadDir, unitDir, false)
+	require.NoError(t, err)
+
+	unlock := lockWorkingDir(l, source.WorkingDir)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = Run(t.Context(), l, opts, report.NewReport(), &runcfg.RunConfig{}, creds.NewGetter())
+	}()
+
+	// The source is not downloaded into the working directory while another unit runs in it.
+	time.Sleep(50 * time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(source.WorkingDir, "main.tf"))
+
+	unlock()
+	<-done
+
+	assert.FileExists(t, filepath.Join(source.WorkingDir, "main.tf"))
+	assert.Contains(t, buf.String(), "Another unit is running in working directory")
+}
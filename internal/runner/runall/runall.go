@@ -17,6 +17,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/internal/iacargs"
 	"github.com/gruntwork-io/terragrunt/internal/os/stdout"
 	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/retry"
 	"github.com/gruntwork-io/terragrunt/internal/shell"
 	"github.com/gruntwork-io/terragrunt/internal/telemetry"
 	"github.com/gruntwork-io/terragrunt/internal/tf"
@@ -143,6 +144,16 @@ func Run(ctx context.Context, l log.Logger, opts *options.TerragruntOptions) err
 		runnerOpts = append(runnerOpts, common.WithWorktrees(wts))
 	}
 
+	if opts.MaxTotalChanges > 0 && opts.TerraformCommand == tf.CommandNameApply && !opts.TerraformCliArgs.HasPlanFile() {
+		if err := checkChangeLimit(ctx, l, opts, runnerOpts); err != nil {
+			return err
+		}
+	}
+
+	if opts.MaxTotalRetries > 0 && opts.RetryBudget == nil {
+		opts.RetryBudget = retry.NewBudget(opts.MaxTotalRetries)
+	}
+
 	rnr, err := runner.NewStackRunner(ctx, l, opts, runnerOpts...)
 	if err != nil {
 		return err
@@ -213,6 +224,42 @@ func RunAllOnStack(ctx context.Context, l log.Logger, opts *options.TerragruntOp
 	return runErr
 }
 
+// maxChangeContributors is the number of units listed when the change limit is exceeded.
+const maxChangeContributors = 10
+
+// checkChangeLimit plans every unit of the stack and returns an error if the total number of planned
+// resource changes exceeds opts.MaxTotalChanges, so that nothing is applied.
+func checkChangeLimit(ctx context.Context, l log.Logger, opts *options.TerragruntOptions, runnerOpts []common.Option) error {
+	planDir, err := os.MkdirTemp("", "terragrunt-max-total-changes-")
+	if err != nil {
+		return errors.New(err)
+	}
+
+	defer os.RemoveAll(planDir) //nolint:errcheck
+
+	planOpts := opts.Clone()
+	planOpts.TerraformCommand = tf.CommandNamePlan
+	planOpts.TerraformCliArgs = opts.TerraformCliArgs.Clone().SetCommand(tf.CommandNamePlan).RemoveFlag("-auto-approve")
+	planOpts.OutputFolder = filepath.Join(planDir, "plan")
+	// Only the change counts are needed, so the JSON plans are not written to disk.
+	planOpts.KeepJSONInMemoryOnly = true
+
+	l.Infof("Planning all units to check the limit of %d resource changes", opts.MaxTotalChanges)
+
+	rnr, err := runner.NewStackRunner(ctx, l, planOpts, runnerOpts...)
+	if err != nil {
+		return err
+	}
+
+	planReport := report.NewReport().WithWorkingDir(opts.WorkingDir)
+
+	if err := rnr.Run(ctx, l, planOpts, planReport); err != nil {
+		return errors.Errorf("failed to plan units before checking the change limit: %w", err)
+	}
+
+	return planReport.CheckChangeLimit(opts.MaxTotalChanges, maxChangeContributors)
+}
+
 // shouldSkipSummary determines if summary output should be skipped for programmatic interactions.
 // Summary is skipped when:
 // - The command is 'output' (typically used for programmatic consumption)
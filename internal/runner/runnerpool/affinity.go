@@ -0,0 +1,81 @@
+package runnerpool
+
+import (
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// AffinityGroupFunc maps the path of a unit to the name of its affinity group.
+// Units mapped to an empty name do not belong to any group.
+type AffinityGroupFunc func(path string) string
+
+// WithAffinityGroups prefers starting the units of an affinity group at the same time, e.g. to keep paired
+// blue/green units in lockstep during a rollout.
+//
+// Units are grouped with the given function. A ready unit is held back while another unit of its group has
+// not started and is not ready yet, as long as other units are running or starting: once they finish, the
+// other unit of the group may become ready too. The ready units of a group are then started one after the
+// other, before the ready units that follow them.
+//
+// This is a soft preference, not a barrier: when nothing else can run, the ready units of a group start
+// without waiting for the rest of their group, so affinity never stalls a run.
+func WithAffinityGroups(affinityOf AffinityGroupFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.affinityOf = affinityOf
+	}
+}
+
+// withAffinity holds back the ready entries whose affinity group is not ready yet, when something else is
+// running or starting, and orders the ready entries of each group next to each other.
+func (dr *Controller) withAffinity(l log.Logger, entries []*queue.Entry) []*queue.Entry {
+	ready := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		ready[e.Component.Path()] = true
+	}
+
+	// A group is incomplete when one of its units has not started and is not ready to.
+	incomplete := map[string]bool{}
+
+	for _, stuck := range dr.q.StuckEntries() {
+		if group := dr.affinityOf(stuck.Path); group != "" && !ready[stuck.Path] {
+			incomplete[group] = true
+		}
+	}
+
+	// Holding back is only worth it when something else runs meanwhile, and may make the group ready.
+	progressing := dr.q.Progress().Running > 0 || slices.ContainsFunc(entries, func(e *queue.Entry) bool {
+		return !incomplete[dr.affinityOf(e.Component.Path())]
+	})
+	if !progressing {
+		clear(incomplete)
+	}
+
+	byGroup := map[string][]*queue.Entry{}
+
+	for _, e := range entries {
+		if group := dr.affinityOf(e.Component.Path()); group != "" {
+			byGroup[group] = append(byGroup[group], e)
+		}
+	}
+
+	out := make([]*queue.Entry, 0, len(entries))
+	emitted := map[string]bool{}
+
+	for _, e := range entries {
+		group := dr.affinityOf(e.Component.Path())
+
+		switch {
+		case group == "":
+			out = append(out, e)
+		case incomplete[group]:
+			l.Debugf("Runner Pool Controller: holding %s until the rest of affinity group %s is ready", e.Component.DisplayPath(), group)
+		case !emitted[group]:
+			emitted[group] = true
+			out = append(out, byGroup[group]...)
+		}
+	}
+
+	return out
+}
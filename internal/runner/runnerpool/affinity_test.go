@@ -0,0 +1,95 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_AffinityGroups(t *testing.T) {
+	t.Parallel()
+
+	// A -> B, with B and X in the same affinity group.
+	units := buildComponentUnits(
+		[]string{"A", "B", "X"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		events = append(events, "start "+u.Path())
+		mu.Unlock()
+
+		if u.Path() == "A" {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		mu.Lock()
+		events = append(events, "finish "+u.Path())
+		mu.Unlock()
+
+		return nil
+	}
+
+	affinityOf := func(path string) string {
+		if path == "B" || path == "X" {
+			return "pair"
+		}
+
+		return ""
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithAffinityGroups(affinityOf),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+
+	// X is ready from the start, but waits for B to become ready once A finishes.
+	require.Len(t, events, 6)
+	assert.Equal(t, []string{"start A", "finish A"}, events[:2])
+	assert.ElementsMatch(t, []string{"start B", "start X", "finish B", "finish X"}, events[2:])
+}
+
+func TestController_AffinityGroupsDoNotStallRun(t *testing.T) {
+	t.Parallel()
+
+	// B and X are in the same affinity group, but B waits on X.
+	units := buildComponentUnits(
+		[]string{"B", "X"},
+		map[string][]string{
+			"B": {"X"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithAffinityGroups(func(string) string { return "pair" }),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+}
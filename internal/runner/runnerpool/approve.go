@@ -0,0 +1,143 @@
+package runnerpool
+
+import (
+	"context"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/shell"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// ApproveUnitFunc decides whether a unit that is ready to run should be run.
+// Returning false skips the unit; returning an error fails it.
+type ApproveUnitFunc func(ctx context.Context, unit *component.Unit) (bool, error)
+
+// WithApproveUnit sets a hook that is consulted before each unit is started.
+//
+// The hook is invoked from the scheduling loop, one unit at a time, so it is safe to prompt from it.
+// When the hook declines a unit, the unit is skipped. If propagateSkip is true, the units waiting on it
+// are skipped as well; otherwise they run as if the skipped unit had succeeded.
+func WithApproveUnit(fn ApproveUnitFunc, propagateSkip bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.approve = fn
+		dr.propagateSkip = propagateSkip
+	}
+}
+
+// ApproveWaveFunc decides whether the units of a dependency wave should be run.
+// Returning false skips the whole wave; returning an error aborts the run.
+type ApproveWaveFunc func(index int, units []*component.Unit) (bool, error)
+
+// WithApproveWave sets a hook that is consulted once per dependency wave, before the wave starts, with the
+// units of the wave that are still due to run. When the hook declines a wave, its units are skipped along
+// with every unit that waits on them. Waves left with no unit to run are not submitted for approval.
+//
+// Setting this hook switches the controller to staged execution.
+func WithApproveWave(fn ApproveWaveFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.approveWave = fn
+		dr.staged = true
+	}
+}
+
+// PromptApproveUnit returns an ApproveUnitFunc that asks the user to confirm each unit before it runs.
+// When opts.NonInteractive is set, every unit is approved without prompting.
+func PromptApproveUnit(l log.Logger, opts *options.TerragruntOptions) ApproveUnitFunc {
+	return func(ctx context.Context, unit *component.Unit) (bool, error) {
+		if opts.NonInteractive {
+			return true, nil
+		}
+
+		prompt := "Do you want to run '" + opts.TerraformCommand + "' in unit " + unit.DisplayPath() + "?"
+
+		return shell.PromptUserForYesNo(ctx, l, prompt, opts.NonInteractive, opts.Writers.ErrWriter)
+	}
+}
+
+// approveCurrentWave consults the wave approval hook for the current wave, and skips the units
+// of the wave and their dependents when it is declined.
+func (dr *Controller) approveCurrentWave(l log.Logger) error {
+	if dr.approveWave == nil {
+		return nil
+	}
+
+	var pending []*component.Unit
+
+	for _, e := range dr.waves[dr.currentWave] {
+		if dr.q.AllFinished(queue.Entries{e}) {
+			continue
+		}
+
+		if unit := dr.unit(e.Component.Path()); unit != nil {
+			pending = append(pending, unit)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	approved, err := dr.approveWave(dr.currentWave, pending)
+	if err != nil {
+		return errors.Errorf("approve wave %d: %w", dr.currentWave, err)
+	}
+
+	if approved {
+		return nil
+	}
+
+	l.Infof("Skipping wave %d: not approved", dr.currentWave)
+
+	for _, e := range dr.waves[dr.currentWave] {
+		dr.q.SkipEntry(e, true)
+	}
+
+	return nil
+}
+
+// approveEntry consults the approval hook for the given entry and reports whether it may be started.
+// Declined entries are skipped and entries whose approval failed are marked as failed.
+func (dr *Controller) approveEntry(
+	ctx context.Context,
+	l log.Logger,
+	e *queue.Entry,
+	results *xsync.MapOf[string, error],
+) bool {
+	if dr.approve == nil {
+		return true
+	}
+
+	unit := dr.unit(e.Component.Path())
+	if unit == nil {
+		// Let the regular execution path report the missing unit.
+		return true
+	}
+
+	approved, err := dr.approve(ctx, unit)
+	if err != nil {
+		l.Debugf("Runner Pool Controller: approval of %s failed: %v", e.Component.DisplayPath(), err)
+		dr.storeResult(results, e.Component.Path(), err)
+		dr.q.FailEntry(e)
+
+		return false
+	}
+
+	if approved {
+		return true
+	}
+
+	l.Infof("Skipping unit %s: not approved", unit.DisplayPath())
+	dr.q.SkipEntry(e, dr.propagateSkip)
+
+	// Skipping may have unblocked other entries, make sure the scheduling loop picks them up.
+	select {
+	case dr.readyCh <- struct{}{}:
+	default:
+	}
+
+	return false
+}
@@ -0,0 +1,250 @@
+package runnerpool_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_ApproveUnit(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		expectedRan   []string
+		propagateSkip bool
+	}{
+		{
+			name:          "skip propagates to dependents",
+			propagateSkip: true,
+			expectedRan:   []string{"A", "D"},
+		},
+		{
+			name:          "dependents run after skip",
+			propagateSkip: false,
+			expectedRan:   []string{"A", "C", "D"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// A -> B -> C, D independent
+			units := buildComponentUnits(
+				[]string{"A", "B", "C", "D"},
+				map[string][]string{
+					"B": {"A"},
+					"C": {"B"},
+				},
+			)
+
+			var (
+				mu  sync.Mutex
+				ran []string
+			)
+
+			runner := func(ctx context.Context, u *component.Unit) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				ran = append(ran, u.Path())
+
+				return nil
+			}
+
+			approve := func(ctx context.Context, u *component.Unit) (bool, error) {
+				return u.Path() != "B", nil
+			}
+
+			q := buildQueue(t, units)
+
+			controller := runnerpool.NewController(
+				q,
+				units,
+				runnerpool.WithRunner(runner),
+				runnerpool.WithMaxConcurrency(2),
+				runnerpool.WithApproveUnit(approve, tc.propagateSkip),
+			)
+
+			require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+			assert.ElementsMatch(t, tc.expectedRan, ran)
+			assert.Equal(t, queue.StatusSkipped, q.EntryByPath("B").Status)
+		})
+	}
+}
+
+func TestController_ApproveUnitErrorFailsUnit(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	approveErr := assert.AnError
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			t.Errorf("unit %s should not run", u.Path())
+			return nil
+		}),
+		runnerpool.WithApproveUnit(func(ctx context.Context, u *component.Unit) (bool, error) {
+			return false, approveErr
+		}, true),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.ErrorIs(t, err, approveErr)
+}
+
+func TestController_ApproveWave(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> C, D -> E
+	// Waves: [A D], [B E], [C]
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D", "E"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+			"E": {"D"},
+		},
+	)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ran = append(ran, u.Path())
+
+		return nil
+	}
+
+	var asked [][]string
+
+	approve := func(index int, units []*component.Unit) (bool, error) {
+		asked = append(asked, unitPaths(units))
+
+		return index == 0, nil
+	}
+
+	q := buildQueue(t, units)
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+		runnerpool.WithApproveWave(approve),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.ElementsMatch(t, []string{"A", "D"}, ran)
+
+	// The last wave only holds units skipped along with the declined wave, so it is not submitted.
+	assert.Equal(t, [][]string{{"A", "D"}, {"B", "E"}}, asked)
+
+	for _, path := range []string{"B", "C", "E"} {
+		assert.Equal(t, queue.StatusSkipped, q.EntryByPath(path).Status, path)
+	}
+}
+
+func TestController_ApproveWaveError(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		t.Errorf("unit %s should not run", u.Path())
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithApproveWave(func(int, []*component.Unit) (bool, error) {
+			return false, assert.AnError
+		}),
+	)
+
+	require.ErrorIs(t, controller.Run(t.Context(), logger.CreateLogger()), assert.AnError)
+}
+
+//nolint:paralleltest // Replaces os.Stdin to answer the prompt.
+func TestRunner_ConfirmEachUnit(t *testing.T) {
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	// The units have no configuration, so they would fail if they were run.
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(vpc)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	var prompts bytes.Buffer
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.ConfirmEachUnit = true
+	opts.NonInteractive = false
+	opts.Writers.ErrWriter = &prompts
+
+	stdin, answers, err := os.Pipe()
+	require.NoError(t, err)
+
+	_, err = answers.WriteString("n\n")
+	require.NoError(t, err)
+	require.NoError(t, answers.Close())
+
+	origStdin := os.Stdin
+	os.Stdin = stdin
+
+	t.Cleanup(func() {
+		os.Stdin = origStdin
+		stdin.Close()
+	})
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{vpc, app})
+	require.NoError(t, err)
+
+	r := report.NewReport()
+
+	// Declining vpc skips it along with app, without prompting for app.
+	require.NoError(t, stack.Run(t.Context(), l, opts, r))
+	assert.Equal(t, 1, strings.Count(prompts.String(), "Do you want to run 'apply' in unit"))
+
+	for _, unit := range []*component.Unit{vpc, app} {
+		run, err := r.GetRun(unit.Path())
+		require.NoError(t, err)
+		assert.Equal(t, report.ResultExcluded, run.Result)
+		require.NotNil(t, run.Reason)
+		assert.Equal(t, report.ReasonUserSkipped, *run.Reason)
+	}
+}
@@ -0,0 +1,65 @@
+package runnerpool
+
+import (
+	"sync"
+)
+
+// OutputBudget bounds the total number of bytes held in UnitWriter buffers across all units of a run.
+//
+// When the budget is exceeded, the largest buffer is flushed to its underlying writer, even if it holds
+// a partial line. This trades per-unit output grouping for bounded memory usage on large stacks.
+type OutputBudget struct {
+	writers map[*UnitWriter]int
+	limit   int
+	used    int
+	mu      sync.Mutex
+}
+
+// NewOutputBudget returns a new OutputBudget that allows at most limit bytes to be buffered.
+// A limit of zero or less disables the budget.
+func NewOutputBudget(limit int) *OutputBudget {
+	return &OutputBudget{
+		writers: make(map[*UnitWriter]int),
+		limit:   limit,
+	}
+}
+
+// Used returns the number of bytes currently buffered across all writers sharing the budget.
+func (budget *OutputBudget) Used() int {
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	return budget.used
+}
+
+// update records the current buffered size of a writer and returns the writer holding the largest
+// buffer if the budget is exceeded, or nil otherwise.
+func (budget *OutputBudget) update(writer *UnitWriter, size int) *UnitWriter {
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	budget.used += size - budget.writers[writer]
+
+	if size == 0 {
+		delete(budget.writers, writer)
+	} else {
+		budget.writers[writer] = size
+	}
+
+	if budget.limit <= 0 || budget.used <= budget.limit {
+		return nil
+	}
+
+	var (
+		largest     *UnitWriter
+		largestSize int
+	)
+
+	for w, s := range budget.writers {
+		if s > largestSize {
+			largest, largestSize = w, s
+		}
+	}
+
+	return largest
+}
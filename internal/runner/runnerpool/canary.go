@@ -0,0 +1,140 @@
+package runnerpool
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// CanaryFunc selects the canary among the units of the dependency wave at the given index that have not
+// finished yet. Returning nil runs the whole wave at once, while returning a unit that is not one of the given
+// units falls back to the first unit by path.
+type CanaryFunc func(index int, units []*component.Unit) *component.Unit
+
+// WithCanaries makes each dependency wave run a single canary unit alone first, and only run the rest of the
+// wave once the canary succeeded, so that systemic issues, such as a bad provider version, are caught before
+// every unit of the wave runs into them. When a canary fails, the run is aborted with a CanaryFailedError,
+// and the units that have not started, including the rest of the wave, are marked as early exit.
+//
+// A nil function selects the first unit of each wave by path. Waves of a single unit run as usual.
+//
+// Setting canaries switches the controller to staged execution.
+func WithCanaries(canaryOf CanaryFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.canaries = true
+		dr.canaryOf = canaryOf
+		dr.staged = true
+	}
+}
+
+// firstByPath returns the unit with the lowest path, or nil if there is none.
+func firstByPath(_ int, units []*component.Unit) *component.Unit {
+	var first *component.Unit
+
+	for _, unit := range units {
+		if first == nil || unit.Path() < first.Path() {
+			first = unit
+		}
+	}
+
+	return first
+}
+
+// pickCanary selects the canary of the wave at the given index, when canaries are enabled and more than
+// one of its units is left to run.
+func (dr *Controller) pickCanary(l log.Logger, index int) {
+	if !dr.canaries {
+		return
+	}
+
+	var candidates []*component.Unit
+
+	for _, e := range dr.waves[index] {
+		if e.Status == queue.StatusSucceeded || e.Status == queue.StatusFailed ||
+			e.Status == queue.StatusEarlyExit || e.Status == queue.StatusSkipped {
+			continue
+		}
+
+		if unit := dr.unit(e.Component.Path()); unit != nil {
+			candidates = append(candidates, unit)
+		}
+	}
+
+	if len(candidates) < 2 { //nolint:mnd
+		return
+	}
+
+	canaryOf := dr.canaryOf
+	if canaryOf == nil {
+		canaryOf = firstByPath
+	}
+
+	canary := canaryOf(index, candidates)
+	if canary == nil {
+		return
+	}
+
+	if !slices.ContainsFunc(candidates, func(unit *component.Unit) bool { return unit.Path() == canary.Path() }) {
+		l.Warnf("Canary unit %s is not left to run in wave %d, running the first unit of the wave by path as the canary instead",
+			canary.DisplayPath(), index)
+
+		canary = firstByPath(index, candidates)
+	}
+
+	if dr.pendingCanaries == nil {
+		dr.pendingCanaries = make(map[int]string)
+	}
+
+	dr.pendingCanaries[index] = canary.Path()
+
+	l.Infof("Running canary unit %s of wave %d before the other %d units of the wave",
+		canary.DisplayPath(), index, len(candidates)-1)
+}
+
+// checkCanaries releases the waves whose canary succeeded or was skipped, and returns a CanaryFailedError
+// for the first wave whose canary failed or exited early.
+func (dr *Controller) checkCanaries(l log.Logger) error {
+	for _, index := range slices.Sorted(maps.Keys(dr.pendingCanaries)) {
+		path := dr.pendingCanaries[index]
+
+		entry := dr.q.EntryByPath(path)
+		if entry == nil {
+			delete(dr.pendingCanaries, index)
+			continue
+		}
+
+		switch entry.Status { //nolint:exhaustive
+		case queue.StatusFailed, queue.StatusEarlyExit:
+			delete(dr.pendingCanaries, index)
+
+			return errors.New(CanaryFailedError{UnitPath: path, Wave: index})
+		case queue.StatusSucceeded, queue.StatusSkipped:
+			l.Debugf("Runner Pool Controller: canary %s of wave %d finished, running the rest of the wave", path, index)
+			delete(dr.pendingCanaries, index)
+		}
+	}
+
+	return nil
+}
+
+// withoutCanaryWaits filters ready entries down to those not waiting on the canary of their wave.
+func (dr *Controller) withoutCanaryWaits(entries []*queue.Entry) []*queue.Entry {
+	if len(dr.pendingCanaries) == 0 {
+		return entries
+	}
+
+	out := make([]*queue.Entry, 0, len(entries))
+
+	for _, e := range entries {
+		canary, ok := dr.pendingCanaries[dr.waveIndex[e.Component.Path()]]
+		if !ok || canary == e.Component.Path() {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,195 @@
+package runnerpool_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_Canaries(t *testing.T) {
+	t.Parallel()
+
+	// Wave 0 holds A, B and C, wave 1 holds D and E.
+	units := buildComponentUnits(
+		[]string{"C", "B", "A", "D", "E"},
+		map[string][]string{
+			"D": {"A"},
+			"E": {"B", "C"},
+		},
+	)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		events = append(events, "start "+u.Path())
+		mu.Unlock()
+
+		mu.Lock()
+		events = append(events, "end "+u.Path())
+		mu.Unlock()
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(5),
+		runnerpool.WithCanaries(nil),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	// The first unit of each wave by path runs alone before the rest of its wave.
+	require.Len(t, events, 10)
+	assert.Equal(t, []string{"start A", "end A"}, events[:2])
+	assert.ElementsMatch(t, []string{"start B", "end B", "start C", "end C"}, events[2:6])
+	assert.Equal(t, []string{"start D", "end D"}, events[6:8])
+	assert.Equal(t, []string{"start E", "end E"}, events[8:])
+}
+
+func TestController_CanaryFailure(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C"}, nil)
+	q := buildQueue(t, units)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			mu.Lock()
+			ran = append(ran, u.Path())
+			mu.Unlock()
+
+			if u.Path() == "C" {
+				return errors.New("bad provider version")
+			}
+
+			return nil
+		}),
+		runnerpool.WithMaxConcurrency(3),
+		runnerpool.WithCanaries(func(index int, units []*component.Unit) *component.Unit {
+			for _, unit := range units {
+				if unit.Path() == "C" {
+					return unit
+				}
+			}
+
+			return nil
+		}),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var canaryErr runnerpool.CanaryFailedError
+	require.ErrorAs(t, err, &canaryErr)
+	assert.Equal(t, "C", canaryErr.UnitPath)
+	assert.Equal(t, 0, canaryErr.Wave)
+
+	assert.Equal(t, []string{"C"}, ran)
+	assert.Equal(t, queue.StatusEarlyExit, q.EntryByPath("A").Status)
+	assert.Equal(t, queue.StatusEarlyExit, q.EntryByPath("B").Status)
+}
+
+func TestController_CanaryOutsideOfWave(t *testing.T) {
+	t.Parallel()
+
+	// Wave 0 holds A and B, wave 1 holds C.
+	units := buildComponentUnits([]string{"B", "A", "C"}, map[string][]string{"C": {"A", "B"}})
+	c := units[2]
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			mu.Lock()
+			ran = append(ran, u.Path())
+			mu.Unlock()
+
+			if u.Path() == "A" {
+				return errors.New("bad provider version")
+			}
+
+			return nil
+		}),
+		runnerpool.WithMaxConcurrency(3),
+		// C is not part of wave 0, so the first unit of the wave by path is the canary instead.
+		runnerpool.WithCanaries(func(index int, units []*component.Unit) *component.Unit {
+			return c
+		}),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+
+	var canaryErr runnerpool.CanaryFailedError
+	require.ErrorAs(t, err, &canaryErr)
+	assert.Equal(t, "A", canaryErr.UnitPath)
+	assert.Equal(t, []string{"A"}, ran)
+}
+
+func TestRunner_Canaries(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	// The units have no configuration, so the canary fails.
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.Canaries = true
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{vpc, app})
+	require.NoError(t, err)
+
+	r := report.NewReport()
+
+	err = stack.Run(t.Context(), l, opts, r)
+
+	var canaryErr runnerpool.CanaryFailedError
+	require.ErrorAs(t, err, &canaryErr)
+	assert.Equal(t, app.Path(), canaryErr.UnitPath)
+
+	var earlyExit runnerpool.UnitEarlyExitError
+	require.ErrorAs(t, err, &earlyExit)
+	assert.Equal(t, vpc.Path(), earlyExit.UnitPath)
+}
@@ -0,0 +1,112 @@
+package runnerpool
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+)
+
+// CancellationGroupFunc maps the path of a unit to the name of its cancellation group.
+// Units mapped to an empty name do not belong to any group.
+type CancellationGroupFunc func(path string) string
+
+// WithCancellationGroups scopes fail-fast behavior to groups of units.
+//
+// Units are grouped with the given function. When a unit of a group fails, the context of the other running
+// units of the same group is cancelled, and the units of the group that have not started yet fail without
+// running, with a GroupCancelledError. Units outside of the group are not affected.
+func WithCancellationGroups(groupOf CancellationGroupFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.groupOf = groupOf
+	}
+}
+
+// cancellationGroups holds the cancelable context of every cancellation group of a run.
+type cancellationGroups struct {
+	parent  context.Context
+	ctxs    map[string]context.Context
+	cancels map[string]context.CancelCauseFunc
+	mu      sync.Mutex
+}
+
+// initCancellationGroups prepares the cancellation groups of a run, whose contexts derive from ctx.
+// The returned function releases the contexts and must be called once the run is over.
+func (dr *Controller) initCancellationGroups(ctx context.Context) func() {
+	if dr.groupOf == nil {
+		return func() {}
+	}
+
+	dr.groups = &cancellationGroups{
+		parent:  ctx,
+		ctxs:    map[string]context.Context{},
+		cancels: map[string]context.CancelCauseFunc{},
+	}
+
+	return func() {
+		dr.groups.mu.Lock()
+		defer dr.groups.mu.Unlock()
+
+		for _, cancel := range dr.groups.cancels {
+			cancel(context.Canceled)
+		}
+	}
+}
+
+// groupContext returns the context the unit at the given path runs with: the context of its cancellation
+// group, or ctx when the unit does not belong to any group.
+func (dr *Controller) groupContext(ctx context.Context, path string) context.Context {
+	if dr.groups == nil {
+		return ctx
+	}
+
+	group := dr.groupOf(path)
+	if group == "" {
+		return ctx
+	}
+
+	dr.groups.mu.Lock()
+	defer dr.groups.mu.Unlock()
+
+	if groupCtx, ok := dr.groups.ctxs[group]; ok {
+		return groupCtx
+	}
+
+	groupCtx, cancel := context.WithCancelCause(dr.groups.parent)
+	dr.groups.ctxs[group] = groupCtx
+	dr.groups.cancels[group] = cancel
+
+	return groupCtx
+}
+
+// cancelGroup cancels the cancellation group of the unit at the given path, which failed.
+// Only the first failure of a group is recorded as the cause of its cancellation.
+func (dr *Controller) cancelGroup(path string) {
+	if dr.groups == nil {
+		return
+	}
+
+	group := dr.groupOf(path)
+	if group == "" {
+		return
+	}
+
+	dr.groups.mu.Lock()
+	cancel, ok := dr.groups.cancels[group]
+	dr.groups.mu.Unlock()
+
+	if ok {
+		cancel(errors.New(GroupCancelledError{Group: group, FailedUnit: path}))
+	}
+}
+
+// groupCancellation returns the cause of the cancellation of the given group context, or nil if the
+// context was not cancelled because of a failure in its group.
+func groupCancellation(groupCtx context.Context) error {
+	var cancelled GroupCancelledError
+	if cause := context.Cause(groupCtx); cause != nil && errors.As(cause, &cancelled) {
+		return cause
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestRunnerPool_CancellationGroups(t *testing.T) {
+	t.Parallel()
+
+	// A, B and D are in the same group, C is not; D depends on C.
+	// A fails while B is running, and D is only ready once B has been cancelled.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"D": {"C"},
+		},
+	)
+
+	errA := errors.New("A failed")
+	bStarted := make(chan struct{})
+	bCancelled := make(chan struct{})
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		ran = append(ran, u.Path())
+		mu.Unlock()
+
+		switch u.Path() {
+		case "A":
+			<-bStarted
+			return errA
+		case "B":
+			close(bStarted)
+			<-ctx.Done()
+			close(bCancelled)
+
+			return ctx.Err()
+		case "C":
+			<-bCancelled
+		}
+
+		return nil
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithCancellationGroups(func(path string) string {
+			if path == "C" {
+				return ""
+			}
+
+			return "group"
+		}),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+	require.ErrorIs(t, err, errA)
+
+	var multiErr *errors.MultiError
+	require.ErrorAs(t, err, &multiErr)
+	require.Len(t, multiErr.WrappedErrors(), 3)
+
+	for _, unitErr := range multiErr.WrappedErrors()[1:] {
+		var cancelled runnerpool.GroupCancelledError
+		require.ErrorAs(t, unitErr, &cancelled)
+		assert.Equal(t, "group", cancelled.Group)
+		assert.Equal(t, "A", cancelled.FailedUnit)
+	}
+
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, ran)
+}
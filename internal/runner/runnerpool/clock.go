@@ -0,0 +1,35 @@
+package runnerpool
+
+import "time"
+
+// Clock is the source of time of the controller, used for deadlock detection, dependency wait timeouts,
+// report snapshots and the offsets of streamed events.
+//
+// The real clock is used by default. Tests inject a fake clock with WithClock to control time
+// deterministically, instead of relying on real sleeps.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once the duration has elapsed.
+	After(d time.Duration) <-chan time.Time
+	// Sleep blocks for the duration.
+	Sleep(d time.Duration)
+}
+
+// WithClock sets the clock of the controller. A nil clock keeps the real clock.
+func WithClock(clock Clock) ControllerOption {
+	return func(dr *Controller) {
+		if clock != nil {
+			dr.clock = clock
+		}
+	}
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
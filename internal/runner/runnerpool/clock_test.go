@@ -0,0 +1,87 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// fakeClock advances time instantly: every wait elapses as soon as it starts.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+	mu    sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+
+	return ch
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func TestController_FakeClockDrivesDeadlockDetection(t *testing.T) {
+	t.Parallel()
+
+	// A -> B, where A is never scheduled.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	q := buildQueue(t, units)
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusPending)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return nil
+	}
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithDeadlockDetection(time.Hour),
+		runnerpool.WithClock(clock),
+	)
+
+	// The deadlock is detected right away, without waiting for an hour.
+	err := controller.Run(t.Context(), log.New())
+
+	var deadlockErr runnerpool.SchedulerDeadlockError
+	require.ErrorAs(t, err, &deadlockErr)
+	require.NotEmpty(t, clock.waits)
+
+	for _, wait := range clock.waits {
+		assert.Equal(t, time.Hour, wait)
+	}
+}
@@ -1,8 +1,14 @@
 package runnerpool
 
 import (
+	"cmp"
 	"context"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gruntwork-io/terragrunt/pkg/options"
 
@@ -12,6 +18,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/pkg/log"
 
 	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
 	"github.com/gruntwork-io/terragrunt/internal/telemetry"
 
 	"github.com/puzpuzpuz/xsync/v3"
@@ -26,7 +33,137 @@ type Controller struct {
 	runner      UnitRunner
 	readyCh     chan struct{}
 	unitsMap    map[string]*component.Unit
-	concurrency int
+	exclusive   map[string]bool
+	approve     ApproveUnitFunc
+	approveWave ApproveWaveFunc
+	beforeWave  WaveFunc
+	afterWave   WaveFunc
+	waveIndex   map[string]int
+	waves       []queue.Entries
+	partitionOf PartitionFunc
+	groupOf     CancellationGroupFunc
+	affinityOf  AffinityGroupFunc
+	groups      *cancellationGroups
+	// partitionIndex maps partition names to their position in the partition run order.
+	partitionIndex   map[string]int
+	partitions       []queue.Entries
+	partitionPolicy  CrossPartitionPolicy
+	currentPartition int
+	concurrency      int
+	currentWave      int
+	// closedWaves is the number of waves that have finished and whose after wave hook was invoked.
+	closedWaves int
+	// overlapWaves lets the wave after the first unfinished one open, as decided by the wave barrier.
+	overlapWaves bool
+	// waveBarrier decides after each finished wave whether the next one is a barrier, when set.
+	waveBarrier WaveBarrierFunc
+	// canaries controls whether a canary unit of each wave runs alone before the rest of the wave.
+	canaries bool
+	canaryOf CanaryFunc
+	// pendingCanaries holds the paths of the canaries that have not finished yet, keyed by wave index.
+	pendingCanaries map[int]string
+	// slots bounds how many units run at once, and can be resized while running with SetParallelism.
+	slots *slotSemaphore
+	// parallelismControl adjusts the parallelism while running, when set.
+	parallelismControl ParallelismControlFunc
+	// parallelismSchedule holds the parallelism of each wave in staged execution, the last one repeating.
+	parallelismSchedule []int
+	// timelineFile is the file the dispatch timeline of the run is written to, if any.
+	timelineFile string
+	// eventSocket is the Unix domain socket unit events are streamed to, if any.
+	eventSocket string
+	// resultWebhook is the URL the result of every unit is posted to, if any, and webhookReport the report
+	// the results are read from.
+	resultWebhook string
+	webhookReport *report.Report
+	// webhookFilter filters the lines of the errors posted to the result webhook, if set.
+	webhookFilter OutputFilter
+	// snapshotReport is the report written to snapshotFile every snapshotInterval while running, if any.
+	snapshotReport   *report.Report
+	snapshotFile     string
+	snapshotInterval time.Duration
+	// clock is the source of time of the timers of the run.
+	clock Clock
+	// deadlockInterval is how long the controller waits without progress before reporting a deadlock.
+	deadlockInterval time.Duration
+	// dependencyWaitTimeout bounds how long a unit waits for the next of its dependencies to finish.
+	dependencyWaitTimeout time.Duration
+	// maxStartsPerSecond caps how many units start per second, when positive.
+	maxStartsPerSecond int
+	// notifyBatchSize caps how many ready units are dispatched per scheduling pass, when positive.
+	notifyBatchSize int
+	// memory caps the sum of the memory estimates of the running units, when set.
+	memory *memoryBudget
+	// unitLock returns the lock a unit holds while it runs, when set.
+	unitLock UnitLockFunc
+	// nextStart is the earliest time the next unit may start at. Only accessed by the scheduling loop.
+	nextStart time.Time
+	// dependencyWaits tracks the units waiting on their dependencies. Only accessed by the scheduling loop.
+	dependencyWaits map[string]dependencyWait
+	gate            sync.RWMutex
+	// mu guards unitsMap, finished, cancelled, errs, unitErrs and ignoredErrs, which are mutated while running.
+	mu       sync.Mutex
+	finished bool
+	staged   bool
+	// cancelled holds the errors of the units cancelled while waiting for a concurrency slot, keyed by path.
+	cancelled map[string]error
+	// errs holds every error collected at the end of the last run, before capping.
+	errs []error
+	// unitErrs holds the error each unit returned in the last run, keyed by path.
+	unitErrs map[string]error
+	// ignoredErrs holds the failures of the quarantined and non-blocking units ignored in the current or last
+	// run, keyed by path. Their entries are marked as succeeded so that their dependents run.
+	ignoredErrs map[string]error
+	// maxReportedErrors caps the number of errors returned by Run, when positive.
+	maxReportedErrors int
+	// progress controls whether a progress line is logged every time a unit completes.
+	progress bool
+	// propagateSkip controls whether skipping an unapproved unit also skips the units waiting on it.
+	propagateSkip bool
+	// attributeErrors controls whether unit errors are wrapped with the unit path when collected.
+	attributeErrors bool
+	// firstFailureOnly controls whether Run only returns the error of the unit that failed first.
+	firstFailureOnly bool
+	// continueOnError controls whether every unit is attempted regardless of the failures of other units.
+	continueOnError bool
+	// quarantined holds the paths of the units whose failures neither fail the run nor their dependents.
+	quarantined map[string]bool
+	// nonBlocking holds the paths of the units whose failures are logged and reported, but neither fail the
+	// run nor their dependents.
+	nonBlocking map[string]bool
+	// warnIsolated controls whether Run warns about units with no dependencies and no dependents.
+	warnIsolated bool
+	// warnDiamonds controls whether Run warns about units with two dependencies depending on a shared one.
+	warnDiamonds bool
+	// fanOutThreshold is the number of direct dependents past which Run warns about a unit, when positive.
+	fanOutThreshold int
+	// warnUndeclared controls whether Run warns about units reading the outputs of units they do not depend on.
+	warnUndeclared bool
+	// releaseFinished controls whether the parsed configuration of a unit is dropped once it has finished.
+	releaseFinished bool
+	// errorSeverity ranks collected errors, most severe first, when set.
+	errorSeverity ErrorSeverityFunc
+	// errorClass groups collected errors by class, when set.
+	errorClass ErrorClassFunc
+	// groupSubtrees controls whether collected errors are grouped by the subtree named by errorSubtree,
+	// or by the top-level subtrees of the run when it is nil.
+	groupSubtrees bool
+	errorSubtree  SubtreeFunc
+	// expectedDuration orders ready units, longest first, when set.
+	expectedDuration ExpectedDurationFunc
+	// flakyHunt controls whether the dispatch order and the parallelism are randomized from flakyHuntSeed.
+	flakyHunt     bool
+	flakyHuntSeed int64
+	// dispatchRank orders the ready units by rank when hunting for flaky units, keyed by path.
+	dispatchRank map[string]int
+	// finishOrder records the position in which each unit finished, keyed by path.
+	finishOrder *xsync.MapOf[string, int64]
+	finishSeq   atomic.Int64
+	// onSlotAcquire and onSlotRelease are notified when units take and give back a concurrency slot.
+	onSlotAcquire SlotFunc
+	onSlotRelease SlotFunc
+	onUnitStart   UnitStartFunc
+	slotsInUse    atomic.Int64
 }
 
 // ControllerOption is a function that modifies a Controller.
@@ -50,12 +187,263 @@ func WithMaxConcurrency(concurrency int) ControllerOption {
 	}
 }
 
+// WithErrorPathAttribution controls whether each unit error returned by Run is prefixed with the unit path.
+// Attribution is enabled by default. The original error remains reachable through errors.Is and errors.As.
+func WithErrorPathAttribution(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.attributeErrors = enabled
+	}
+}
+
+// WithFirstFailureOnly makes Run return only the error of the unit that failed first, as a FirstFailureError
+// carrying the other errors as suppressed context, instead of a MultiError of every failure.
+func WithFirstFailureOnly(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.firstFailureOnly = enabled
+	}
+}
+
+// WithMaxReportedErrors caps the number of errors returned by Run, e.g. to keep the output readable when
+// hundreds of units fail. Past the first max errors, Run returns an OmittedErrorsError summarizing the rest,
+// while Errors still returns every error. A zero or negative max reports every error, which is the default.
+func WithMaxReportedErrors(maxErrors int) ControllerOption {
+	return func(dr *Controller) {
+		dr.maxReportedErrors = maxErrors
+	}
+}
+
+// WithContinueOnError makes Run attempt every unit regardless of failures, while still failing the run:
+// a failure neither stops the rest of the run, as it would with fail fast, nor makes the units waiting on the
+// failed unit exit early. Run then returns the errors of every unit that failed, so none of them is hidden
+// behind an early exit.
+func WithContinueOnError(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.continueOnError = enabled
+	}
+}
+
+// WithQuarantine quarantines the units at the given paths, e.g. units known to fail intermittently.
+// When a quarantined unit fails, a warning is logged and the run carries on as if it had succeeded:
+// its dependents still run, and its error is not returned by Run.
+func WithQuarantine(paths ...string) ControllerOption {
+	return func(dr *Controller) {
+		if dr.quarantined == nil {
+			dr.quarantined = make(map[string]bool, len(paths))
+		}
+
+		for _, path := range paths {
+			dr.quarantined[path] = true
+		}
+	}
+}
+
+// WithNonBlocking marks the units at the given paths as non-blocking, e.g. informational units such as
+// reporting units, whose result should never decide the result of the run. A non-blocking unit is run and
+// its failure is logged like the failure of a quarantined unit: its dependents still run, and its error is
+// not returned by Run. Quarantining is meant as a temporary measure for units known to fail intermittently,
+// while non-blocking units are a permanent policy.
+//
+// Unlike ignoring dependency errors for the whole queue, which runs the dependents of every failed unit but
+// still fails the run, only the failures of the given units are ignored, and they do not fail the run.
+func WithNonBlocking(paths ...string) ControllerOption {
+	return func(dr *Controller) {
+		if dr.nonBlocking == nil {
+			dr.nonBlocking = make(map[string]bool, len(paths))
+		}
+
+		for _, path := range paths {
+			dr.nonBlocking[path] = true
+		}
+	}
+}
+
+// ignoredFailureKind returns how the failure of the unit at the given path is ignored: "quarantined" or
+// "non-blocking", or an empty string if its failure is not ignored.
+func (dr *Controller) ignoredFailureKind(path string) string {
+	switch {
+	case dr.quarantined[path]:
+		return "quarantined"
+	case dr.nonBlocking[path]:
+		return "non-blocking"
+	default:
+		return ""
+	}
+}
+
+// ignoreFailure records and logs the failure of a quarantined or non-blocking unit, warning about the
+// dependents that will run anyway.
+func (dr *Controller) ignoreFailure(l log.Logger, ent *queue.Entry, kind string, err error) {
+	l.Warnf("Ignoring the failure of %s unit %s: %v", kind, ent.Component.DisplayPath(), err)
+
+	dr.mu.Lock()
+	if dr.ignoredErrs == nil {
+		dr.ignoredErrs = make(map[string]error)
+	}
+
+	dr.ignoredErrs[ent.Component.Path()] = err
+	dr.mu.Unlock()
+
+	var dependents []string
+
+	for _, other := range dr.q.Snapshot() {
+		if slices.ContainsFunc(other.Component.Dependencies(), func(dep component.Component) bool {
+			return dep.Path() == ent.Component.Path()
+		}) {
+			dependents = append(dependents, other.Component.DisplayPath())
+		}
+	}
+
+	if len(dependents) > 0 {
+		l.Warnf("Units depending on %s unit %s will still run: %s",
+			kind, ent.Component.DisplayPath(), strings.Join(dependents, ", "))
+	}
+}
+
+// IgnoredFailures returns the errors of the quarantined and non-blocking units whose failures were ignored in
+// the current or last run, keyed by path. The queue entries of these units are marked as succeeded, so that
+// their dependents run, and their errors are not returned by Run.
+func (dr *Controller) IgnoredFailures() map[string]error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return maps.Clone(dr.ignoredErrs)
+}
+
+// ignoredFailure returns the ignored failure of the unit at the given path, or nil if it did not fail or its
+// failure was not ignored.
+func (dr *Controller) ignoredFailure(path string) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return dr.ignoredErrs[path]
+}
+
+// WithIsolationWarning makes Run warn about the units that have no dependencies and no dependents, which
+// is often the sign of a forgotten dependency block. The warning is advisory and disabled by default.
+func WithIsolationWarning(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.warnIsolated = enabled
+	}
+}
+
+// warnIsolatedUnits logs the units of the queue that have no dependencies and no dependents.
+// A run of a single unit is isolated by construction, so it is not reported.
+func (dr *Controller) warnIsolatedUnits(l log.Logger) {
+	if !dr.warnIsolated || len(dr.q.Snapshot()) < 2 { //nolint:mnd
+		return
+	}
+
+	isolated := dr.q.IsolatedEntries()
+	if len(isolated) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(isolated))
+
+	for _, path := range isolated {
+		if entry := dr.q.EntryByPath(path); entry != nil {
+			names = append(names, entry.Component.DisplayPath())
+		}
+	}
+
+	l.Warnf("Units with no dependencies and no dependents, check that no dependency block is missing: %s",
+		strings.Join(names, ", "))
+}
+
+// WithDiamondWarning makes Run warn about the diamonds of the dependency graph: units with two dependencies
+// that run concurrently after a shared dependency, and may race if they write to a shared resource.
+// The warning is advisory and disabled by default.
+func WithDiamondWarning(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.warnDiamonds = enabled
+	}
+}
+
+// warnDiamondDependencies logs the diamonds of the dependency graph of the queue.
+func (dr *Controller) warnDiamondDependencies(l log.Logger) {
+	if !dr.warnDiamonds {
+		return
+	}
+
+	for _, diamond := range dr.q.DiamondDependencies() {
+		names := make([]string, 0, len(diamond))
+
+		for _, path := range diamond {
+			if entry := dr.q.EntryByPath(path); entry != nil {
+				names = append(names, entry.Component.DisplayPath())
+			}
+		}
+
+		if len(names) != len(diamond) {
+			continue
+		}
+
+		l.Warnf("Units %s and %s both depend on %s and run concurrently before %s, check that they do not write to a shared resource",
+			names[1], names[2], names[0], names[3])
+	}
+}
+
+// WithFanOutWarning makes Run warn about the units that more than threshold units directly depend on. Such
+// units are bottlenecks of the run, as everything depending on them waits for them, and may be worth
+// splitting. The warning is advisory, and a zero or negative threshold disables it, which is the default.
+func WithFanOutWarning(threshold int) ControllerOption {
+	return func(dr *Controller) {
+		dr.fanOutThreshold = threshold
+	}
+}
+
+// warnHighFanOut logs the units of the queue whose number of direct dependents exceeds the fan out threshold.
+func (dr *Controller) warnHighFanOut(l log.Logger) {
+	if dr.fanOutThreshold <= 0 {
+		return
+	}
+
+	high := dr.q.HighFanOutEntries(dr.fanOutThreshold)
+	if len(high) == 0 {
+		return
+	}
+
+	fanOut := dr.q.FanOut()
+
+	byPath := make(map[string]*queue.Entry)
+	for _, e := range dr.q.Snapshot() {
+		byPath[e.Component.Path()] = e
+	}
+
+	for _, path := range high {
+		entry, ok := byPath[path]
+		if !ok {
+			continue
+		}
+
+		l.Warnf("Unit %s has %d direct dependents, which all wait for it, consider splitting it to reduce the bottleneck",
+			entry.Component.DisplayPath(), fanOut[path])
+	}
+}
+
+// ErrorSeverityFunc ranks an error returned by a unit: the higher the value, the more severe the error.
+type ErrorSeverityFunc func(err error) int
+
+// WithErrorSeverity makes Run order the collected errors from the most to the least severe, according to
+// the given function, so that the first error of the returned MultiError is the most severe one.
+// Errors of equal severity keep their queue order. By default, errors are not reordered.
+//
+// When combined with WithFirstFailureOnly, the first failure is still picked by finish order.
+func WithErrorSeverity(severity ErrorSeverityFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.errorSeverity = severity
+	}
+}
+
 // NewController creates a new Controller with the given options and a pre-built queue.
 func NewController(q *queue.Queue, units []*component.Unit, opts ...ControllerOption) *Controller {
 	dr := &Controller{
-		q:           q,
-		readyCh:     make(chan struct{}, 1), // buffered to avoid blocking
-		concurrency: options.DefaultParallelism,
+		q:               q,
+		readyCh:         make(chan struct{}, 1), // buffered to avoid blocking
+		concurrency:     options.DefaultParallelism,
+		attributeErrors: true,
+		finishOrder:     xsync.NewMapOf[string, int64](),
+		clock:           realClock{},
 	}
 	// Map to link runner Units and Queue Entries
 	unitsMap := make(map[string]*component.Unit)
@@ -76,29 +464,99 @@ func NewController(q *queue.Queue, units []*component.Unit, opts ...ControllerOp
 		dr.q = &queue.Queue{Entries: []*queue.Entry{}}
 	}
 
+	dr.slots = newSlotSemaphore(dr.concurrency)
+
 	return dr
 }
 
+// Validate checks that every entry of the queue has a matching unit to run.
+//
+// Entries can be left without a unit when units are removed from the run after the queue was built,
+// e.g. by exclusion. Such entries would otherwise only fail once they are scheduled, so Run calls Validate
+// before scheduling any unit. Returns an OrphanedEntriesError listing the entries without a unit.
+func (dr *Controller) Validate() error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	var orphaned []string
+
+	for _, entry := range dr.q.Snapshot() {
+		if _, ok := dr.unitsMap[entry.Component.Path()]; !ok {
+			orphaned = append(orphaned, entry.Component.Path())
+		}
+	}
+
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	slices.Sort(orphaned)
+
+	return errors.New(OrphanedEntriesError{Paths: orphaned})
+}
+
 // Run executes the Queue return error summarizing all entries that failed to run.
 func (dr *Controller) Run(ctx context.Context, l log.Logger) error {
 	return telemetry.TelemeterFromContext(ctx).Collect(ctx, "runner_pool_controller", map[string]any{
-		"total_tasks":             len(dr.q.Entries),
+		"total_tasks":             len(dr.q.Snapshot()),
 		"concurrency":             dr.concurrency,
 		"fail_fast":               dr.q.FailFast,
 		"ignore_dependency_order": dr.q.IgnoreDependencyOrder,
 	}, func(childCtx context.Context) error {
 		var (
 			wg      sync.WaitGroup
-			sem     = make(chan struct{}, dr.concurrency)
 			results = xsync.NewMapOf[string, error]()
+			waveErr error
 		)
 
 		if dr.runner == nil {
 			return errors.Errorf("Runner Pool Controller: runner is not set, cannot run")
 		}
 
+		if err := validateParallelismSchedule(dr.parallelismSchedule); err != nil {
+			return err
+		}
+
+		if err := dr.Validate(); err != nil {
+			return err
+		}
+
+		dr.mu.Lock()
+		dr.ignoredErrs = nil
+		dr.mu.Unlock()
+
+		if dr.continueOnError {
+			dr.q.FailFast = false
+			dr.q.IgnoreDependencyErrors = true
+		}
+
+		runner, writeTimeline := dr.recordTimeline(l, dr.runner)
+		defer writeTimeline()
+
+		runner, closeEvents := dr.streamEvents(childCtx, l, runner)
+		defer closeEvents()
+
+		runner, closeWebhook := dr.postResults(childCtx, l, runner, results)
+		defer closeWebhook()
+
+		stopSnapshots := dr.snapshotReports(l)
+		defer stopSnapshots()
+
+		releaseGroups := dr.initCancellationGroups(childCtx)
+		defer releaseGroups()
+
 		l.Debugf("Runner Pool Controller: starting with %d tasks, concurrency %d",
-			len(dr.q.Entries), dr.concurrency)
+			len(dr.q.Snapshot()), dr.concurrency)
+
+		dr.warnIsolatedUnits(l)
+		dr.warnDiamondDependencies(l)
+		dr.warnHighFanOut(l)
+		dr.warnUndeclaredDependencies(l)
+
+		dr.initFlakyHunt(l)
+
+		stopControl := dr.startParallelismControl(childCtx)
+		defer stopControl()
 
 		// Initial signal to start scheduling
 		select {
@@ -106,22 +564,101 @@ func (dr *Controller) Run(ctx context.Context, l log.Logger) error {
 		default:
 		}
 
+		if dr.staged || dr.onUnitStart != nil {
+			dr.initWaves()
+		}
+
+		if dr.partitionOf != nil {
+			if err := dr.initPartitions(l); err != nil {
+				return err
+			}
+		}
+
+	schedule:
 		for {
+			if dr.staged && waveErr == nil {
+				if waveErr = dr.advanceWaves(l); waveErr != nil {
+					l.Debugf("Runner Pool Controller: aborting remaining tasks: %v", waveErr)
+					dr.q.EarlyExitRemaining()
+				}
+
+				dr.resizeForWave(l)
+			}
+
+			if dr.partitionOf != nil {
+				dr.advancePartitions(l)
+			}
+
 			readyEntries := dr.q.GetReadyWithDependencies(l)
+			if dr.staged {
+				readyEntries = dr.withoutCanaryWaits(dr.inCurrentWave(readyEntries))
+			}
+
+			if dr.partitionOf != nil {
+				readyEntries = dr.inCurrentPartition(readyEntries)
+			}
+
+			if dr.expectedDuration != nil {
+				readyEntries = dr.longestFirst(readyEntries)
+			}
+
+			if dr.flakyHunt {
+				readyEntries = dr.shuffled(readyEntries)
+			}
+
+			if dr.affinityOf != nil {
+				readyEntries = dr.withAffinity(l, readyEntries)
+			}
+
 			l.Debugf("Runner Pool Controller: found %d readyEntries tasks", len(readyEntries))
 
+			readyEntries, heldBack := dr.nextBatch(readyEntries)
+
 			for _, e := range readyEntries {
+				if !dr.approveEntry(childCtx, l, e, results) {
+					continue
+				}
+
+				if !dr.reserveMemory(l, e) {
+					continue
+				}
+
+				unlock, locked := dr.tryLockUnit(l, e)
+				if !locked {
+					dr.releaseMemory(e.Component.Path())
+					continue
+				}
+
 				// log debug which entry is running
-				l.Debugf("Runner Pool Controller: running %s", e.Component.Path())
+				l.Debugf("Runner Pool Controller: running %s", e.Component.DisplayPath())
 				dr.q.SetEntryStatus(e, queue.StatusRunning)
 
-				sem <- struct{}{}
+				if err := dr.waitForStart(childCtx); err != nil {
+					unlock()
+					dr.releaseMemory(e.Component.Path())
+					dr.cancelEntry(l, e, results, err)
+
+					continue
+				}
+
+				if err := dr.slots.acquire(childCtx); err != nil {
+					unlock()
+					dr.releaseMemory(e.Component.Path())
+					dr.cancelEntry(l, e, results, err)
+
+					continue
+				}
+
+				dr.slotAcquired(e.Component.Path())
 
 				wg.Add(1)
 
-				go func(ent *queue.Entry) {
+				go func(ent *queue.Entry, unlock func()) {
 					defer func() {
-						<-sem
+						dr.slotReleased(ent.Component.Path())
+						dr.slots.release()
+						unlock()
+						dr.releaseMemory(ent.Component.Path())
 						wg.Done()
 
 						select {
@@ -130,38 +667,101 @@ func (dr *Controller) Run(ctx context.Context, l log.Logger) error {
 						}
 					}()
 
-					unit := dr.unitsMap[ent.Component.Path()]
+					unit := dr.unit(ent.Component.Path())
 					if unit == nil {
 						err := errors.Errorf("unit for path %s not found in discovered units", ent.Component.Path())
 						l.Errorf("Runner Pool Controller: unit for path %s not found in discovered units, skipping execution", ent.Component.Path())
 						dr.q.FailEntry(ent)
-						results.Store(ent.Component.Path(), err)
+						dr.storeResult(results, ent.Component.Path(), err)
+
+						return
+					}
+
+					runCtx := dr.groupContext(childCtx, ent.Component.Path())
+					if cancelled := groupCancellation(runCtx); cancelled != nil {
+						l.Debugf("Runner Pool Controller: %s not run: %v", ent.Component.DisplayPath(), cancelled)
+						dr.q.FailEntry(ent)
+						dr.storeResult(results, ent.Component.Path(), cancelled)
+						dr.logProgress(l, unit, "cancelled")
 
 						return
 					}
 
-					err := dr.runner(childCtx, unit)
-					results.Store(ent.Component.Path(), err)
+					release := dr.acquireGate(ent.Component.Path())
+
+					dr.unitStarted(unit)
+					err := runner(runCtx, unit)
+
+					release()
+
+					if cancelled := groupCancellation(runCtx); cancelled != nil && errors.Is(err, context.Canceled) {
+						err = cancelled
+					}
+
+					// An ignored failure is recorded on its own, and the entry still succeeds so that its
+					// dependents run.
+					outcome := "finished"
+
+					if kind := dr.ignoredFailureKind(ent.Component.Path()); err != nil && kind != "" {
+						dr.ignoreFailure(l, ent, kind, err)
+						err = nil
+						outcome = "failed (ignored)"
+					}
+
+					dr.storeResult(results, ent.Component.Path(), err)
+					dr.releaseUnit(unit)
 
 					if err != nil {
-						l.Debugf("Runner Pool Controller: %s failed", ent.Component.Path())
-						dr.q.FailEntry(ent)
+						l.Debugf("Runner Pool Controller: %s failed", ent.Component.DisplayPath())
+						dr.cancelGroup(ent.Component.Path())
+						dr.failEntry(childCtx, ent)
+						dr.logProgress(l, unit, "failed")
 
 						return
 					}
 
-					l.Debugf("Runner Pool Controller: %s succeeded", ent.Component.Path())
+					l.Debugf("Runner Pool Controller: %s succeeded", ent.Component.DisplayPath())
 					dr.q.SetEntryStatus(ent, queue.StatusSucceeded)
-				}(e)
+					dr.logProgress(l, unit, outcome)
+				}(e, unlock)
 			}
 
-			if dr.q.Finished() {
+			if heldBack {
+				dr.pauseBeforeNextBatch(childCtx)
+			}
+
+			if dr.markFinished() {
 				break
 			}
 
+			watchdog := dr.watchdog()
+			dependencyTimer := dr.dependencyWaitTimer()
+
 			select {
 			case <-dr.readyCh:
+			case <-dependencyTimer:
+				dr.expireDependencyWaits(l, results)
+			case <-watchdog:
+				if err := dr.detectDeadlock(l); err != nil {
+					dr.mu.Lock()
+					dr.finished = true
+					dr.mu.Unlock()
+
+					wg.Wait()
+
+					return err
+				}
 			case <-childCtx.Done():
+				dr.mu.Lock()
+				dr.finished = true
+				dr.mu.Unlock()
+
+				// When the deadline of the run passes, the units that were not started are failed and
+				// reported, while the running ones stop on their own.
+				if dr.expireDeadline(childCtx, l, results) {
+					break schedule
+				}
+
 				wg.Wait()
 				return nil
 			}
@@ -169,30 +769,204 @@ func (dr *Controller) Run(ctx context.Context, l log.Logger) error {
 
 		wg.Wait()
 
-		// Collect errors from results map and check for errors
-		errCollector := &errors.MultiError{}
+		// The last wave may have finished right before the loop exited, so give it a chance to complete.
+		if dr.staged && waveErr == nil {
+			waveErr = dr.advanceWaves(l)
+		}
 
-		for _, entry := range dr.q.Entries {
-			if err, ok := results.Load(entry.Component.Path()); ok {
-				if err == nil {
-					continue
-				}
+		errCollector := dr.collectErrors(results)
 
-				errCollector = errCollector.Append(err)
+		if waveErr != nil {
+			errCollector = errCollector.Append(waveErr)
+		}
+
+		return errCollector.ErrorOrNil()
+	})
+}
+
+// ErrControllerFinished is returned when adding a unit to a controller that is no longer scheduling units.
+var ErrControllerFinished = errors.New("runner pool controller has finished")
+
+// AddUnit adds a unit to the controller while it is running.
+//
+// The unit's dependencies must already be part of the run, and the addition must not introduce a cycle.
+// The unit is started as soon as its dependencies have succeeded, which may be immediately.
+// Units can only be added until the controller has finished scheduling, after which ErrControllerFinished is returned.
+func (dr *Controller) AddUnit(unit *component.Unit) error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	if dr.finished {
+		return ErrControllerFinished
+	}
+
+	if _, err := dr.q.AddEntry(unit); err != nil {
+		return err
+	}
+
+	dr.unitsMap[unit.Path()] = unit
+
+	select {
+	case dr.readyCh <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
 
+// unit returns the unit at the given path, or nil if it is not part of the run.
+func (dr *Controller) unit(path string) *component.Unit {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return dr.unitsMap[path]
+}
+
+// markFinished reports whether every entry of the queue is in a terminal state.
+// Once it returns true, no more units can be added to the controller.
+func (dr *Controller) markFinished() bool {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	dr.finished = dr.q.Finished()
+
+	return dr.finished
+}
+
+// storeResult records the outcome of the unit at the given path, along with the order in which it finished.
+func (dr *Controller) storeResult(results *xsync.MapOf[string, error], path string, err error) {
+	dr.finishOrder.Store(path, dr.finishSeq.Add(1))
+	results.Store(path, err)
+}
+
+// collectErrors gathers the errors of all entries that failed or exited early into a single MultiError.
+// In first failure mode, the MultiError only holds a FirstFailureError, and with error groups, it holds an
+// ErrorGroupError per class, or per subtree.
+func (dr *Controller) collectErrors(results *xsync.MapOf[string, error]) *errors.MultiError {
+	errCollector := &errors.MultiError{}
+	unitErrs := make(map[string]error)
+	// paths holds the path of the unit of every collected error, in the order of the collector.
+	paths := []string{}
+
+	// first is the index in the collector of the error of the unit that failed first, or -1.
+	var (
+		first      = -1
+		firstOrder int64
+	)
+
+	for _, entry := range dr.q.Snapshot() {
+		if err, ok := results.Load(entry.Component.Path()); ok {
+			if err == nil {
 				continue
 			}
 
-			if entry.Status == queue.StatusEarlyExit {
-				failedDep := findFailedDependency(entry, dr.q)
-				errCollector = errCollector.Append(NewUnitEarlyExitError(entry.Component.Path(), failedDep))
+			unitErrs[entry.Component.Path()] = err
+
+			if dr.attributeErrors {
+				err = errors.Errorf("[%s]: %w", entry.Component.DisplayPath(), err)
 			}
 
-			if entry.Status == queue.StatusFailed {
-				errCollector = errCollector.Append(NewUnitFailedError(entry.Component.Path()))
+			errCollector = errCollector.Append(err)
+			paths = append(paths, entry.Component.Path())
+
+			if order, ok := dr.finishOrder.Load(entry.Component.Path()); ok && (first < 0 || order < firstOrder) {
+				first, firstOrder = errCollector.Len()-1, order
 			}
+
+			continue
 		}
 
-		return errCollector.ErrorOrNil()
+		if entry.Status == queue.StatusEarlyExit {
+			failedDep := findFailedDependency(entry, dr.q)
+			err := NewUnitEarlyExitError(entry.Component.Path(), failedDep)
+			errCollector = errCollector.Append(err)
+			paths = append(paths, entry.Component.Path())
+		}
+
+		if entry.Status == queue.StatusFailed {
+			err := NewUnitFailedError(entry.Component.Path())
+			errCollector = errCollector.Append(err)
+			paths = append(paths, entry.Component.Path())
+		}
+	}
+
+	dr.mu.Lock()
+	dr.errs = errCollector.WrappedErrors()
+	dr.unitErrs = unitErrs
+	dr.mu.Unlock()
+
+	if !dr.firstFailureOnly || errCollector.Len() == 0 {
+		if subtreeOf := dr.subtreeFunc(); subtreeOf != nil {
+			return dr.groupErrors(errCollector, func(i int, _ error) string { return subtreeOf(paths[i]) })
+		}
+
+		if dr.errorClass != nil {
+			return dr.groupErrors(dr.sortBySeverity(errCollector), func(_ int, err error) string { return dr.errorClass(err) })
+		}
+
+		return dr.capErrors(dr.sortBySeverity(errCollector))
+	}
+
+	// Units that never ran, e.g. because of an early exit, have no finish order: only fall back to them
+	// when no unit failed while running. The errors are told apart by position, as they may not be comparable.
+	if first < 0 {
+		first = 0
+	}
+
+	errs := errCollector.WrappedErrors()
+	suppressed := slices.Delete(slices.Clone(errs), first, first+1)
+
+	return (&errors.MultiError{}).Append(errors.New(FirstFailureError{Err: errs[first], Suppressed: suppressed}))
+}
+
+// capErrors returns the errors of the collector truncated to the maximum number of reported errors, with
+// an OmittedErrorsError standing in for the rest, or the collector itself when it is within the maximum.
+func (dr *Controller) capErrors(errCollector *errors.MultiError) *errors.MultiError {
+	if dr.maxReportedErrors <= 0 || errCollector.Len() <= dr.maxReportedErrors {
+		return errCollector
+	}
+
+	errs := errCollector.WrappedErrors()
+
+	return (&errors.MultiError{}).
+		Append(errs[:dr.maxReportedErrors]...).
+		Append(errors.New(OmittedErrorsError{Errors: errs[dr.maxReportedErrors:]}))
+}
+
+// Errors returns every error collected at the end of the last run, in queue order, including the ones
+// left out of the error returned by Run because of WithMaxReportedErrors or WithFirstFailureOnly.
+func (dr *Controller) Errors() []error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return slices.Clone(dr.errs)
+}
+
+// sortBySeverity returns the errors of the collector ordered from the most to the least severe,
+// or the collector itself when no severity function is set.
+func (dr *Controller) sortBySeverity(errCollector *errors.MultiError) *errors.MultiError {
+	if dr.errorSeverity == nil || errCollector.Len() < 2 { //nolint:mnd
+		return errCollector
+	}
+
+	type rankedError struct {
+		err      error
+		severity int
+	}
+
+	ranked := make([]rankedError, 0, errCollector.Len())
+	for _, err := range errCollector.WrappedErrors() {
+		ranked = append(ranked, rankedError{err: err, severity: dr.errorSeverity(err)})
+	}
+
+	slices.SortStableFunc(ranked, func(a, b rankedError) int {
+		return cmp.Compare(b.severity, a.severity)
 	})
+
+	sorted := &errors.MultiError{}
+	for _, r := range ranked {
+		sorted = sorted.Append(r.err)
+	}
+
+	return sorted
 }
@@ -1,17 +1,30 @@
 package runnerpool_test
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/gruntwork-io/terragrunt/internal/errors"
 
 	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
 	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
 
 	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
 	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,6 +57,21 @@ func buildComponentUnits(paths []string, depMap map[string][]string) []*componen
 	return units
 }
 
+// buildQueue creates a queue from the given units.
+func buildQueue(t *testing.T, units []*component.Unit) *queue.Queue {
+	t.Helper()
+
+	components := make(component.Components, len(units))
+	for i, u := range units {
+		components[i] = u
+	}
+
+	q, err := queue.NewQueue(components)
+	require.NoError(t, err)
+
+	return q
+}
+
 func TestRunnerPool_LinearDependency(t *testing.T) {
 	t.Parallel()
 
@@ -287,3 +315,739 @@ func TestRunnerPool_ComplexDependency_BFails_FailFast(t *testing.T) {
 		assert.Contains(t, err.Error(), want, "Expected error message '%s' in errors", want)
 	}
 }
+
+type typedUnitError struct {
+	code int
+}
+
+func (e typedUnitError) Error() string {
+	return "typed unit error"
+}
+
+func TestRunnerPool_ErrorsAttributedWithUnitPath(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, nil)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "A" {
+			return typedUnitError{code: 42}
+		}
+
+		return nil
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[A]: typed unit error")
+
+	var typed typedUnitError
+	require.ErrorAs(t, err, &typed)
+	assert.Equal(t, 42, typed.code)
+}
+
+func TestRunnerPool_ErrorPathAttributionDisabled(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return typedUnitError{}
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithErrorPathAttribution(false),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "[A]")
+}
+
+func TestRunnerPool_FirstFailureOnly(t *testing.T) {
+	t.Parallel()
+
+	// A and B fail, B first; C depends on A and exits early.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"C": {"A"},
+		},
+	)
+
+	errA := errors.New("A failed")
+	errB := errors.New("B failed")
+	bFailed := make(chan struct{})
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "B" {
+			close(bFailed)
+			return errB
+		}
+
+		<-bFailed
+		time.Sleep(50 * time.Millisecond)
+
+		return errA
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+		runnerpool.WithFirstFailureOnly(true),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var first runnerpool.FirstFailureError
+	require.ErrorAs(t, err, &first)
+	require.ErrorIs(t, first.Err, errB)
+	require.NotErrorIs(t, err, errA)
+	assert.Len(t, first.Suppressed, 2)
+	assert.Contains(t, err.Error(), "[B]: B failed (and 2 more failures)")
+}
+
+// pathsError is not comparable, so comparing two of them with == panics.
+type pathsError []string
+
+func (err pathsError) Error() string {
+	return strings.Join(err, ", ") + " failed"
+}
+
+func TestRunnerPool_FirstFailureOnlyNonComparableErrors(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, nil)
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			return pathsError{u.Path()}
+		}),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithErrorPathAttribution(false),
+		runnerpool.WithFirstFailureOnly(true),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var first runnerpool.FirstFailureError
+	require.ErrorAs(t, err, &first)
+	assert.Len(t, first.Suppressed, 1)
+	assert.NotEqual(t, first.Err.Error(), first.Suppressed[0].Error())
+}
+
+func TestRunnerPool_MaxReportedErrors(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D", "E"}, map[string][]string{})
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return errors.New(u.Path() + " failed")
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithMaxReportedErrors(2),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	// Only the first two errors are reported, followed by a summary of the rest.
+	assert.Contains(t, err.Error(), "A failed")
+	assert.Contains(t, err.Error(), "B failed")
+	assert.NotContains(t, err.Error(), "C failed")
+	assert.Contains(t, err.Error(), "and 3 more errors")
+
+	var omitted runnerpool.OmittedErrorsError
+	require.ErrorAs(t, err, &omitted)
+	assert.Len(t, omitted.Errors, 3)
+
+	// Every error remains available to tooling.
+	all := controller.Errors()
+	require.Len(t, all, 5)
+	assert.ErrorContains(t, all[4], "E failed")
+}
+
+func TestRunnerPool_ErrorSeverity(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C"}, map[string][]string{})
+
+	errThrottled := errors.New("throttled")
+	errAuth := errors.New("auth failed")
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "B" {
+			return errAuth
+		}
+
+		return errThrottled
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithErrorSeverity(func(err error) int {
+			if errors.Is(err, errAuth) {
+				return 1
+			}
+
+			return 0
+		}),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var multiErr *errors.MultiError
+	require.ErrorAs(t, err, &multiErr)
+
+	errs := multiErr.WrappedErrors()
+	require.Len(t, errs, 3)
+	require.ErrorIs(t, errs[0], errAuth)
+	assert.Contains(t, errs[1].Error(), "[A]")
+	assert.Contains(t, errs[2].Error(), "[C]")
+}
+
+func TestRunnerPool_ErrorGroups(t *testing.T) {
+	t.Parallel()
+
+	// A <- C, with A and B failing and C exiting early.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"C": {"A"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "A" || u.Path() == "B" {
+			return errors.New(u.Path() + " failed")
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxReportedErrors(1),
+		runnerpool.WithErrorGroups(nil),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var multiErr *errors.MultiError
+	require.ErrorAs(t, err, &multiErr)
+
+	groups := multiErr.WrappedErrors()
+	require.Len(t, groups, 2)
+
+	var runErrors runnerpool.ErrorGroupError
+	require.ErrorAs(t, groups[0], &runErrors)
+	assert.Equal(t, "*errors.errorString", runErrors.Class)
+	assert.Equal(t, 2, runErrors.Count)
+	require.Len(t, runErrors.Errors, 2)
+	assert.ErrorContains(t, runErrors.Errors[0], "A failed")
+
+	var omitted runnerpool.OmittedErrorsError
+	require.ErrorAs(t, runErrors.Errors[1], &omitted)
+	assert.Len(t, omitted.Errors, 1)
+
+	var earlyExits runnerpool.ErrorGroupError
+	require.ErrorAs(t, groups[1], &earlyExits)
+	assert.Equal(t, "runnerpool.UnitEarlyExitError", earlyExits.Class)
+	assert.Equal(t, 1, earlyExits.Count)
+
+	// The grouped errors still match, and the raw list remains available.
+	var earlyExit runnerpool.UnitEarlyExitError
+	require.ErrorAs(t, err, &earlyExit)
+	assert.Equal(t, "C", earlyExit.UnitPath)
+	assert.Contains(t, err.Error(), "runnerpool.UnitEarlyExitError (1):")
+	assert.Len(t, controller.Errors(), 3)
+}
+
+func TestRunner_GroupErrorsByType(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	// The units have no configuration, so vpc and db fail and app exits early.
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+	db := component.NewUnit(filepath.Join(rootDir, "db")).WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(vpc)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.GroupErrorsByType = true
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{vpc, app, db})
+	require.NoError(t, err)
+
+	err = stack.Run(t.Context(), l, opts, report.NewReport())
+	require.Error(t, err)
+
+	var group runnerpool.ErrorGroupError
+	require.ErrorAs(t, err, &group)
+	assert.Contains(t, err.Error(), "runnerpool.UnitEarlyExitError (1):")
+	assert.Contains(t, err.Error(), " (2):")
+}
+
+func TestRunnerPool_Quarantine(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, with A quarantined and failing.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		ran = append(ran, u.Path())
+		mu.Unlock()
+
+		if u.Path() == "A" {
+			return errors.New("A is flaky")
+		}
+
+		return nil
+	}
+
+	q := buildQueue(t, units)
+
+	err := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithQuarantine("A"),
+	).Run(t.Context(), logger.CreateLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A", "B"}, ran)
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("B").Status)
+}
+
+func TestRunnerPool_NonBlocking(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, with A non-blocking and failing.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	buf := new(bytes.Buffer)
+	l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+	q := buildQueue(t, units)
+
+	err := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			if u.Path() == "A" {
+				return errors.New("reporting failed")
+			}
+
+			return nil
+		}),
+		runnerpool.WithNonBlocking("A"),
+	).Run(t.Context(), l)
+	require.NoError(t, err)
+
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("B").Status)
+	assert.Contains(t, buf.String(), "Ignoring the failure of non-blocking unit A")
+	assert.Contains(t, buf.String(), "Units depending on non-blocking unit A will still run: B")
+}
+
+func TestRunnerPool_IgnoredFailures(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, with A non-blocking and failing.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	var summaries []runnerpool.RunSummary
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			if u.Path() == "A" {
+				return errors.New("reporting failed")
+			}
+
+			return nil
+		}),
+		runnerpool.WithNonBlocking("A"),
+		runnerpool.WithWaveBarrier(func(index int, results runnerpool.RunSummary) bool {
+			summaries = append(summaries, results)
+			return true
+		}),
+	)
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	// The failure of A is recorded, even though B ran after it.
+	ignored := controller.IgnoredFailures()
+	require.Len(t, ignored, 1)
+	require.ErrorContains(t, ignored["A"], "reporting failed")
+
+	require.NotEmpty(t, summaries)
+	assert.Equal(t, []string{"A"}, summaries[0].Failed)
+	assert.Empty(t, summaries[0].Succeeded)
+
+	detail, err := controller.FailureReason("A")
+	require.NoError(t, err)
+	assert.True(t, detail.Ignored)
+	assert.Equal(t, queue.StatusSucceeded, detail.Status)
+	require.ErrorContains(t, detail.Err, "reporting failed")
+
+	detail, err = controller.FailureReason("B")
+	require.NoError(t, err)
+	assert.False(t, detail.Ignored)
+	assert.NoError(t, detail.Err)
+}
+
+func TestRunnerPool_ContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C, with A and B failing in a fail fast queue.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		ran = append(ran, u.Path())
+		mu.Unlock()
+
+		if u.Path() != "C" {
+			return errors.New(u.Path() + " failed")
+		}
+
+		return nil
+	}
+
+	q := buildQueue(t, units)
+	q.FailFast = true
+
+	err := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithContinueOnError(true),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	// Every unit ran, and the failures of both A and B are returned.
+	assert.Equal(t, []string{"A", "B", "C"}, ran)
+	assert.ErrorContains(t, err, "A failed")
+	assert.ErrorContains(t, err, "B failed")
+	assert.Equal(t, queue.StatusFailed, q.EntryByPath("B").Status)
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("C").Status)
+}
+
+func TestRunnerPool_AddUnitWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	// A -> B, with C discovered while A is running and depending on A.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	unitC := component.NewUnit("C")
+	unitC.AddDependency(units[0])
+
+	var (
+		mu         sync.Mutex
+		ran        []string
+		controller *runnerpool.Controller
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		ran = append(ran, u.Path())
+		mu.Unlock()
+
+		if u.Path() == "A" {
+			return controller.AddUnit(unitC)
+		}
+
+		return nil
+	}
+
+	controller = runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, ran)
+
+	require.ErrorIs(t, controller.AddUnit(component.NewUnit("D")), runnerpool.ErrControllerFinished)
+}
+
+func TestRunnerPool_AddUnitWhileIgnoringFailure(t *testing.T) {
+	t.Parallel()
+
+	// A fails and is non-blocking, so its dependents are looked up while B adds units to the run.
+	units := buildComponentUnits([]string{"A", "B"}, nil)
+
+	var controller *runnerpool.Controller
+
+	adding := make(chan struct{})
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		switch u.Path() {
+		case "A":
+			<-adding
+			return errors.New("reporting failed")
+		case "B":
+			close(adding)
+
+			for i := range 200 {
+				if err := controller.AddUnit(component.NewUnit(fmt.Sprintf("added-%d", i))); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	controller = runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+		runnerpool.WithNonBlocking("A"),
+		runnerpool.WithErrorSubtrees(nil),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.Len(t, controller.IgnoredFailures(), 1)
+}
+
+func TestRunnerPool_ValidateDetectsOrphanedEntries(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C"}, map[string][]string{"B": {"A"}})
+	q := buildQueue(t, units)
+
+	require.NoError(t, runnerpool.NewController(q, units).Validate())
+
+	// Drop B and C from the units to run, while their entries stay in the queue.
+	err := runnerpool.NewController(q, units[:1]).Validate()
+	require.Error(t, err)
+
+	var orphaned runnerpool.OrphanedEntriesError
+	require.ErrorAs(t, err, &orphaned)
+	assert.Equal(t, []string{"B", "C"}, orphaned.Paths)
+}
+
+func TestRunnerPool_RunFailsOnOrphanedEntries(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, nil)
+
+	var ran atomic.Bool
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units[:1],
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			ran.Store(true)
+			return nil
+		}),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+
+	var orphaned runnerpool.OrphanedEntriesError
+	require.ErrorAs(t, err, &orphaned)
+	assert.Equal(t, []string{"B"}, orphaned.Paths)
+	assert.False(t, ran.Load())
+}
+
+func TestController_IsolationWarning(t *testing.T) {
+	t.Parallel()
+
+	// A -> B, and C on its own.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	for _, enabled := range []bool{true, false} {
+		buf := new(bytes.Buffer)
+		l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+			runnerpool.WithIsolationWarning(enabled),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), l))
+
+		if enabled {
+			assert.Contains(t, buf.String(), "check that no dependency block is missing: C")
+		} else {
+			assert.NotContains(t, buf.String(), "no dependency block is missing")
+		}
+	}
+}
+
+func TestController_DiamondWarning(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, A <- C, and D depends on both B and C.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"A"},
+			"D": {"B", "C"},
+		},
+	)
+
+	for _, enabled := range []bool{true, false} {
+		buf := new(bytes.Buffer)
+		l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+			runnerpool.WithDiamondWarning(enabled),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), l))
+
+		if enabled {
+			assert.Contains(t, buf.String(), "Units B and C both depend on A and run concurrently before D")
+		} else {
+			assert.NotContains(t, buf.String(), "both depend on")
+		}
+	}
+}
+
+func TestController_FanOutWarning(t *testing.T) {
+	t.Parallel()
+
+	// A <- B, C, D.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"A"},
+			"D": {"A"},
+		},
+	)
+
+	for _, threshold := range []int{2, 3} {
+		buf := new(bytes.Buffer)
+		l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+			runnerpool.WithFanOutWarning(threshold),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), l))
+
+		if threshold < 3 {
+			assert.Contains(t, buf.String(), "Unit A has 3 direct dependents")
+		} else {
+			assert.NotContains(t, buf.String(), "direct dependents")
+		}
+	}
+}
+
+func TestRunner_FanOutWarningThreshold(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	// vpc <- app, db, cache.
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	units := component.Components{vpc}
+
+	for _, name := range []string{"app", "db", "cache"} {
+		unit := component.NewUnit(filepath.Join(rootDir, name)).WithConfig(&config.TerragruntConfig{})
+		unit.AddDependency(vpc)
+		units = append(units, unit)
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+	opts.FanOutWarningThreshold = 2
+
+	buf := new(bytes.Buffer)
+	l := logger.CreateLogger()
+	l.SetOptions(log.WithOutput(buf))
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units)
+	require.NoError(t, err)
+
+	// The units have no configuration, so the run fails, but the warning is logged before any unit runs.
+	require.Error(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+	assert.Contains(t, buf.String(), "Unit "+vpc.Path()+" has 3 direct dependents")
+}
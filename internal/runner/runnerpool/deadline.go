@@ -0,0 +1,59 @@
+package runnerpool
+
+import (
+	"context"
+
+	"github.com/puzpuzpuz/xsync/v3"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// expireDeadline fails every unit that has not started yet with a UnitDeadlineExceededError when the context
+// of the run is done because its deadline passed, and reports whether it did. The running units are
+// signalled through their own context, which shares the deadline, and fail as they stop.
+//
+// The units are reported as cancelled, see Cancelled, so that the deadline can be told apart from a
+// dependency failure.
+func (dr *Controller) expireDeadline(ctx context.Context, l log.Logger, results *xsync.MapOf[string, error]) bool {
+	if !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		return false
+	}
+
+	expired := dr.q.FailRemaining()
+
+	if len(expired) > 0 {
+		l.Warnf("Deadline of the run exceeded, %d units were not started", len(expired))
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	if dr.cancelled == nil {
+		dr.cancelled = make(map[string]error)
+	}
+
+	for _, ent := range expired {
+		path := ent.Component.Path()
+		err := errors.New(UnitDeadlineExceededError{UnitPath: path})
+
+		dr.storeResult(results, path, err)
+		dr.cancelled[path] = err
+	}
+
+	return true
+}
+
+// failEntry marks the entry of a unit that failed as failed. Once the deadline of the run passed, the failure
+// is not propagated: the units that were never started are then failed by expireDeadline as not started
+// before the deadline, rather than exiting early because of a unit the deadline stopped, whichever of the two
+// happens first.
+func (dr *Controller) failEntry(ctx context.Context, ent *queue.Entry) {
+	if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		dr.q.SetEntryStatus(ent, queue.StatusFailed)
+		return
+	}
+
+	dr.q.FailEntry(ent)
+}
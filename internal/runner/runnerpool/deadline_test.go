@@ -0,0 +1,84 @@
+package runnerpool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_Deadline(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C: A runs until the deadline, so B and C are never started.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	q := buildQueue(t, units)
+
+	// The deadline of the run passes once A has started.
+	ctx, cancel := context.WithCancelCause(t.Context())
+	defer cancel(nil)
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(func(runCtx context.Context, u *component.Unit) error {
+			cancel(context.DeadlineExceeded)
+
+			<-runCtx.Done()
+
+			return runCtx.Err()
+		}),
+	)
+
+	err := controller.Run(ctx, logger.CreateLogger())
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cancelled := controller.Cancelled()
+	assert.Len(t, cancelled, 2)
+
+	for _, path := range []string{"B", "C"} {
+		var deadlineErr runnerpool.UnitDeadlineExceededError
+
+		require.ErrorAs(t, cancelled[path], &deadlineErr)
+		assert.Equal(t, path, deadlineErr.UnitPath)
+		assert.Equal(t, queue.StatusFailed, q.EntryByPath(path).Status)
+	}
+
+	assert.Equal(t, queue.StatusFailed, q.EntryByPath("A").Status)
+}
+
+func TestController_CancelledWithoutDeadline(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, map[string][]string{"B": {"A"}})
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(runCtx context.Context, u *component.Unit) error {
+			cancel()
+			<-runCtx.Done()
+
+			return runCtx.Err()
+		}),
+	)
+
+	require.NoError(t, controller.Run(ctx, logger.CreateLogger()))
+	assert.Empty(t, controller.Cancelled())
+}
@@ -0,0 +1,54 @@
+package runnerpool
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// WithDeadlockDetection makes the controller give up with a SchedulerDeadlockError when no unit has
+// completed for the given interval, no unit is running and units are still waiting to run.
+//
+// Running units are not considered stuck, so long applies do not trip the detector.
+// A zero or negative interval disables detection, which is the default.
+func WithDeadlockDetection(interval time.Duration) ControllerOption {
+	return func(dr *Controller) {
+		dr.deadlockInterval = interval
+	}
+}
+
+// watchdog returns a channel that fires once the deadlock interval has elapsed.
+// When deadlock detection is disabled, the returned channel never fires.
+func (dr *Controller) watchdog() <-chan time.Time {
+	if dr.deadlockInterval <= 0 {
+		return nil
+	}
+
+	return dr.clock.After(dr.deadlockInterval)
+}
+
+// detectDeadlock returns a SchedulerDeadlockError if units are waiting while nothing is running,
+// after logging every stuck unit along with the units it is waiting on.
+func (dr *Controller) detectDeadlock(l log.Logger) error {
+	p := dr.q.Progress()
+	if p.Running > 0 || p.Waiting == 0 {
+		return nil
+	}
+
+	stuck := dr.q.StuckEntries()
+
+	l.Errorf("Runner Pool Controller: no progress for %s with %d units waiting and none running", dr.deadlockInterval, len(stuck))
+
+	for _, entry := range stuck {
+		if len(entry.UnmetDependencies) == 0 {
+			l.Errorf("Runner Pool Controller: %s is waiting with no unmet dependencies", entry.Path)
+			continue
+		}
+
+		l.Errorf("Runner Pool Controller: %s is waiting on %s", entry.Path, strings.Join(entry.UnmetDependencies, ", "))
+	}
+
+	return errors.New(SchedulerDeadlockError{Interval: dr.deadlockInterval, Stuck: stuck})
+}
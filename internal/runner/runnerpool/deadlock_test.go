@@ -0,0 +1,81 @@
+package runnerpool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_DeadlockDetection(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> C, where A is never scheduled.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	q := buildQueue(t, units)
+	q.SetEntryStatus(q.EntryByPath("A"), queue.StatusPending)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithDeadlockDetection(50*time.Millisecond),
+	)
+
+	err := controller.Run(t.Context(), log.New())
+	require.Error(t, err)
+
+	var deadlockErr runnerpool.SchedulerDeadlockError
+	require.ErrorAs(t, err, &deadlockErr)
+	assert.Equal(t, []queue.StuckEntry{
+		{Path: "A", UnmetDependencies: []string{}},
+		{Path: "B", UnmetDependencies: []string{"A"}},
+		{Path: "C", UnmetDependencies: []string{"B"}},
+	}, deadlockErr.Stuck)
+}
+
+func TestController_DeadlockDetectionIgnoresRunningUnits(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "A" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithDeadlockDetection(20*time.Millisecond),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+}
@@ -0,0 +1,40 @@
+package runnerpool
+
+import (
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+)
+
+// checkDependencyChain returns an IncompleteDependencyChainError when a dependency of the unit, direct or
+// transitive, neither succeeded in the run nor is assumed to be applied, e.g. because it was excluded.
+// A quarantined or non-blocking dependency whose failure the controller ignored did not succeed.
+func (rnr *Runner) checkDependencyChain(controller *Controller, unit *component.Unit) error {
+	deps := make(map[string]bool)
+	collectDependencies(unit, deps)
+
+	var unsatisfied []string
+
+	for path := range deps {
+		if rnr.assumedApplied[path] {
+			continue
+		}
+
+		if entry := rnr.queue.EntryByPath(path); entry != nil && entry.Status == queue.StatusSucceeded &&
+			controller.ignoredFailure(path) == nil {
+			continue
+		}
+
+		unsatisfied = append(unsatisfied, path)
+	}
+
+	if len(unsatisfied) == 0 {
+		return nil
+	}
+
+	slices.Sort(unsatisfied)
+
+	return errors.New(IncompleteDependencyChainError{UnitPath: unit.Path(), Dependencies: unsatisfied})
+}
@@ -0,0 +1,111 @@
+package runnerpool_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestRunner_RequireFullDependencyChain(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	for _, name := range []string{"vpc", "db", "app"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(rootDir, name), os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(rootDir, name, "terragrunt.hcl"), nil, 0o644))
+	}
+
+	// vpc <- db <- app, with vpc assumed to be applied and db excluded from the run
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	db := component.NewUnit(filepath.Join(rootDir, "db")).WithConfig(&config.TerragruntConfig{})
+	db.AddDependency(vpc)
+
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(db)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.RequireFullDependencyChain = true
+
+	l := thlogger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		l,
+		opts,
+		component.Components{vpc, db, app},
+		runnerpool.WithAssumeAppliedExcept("app", "db"),
+		runnerpool.WithExcludeGlobs("db"),
+	)
+	require.NoError(t, err)
+
+	err = stack.Run(t.Context(), l, opts, report.NewReport())
+
+	var chainErr runnerpool.IncompleteDependencyChainError
+	require.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, filepath.Join(rootDir, "app"), chainErr.UnitPath)
+	// vpc is assumed to be applied, while db is only excluded.
+	assert.Equal(t, []string{filepath.Join(rootDir, "db")}, chainErr.Dependencies)
+}
+
+func TestRunner_RequireFullDependencyChainIgnoredFailure(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	for _, name := range []string{"vpc", "app"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(rootDir, name), os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(rootDir, name, "terragrunt.hcl"), nil, 0o644))
+	}
+
+	// vpc <- app, with vpc non-blocking and failing, as it has no Terraform files.
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(vpc)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.RequireFullDependencyChain = true
+
+	l := thlogger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		l,
+		opts,
+		component.Components{vpc, app},
+		runnerpool.WithNonBlockingUnits("vpc"),
+	)
+	require.NoError(t, err)
+
+	err = stack.Run(t.Context(), l, opts, report.NewReport())
+
+	// The ignored failure of vpc does not count as applying it.
+	var chainErr runnerpool.IncompleteDependencyChainError
+	require.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, filepath.Join(rootDir, "app"), chainErr.UnitPath)
+	assert.Equal(t, []string{filepath.Join(rootDir, "vpc")}, chainErr.Dependencies)
+}
@@ -0,0 +1,173 @@
+package runnerpool
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// WithDependencyWaitTimeout bounds how long a unit waits for the next of its dependencies to finish.
+// A unit that waits longer is failed with a DependencyWaitTimeoutError without being run, and the units
+// waiting on it exit early as with any other failure.
+//
+// The wait restarts every time one of the unit's dependencies finishes, so this is a per-edge patience limit
+// rather than a bound on the whole run: it is distinct from the timeout of a single unit run and from
+// deadlock detection, which only trips when nothing is running at all.
+// A zero or negative timeout waits forever, which is the default.
+func WithDependencyWaitTimeout(timeout time.Duration) ControllerOption {
+	return func(dr *Controller) {
+		dr.dependencyWaitTimeout = timeout
+	}
+}
+
+// dependencyWait tracks a unit that is waiting on its dependencies.
+type dependencyWait struct {
+	since time.Time
+	unmet int
+}
+
+// dependencyWaitTimer records the units currently waiting on their dependencies and returns a channel that
+// fires once the earliest of their waits times out.
+// When dependency wait timeouts are disabled or no unit is waiting, the returned channel never fires.
+func (dr *Controller) dependencyWaitTimer() <-chan time.Time {
+	if dr.dependencyWaitTimeout <= 0 {
+		return nil
+	}
+
+	var (
+		now     = dr.clock.Now()
+		waits   = make(map[string]dependencyWait)
+		nextDue time.Time
+	)
+
+	for _, entry := range dr.q.StuckEntries() {
+		if len(entry.UnmetDependencies) == 0 {
+			continue
+		}
+
+		// A unit whose dependencies made progress since the last check starts waiting again.
+		wait, ok := dr.dependencyWaits[entry.Path]
+		if !ok || wait.unmet != len(entry.UnmetDependencies) {
+			wait = dependencyWait{since: now, unmet: len(entry.UnmetDependencies)}
+		}
+
+		waits[entry.Path] = wait
+
+		if due := wait.since.Add(dr.dependencyWaitTimeout); nextDue.IsZero() || due.Before(nextDue) {
+			nextDue = due
+		}
+	}
+
+	dr.dependencyWaits = waits
+
+	if nextDue.IsZero() {
+		return nil
+	}
+
+	return dr.clock.After(nextDue.Sub(now))
+}
+
+// expireDependencyWaits fails every unit that has waited on the same dependencies for longer than the
+// dependency wait timeout.
+func (dr *Controller) expireDependencyWaits(l log.Logger, results *xsync.MapOf[string, error]) {
+	now := dr.clock.Now()
+
+	for _, stuck := range dr.q.StuckEntries() {
+		wait, ok := dr.dependencyWaits[stuck.Path]
+		if !ok || wait.unmet != len(stuck.UnmetDependencies) || now.Sub(wait.since) < dr.dependencyWaitTimeout {
+			continue
+		}
+
+		entry := dr.q.EntryByPath(stuck.Path)
+		if entry == nil {
+			continue
+		}
+
+		// The timeout of a unit this unit waits on may already have stopped it.
+		switch entry.Status { //nolint:exhaustive
+		case queue.StatusFailed, queue.StatusEarlyExit, queue.StatusSkipped:
+			continue
+		}
+
+		err := errors.New(DependencyWaitTimeoutError{
+			UnitPath:     stuck.Path,
+			Dependencies: stuck.UnmetDependencies,
+			Timeout:      dr.dependencyWaitTimeout,
+		})
+
+		l.Errorf("Runner Pool Controller: %v", err)
+
+		dr.storeResult(results, stuck.Path, err)
+		dr.cancelGroup(stuck.Path)
+		dr.q.FailEntry(entry)
+
+		if unit := dr.unit(stuck.Path); unit != nil {
+			dr.logProgress(l, unit, "timed out")
+		}
+
+		delete(dr.dependencyWaits, stuck.Path)
+	}
+}
+
+// dependencyWaitTimeouts returns the dependency wait timeouts found in the given run error, keyed by unit path.
+func dependencyWaitTimeouts(err error) map[string]DependencyWaitTimeoutError {
+	timeouts := make(map[string]DependencyWaitTimeoutError)
+
+	var collect func(err error)
+
+	collect = func(err error) {
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range multi.Unwrap() {
+				collect(wrapped)
+			}
+
+			return
+		}
+
+		// In first failure mode, the errors of the other units are only attached as suppressed context.
+		var first FirstFailureError
+		if errors.As(err, &first) {
+			collect(first.Err)
+
+			for _, suppressed := range first.Suppressed {
+				collect(suppressed)
+			}
+
+			return
+		}
+
+		// Grouped errors are only attached to their group, as are the errors omitted from it.
+		var group ErrorGroupError
+		if errors.As(err, &group) {
+			for _, wrapped := range group.Errors {
+				collect(wrapped)
+			}
+
+			return
+		}
+
+		// The errors past the maximum number of reported errors are only attached to a summary.
+		var omitted OmittedErrorsError
+		if errors.As(err, &omitted) {
+			for _, wrapped := range omitted.Errors {
+				collect(wrapped)
+			}
+
+			return
+		}
+
+		var timeout DependencyWaitTimeoutError
+		if errors.As(err, &timeout) {
+			timeouts[timeout.UnitPath] = timeout
+		}
+	}
+
+	if err != nil {
+		collect(err)
+	}
+
+	return timeouts
+}
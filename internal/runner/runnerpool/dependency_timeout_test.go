@@ -0,0 +1,104 @@
+package runnerpool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_DependencyWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> C, and D on its own, where A outlives the patience of B.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	release := make(chan struct{})
+	errCh := make(chan error, 1)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "A" {
+			<-release
+		}
+
+		return nil
+	}
+
+	q := buildQueue(t, units)
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithDependencyWaitTimeout(50*time.Millisecond),
+	)
+
+	go func() {
+		errCh <- controller.Run(t.Context(), log.New())
+	}()
+
+	require.Eventually(t, func() bool {
+		status, _ := q.EntryStatus("B")
+
+		return status == queue.StatusFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+
+	err := <-errCh
+	require.Error(t, err)
+
+	var timeoutErr runnerpool.DependencyWaitTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, "B", timeoutErr.UnitPath)
+	assert.Equal(t, []string{"A"}, timeoutErr.Dependencies)
+	assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("A").Status)
+	assert.Equal(t, queue.StatusEarlyExit, q.EntryByPath("C").Status)
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("D").Status)
+}
+
+func TestController_DependencyWaitTimeoutRestartsOnProgress(t *testing.T) {
+	t.Parallel()
+
+	// A -> C <- B, where each dependency finishes within the patience of C but both together do not.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"C": {"A", "B"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		switch u.Path() {
+		case "A":
+			time.Sleep(200 * time.Millisecond)
+		case "B":
+			time.Sleep(400 * time.Millisecond)
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithDependencyWaitTimeout(300*time.Millisecond),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+}
@@ -0,0 +1,63 @@
+package runnerpool
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+)
+
+// ExpectedDurationFunc returns how long a unit is expected to run, or zero when there is no estimate.
+type ExpectedDurationFunc func(unit *component.Unit) time.Duration
+
+// WithExpectedDurations starts the ready units with the longest expected duration first.
+//
+// This is the longest processing time heuristic of list scheduling: under limited parallelism, starting the
+// long poles first shortens the total run time. Units without an estimate start after the ones with an
+// estimate, and units with the same estimate start in path order.
+func WithExpectedDurations(expected ExpectedDurationFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.expectedDuration = expected
+	}
+}
+
+// ExpectedDurationsFromReport estimates the duration of every unit from the duration of its succeeded
+// run in a prior JSON report, whose run names are relative to the given working directory.
+func ExpectedDurationsFromReport(runs report.JSONRuns, workingDir string) ExpectedDurationFunc {
+	durations := runs.Durations()
+
+	return func(unit *component.Unit) time.Duration {
+		name := unit.Path()
+		if rel, err := filepath.Rel(workingDir, unit.Path()); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+
+		return durations[name]
+	}
+}
+
+// longestFirst orders the given ready entries by decreasing expected duration, then by path.
+func (dr *Controller) longestFirst(entries []*queue.Entry) []*queue.Entry {
+	expected := make(map[string]time.Duration, len(entries))
+
+	for _, e := range entries {
+		if unit := dr.unit(e.Component.Path()); unit != nil {
+			expected[e.Component.Path()] = dr.expectedDuration(unit)
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b *queue.Entry) int {
+		if c := cmp.Compare(expected[b.Component.Path()], expected[a.Component.Path()]); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Component.Path(), b.Component.Path())
+	})
+
+	return entries
+}
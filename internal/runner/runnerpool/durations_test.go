@@ -0,0 +1,58 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_ExpectedDurations(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D", "E"}, nil)
+
+	started := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := report.JSONRuns{
+		{Name: "B", Result: string(report.ResultSucceeded), Started: started, Ended: started.Add(3 * time.Minute)},
+		{Name: "D", Result: string(report.ResultSucceeded), Started: started, Ended: started.Add(time.Minute)},
+		{Name: "E", Result: string(report.ResultSucceeded), Started: started, Ended: started.Add(time.Minute)},
+		// Failed runs are not representative of how long a unit takes.
+		{Name: "C", Result: string(report.ResultFailed), Started: started, Ended: started.Add(time.Hour)},
+	}
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		order = append(order, u.Path())
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithExpectedDurations(runnerpool.ExpectedDurationsFromReport(runs, ".")),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+
+	// Longest first, ties and units without an estimate in path order.
+	assert.Equal(t, []string{"B", "D", "E", "A", "C"}, order)
+}
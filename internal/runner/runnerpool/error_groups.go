@@ -0,0 +1,93 @@
+package runnerpool
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+)
+
+// ErrorClassFunc names the class of an error returned by a unit, errors of the same class are grouped together.
+type ErrorClassFunc func(err error) string
+
+// WithErrorGroups makes Run group the collected errors by class, e.g. to make a run where hundreds of units
+// fail for a handful of reasons easier to triage. Run then returns a MultiError holding an ErrorGroupError
+// for every class, in the order the classes first appear, while Errors still returns the flat list.
+//
+// A nil function classifies errors with ErrorTypeClass. Severity ordering applies before grouping, and the
+// maximum number of reported errors applies to each group.
+func WithErrorGroups(classOf ErrorClassFunc) ControllerOption {
+	return func(dr *Controller) {
+		if classOf == nil {
+			classOf = ErrorTypeClass
+		}
+
+		dr.errorClass = classOf
+	}
+}
+
+// wrapperPackages holds the packages whose errors only wrap other errors, and do not classify them.
+var wrapperPackages = map[string]bool{
+	"fmt":                         true,
+	"errors":                      true,
+	"github.com/go-errors/errors": true,
+}
+
+// ErrorTypeClass classifies an error by the Go type of the first error of its chain that is not a generic
+// wrapper, e.g. "runnerpool.UnitEarlyExitError", so that the unit path attribution and stack traces added
+// on the way up do not hide the cause. An error made only of wrappers is classified by its innermost type.
+func ErrorTypeClass(err error) string {
+	innermost := err
+
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		typ := reflect.TypeOf(cause)
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if !wrapperPackages[typ.PkgPath()] {
+			return fmt.Sprintf("%T", cause)
+		}
+
+		innermost = cause
+	}
+
+	return fmt.Sprintf("%T", innermost)
+}
+
+// groupErrors returns the errors of the collector grouped by the key keyOf returns for the error at the given
+// index of the collector, in the order the keys first appear, each group ordered by severity and capped to the
+// maximum number of reported errors.
+func (dr *Controller) groupErrors(errCollector *errors.MultiError, keyOf func(i int, err error) string) *errors.MultiError {
+	if errCollector.Len() == 0 {
+		return errCollector
+	}
+
+	var (
+		keys    []string
+		members = make(map[string]*errors.MultiError)
+	)
+
+	for i, err := range errCollector.WrappedErrors() {
+		key := keyOf(i, err)
+		if _, ok := members[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		members[key] = members[key].Append(err)
+	}
+
+	grouped := &errors.MultiError{}
+
+	for _, key := range keys {
+		errs := members[key]
+
+		grouped = grouped.Append(errors.New(ErrorGroupError{
+			Class:  key,
+			Count:  errs.Len(),
+			Errors: dr.capErrors(dr.sortBySeverity(errs)).WrappedErrors(),
+		}))
+	}
+
+	return grouped
+}
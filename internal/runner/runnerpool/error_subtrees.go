@@ -0,0 +1,100 @@
+package runnerpool
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SubtreeFunc names the subtree of the stack the unit at the given path belongs to, the errors of the units
+// of the same subtree are grouped together.
+type SubtreeFunc func(path string) string
+
+// WithErrorSubtrees makes Run group the collected errors by the subtree of the stack of the units they belong
+// to, e.g. per account directory, to see which part of a large stack is broken. Run then returns a MultiError
+// holding an ErrorGroupError named after every subtree, in the order the subtrees first appear, while Errors
+// still returns the flat list.
+//
+// A nil function groups the units by their top-level directory below the deepest directory holding all the
+// units of the run, see TopLevelSubtrees. Severity ordering applies within each subtree, and the maximum
+// number of reported errors applies to each group. Grouping by subtree takes precedence over WithErrorGroups.
+func WithErrorSubtrees(subtreeOf SubtreeFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.groupSubtrees = true
+		dr.errorSubtree = subtreeOf
+	}
+}
+
+// TopLevelSubtrees returns a SubtreeFunc naming the subtree of a unit after its top-level directory below the
+// deepest directory holding all the given unit paths. For example, the units "live/prod/vpc", "live/prod/app"
+// and "live/dev/vpc" belong to the "prod" and "dev" subtrees.
+func TopLevelSubtrees(paths []string) SubtreeFunc {
+	dirs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		dirs = append(dirs, filepath.Dir(filepath.Clean(path)))
+	}
+
+	root := commonDir(dirs)
+
+	return func(path string) string {
+		rel, err := filepath.Rel(root, filepath.Clean(path))
+		if err != nil {
+			return path
+		}
+
+		top, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+
+		return top
+	}
+}
+
+// commonDir returns the deepest directory shared by all the given cleaned directories.
+func commonDir(dirs []string) string {
+	if len(dirs) == 0 {
+		return "."
+	}
+
+	common := strings.Split(dirs[0], string(filepath.Separator))
+
+	for _, dir := range dirs[1:] {
+		parts := strings.Split(dir, string(filepath.Separator))
+
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+
+		common = common[:n]
+	}
+
+	if len(common) == 0 {
+		return "."
+	}
+
+	// Splitting an absolute path leaves an empty first element for the root.
+	if len(common) == 1 && common[0] == "" {
+		return string(filepath.Separator)
+	}
+
+	return strings.Join(common, string(filepath.Separator))
+}
+
+// subtreeFunc returns the function naming the subtree of the units the errors of the run are grouped by, or
+// nil when the errors are not grouped by subtree.
+func (dr *Controller) subtreeFunc() SubtreeFunc {
+	if !dr.groupSubtrees {
+		return nil
+	}
+
+	if dr.errorSubtree != nil {
+		return dr.errorSubtree
+	}
+
+	entries := dr.q.Snapshot()
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		paths = append(paths, entry.Component.Path())
+	}
+
+	return TopLevelSubtrees(paths)
+}
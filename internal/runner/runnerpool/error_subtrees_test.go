@@ -0,0 +1,139 @@
+package runnerpool_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestTopLevelSubtrees(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "live")
+
+	subtreeOf := runnerpool.TopLevelSubtrees([]string{
+		filepath.Join(root, "prod", "vpc"),
+		filepath.Join(root, "prod", "app"),
+		filepath.Join(root, "dev", "vpc"),
+		filepath.Join(root, "shared"),
+	})
+
+	assert.Equal(t, "prod", subtreeOf(filepath.Join(root, "prod", "vpc")))
+	assert.Equal(t, "dev", subtreeOf(filepath.Join(root, "dev", "vpc")))
+	assert.Equal(t, "shared", subtreeOf(filepath.Join(root, "shared")))
+
+	// A single unit is its own subtree.
+	assert.Equal(t, "vpc", runnerpool.TopLevelSubtrees([]string{"vpc"})("vpc"))
+}
+
+func TestController_ErrorSubtrees(t *testing.T) {
+	t.Parallel()
+
+	// prod/vpc <- prod/app, with prod/vpc and dev/vpc failing and prod/app exiting early.
+	units := buildComponentUnits(
+		[]string{"prod/vpc", "prod/app", "dev/vpc", "dev/app"},
+		map[string][]string{
+			"prod/app": {"prod/vpc"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if filepath.Base(u.Path()) == "vpc" {
+			return errors.New(u.Path() + " failed")
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithErrorSubtrees(nil),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	var multiErr *errors.MultiError
+	require.ErrorAs(t, err, &multiErr)
+
+	groups := map[string]runnerpool.ErrorGroupError{}
+
+	for _, wrapped := range multiErr.WrappedErrors() {
+		var group runnerpool.ErrorGroupError
+		require.ErrorAs(t, wrapped, &group)
+
+		groups[group.Class] = group
+	}
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, 2, groups["prod"].Count)
+	assert.Equal(t, 1, groups["dev"].Count)
+	assert.ErrorContains(t, groups["dev"].Errors[0], "dev/vpc failed")
+
+	var earlyExit runnerpool.UnitEarlyExitError
+	require.ErrorAs(t, err, &earlyExit)
+	assert.Equal(t, "prod/app", earlyExit.UnitPath)
+	assert.Contains(t, err.Error(), "prod (2):")
+	assert.Len(t, controller.Errors(), 3)
+
+	// A custom function names the subtrees.
+	controller = runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithErrorSubtrees(func(path string) string { return "all" }),
+	)
+
+	err = controller.Run(t.Context(), logger.CreateLogger())
+	require.ErrorAs(t, err, &multiErr)
+	require.Len(t, multiErr.WrappedErrors(), 1)
+	assert.Contains(t, err.Error(), "all (3):")
+}
+
+func TestRunner_GroupErrorsBySubtree(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	// The units have no configuration, so prod/vpc and dev/vpc fail and prod/app exits early.
+	prodVpc := component.NewUnit(filepath.Join(rootDir, "prod", "vpc")).WithConfig(&config.TerragruntConfig{})
+	prodApp := component.NewUnit(filepath.Join(rootDir, "prod", "app")).WithConfig(&config.TerragruntConfig{})
+	devVpc := component.NewUnit(filepath.Join(rootDir, "dev", "vpc")).WithConfig(&config.TerragruntConfig{})
+	prodApp.AddDependency(prodVpc)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.GroupErrorsBySubtree = true
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{prodVpc, prodApp, devVpc})
+	require.NoError(t, err)
+
+	err = stack.Run(t.Context(), l, opts, report.NewReport())
+	require.Error(t, err)
+
+	var group runnerpool.ErrorGroupError
+	require.ErrorAs(t, err, &group)
+	assert.Contains(t, err.Error(), "prod (2):")
+	assert.Contains(t, err.Error(), "dev (1):")
+}
@@ -1,7 +1,12 @@
 package runnerpool
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gruntwork-io/terragrunt/internal/errors"
 	"github.com/gruntwork-io/terragrunt/internal/queue"
@@ -44,10 +49,195 @@ func NewUnitFailedError(unitPath string) error {
 	return errors.New(UnitFailedError{UnitPath: unitPath})
 }
 
+// FirstFailureError holds the error of the unit that failed first, with the errors of the other failed
+// units attached as suppressed context. Only the first error is unwrapped.
+type FirstFailureError struct {
+	Err        error
+	Suppressed []error
+}
+
+func (e FirstFailureError) Error() string {
+	if len(e.Suppressed) == 0 {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s (and %d more failures)", e.Err, len(e.Suppressed))
+}
+
+func (e FirstFailureError) Unwrap() error {
+	return e.Err
+}
+
+// OmittedErrorsError stands in for the errors left out of the errors returned by a run, past the maximum
+// number of reported errors. The omitted errors are not unwrapped, so that they are not printed.
+type OmittedErrorsError struct {
+	Errors []error
+}
+
+func (e OmittedErrorsError) Error() string {
+	return fmt.Sprintf("and %d more errors", len(e.Errors))
+}
+
+// ErrorGroupError holds the errors of a run that share a class, or a subtree of the stack, along with how many
+// there were before capping.
+// The errors are not unwrapped, so that they are printed as one section instead of being flattened, but
+// errors.Is and errors.As still match any of them.
+type ErrorGroupError struct {
+	Class  string
+	Errors []error
+	Count  int
+}
+
+func (e ErrorGroupError) Error() string {
+	members := make([]string, 0, len(e.Errors))
+
+	for _, err := range e.Errors {
+		lines := strings.Split(strings.ReplaceAll(err.Error(), "\r\n", "\n"), "\n")
+		members = append(members, "- "+strings.Join(lines, "\n  "))
+	}
+
+	return fmt.Sprintf("%s (%d):\n%s", e.Class, e.Count, strings.Join(members, "\n"))
+}
+
+func (e ErrorGroupError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e ErrorGroupError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InvalidParallelismScheduleError is returned when the parallelism schedule holds a value that is not positive.
+type InvalidParallelismScheduleError struct {
+	Wave        int
+	Parallelism int
+}
+
+func (e InvalidParallelismScheduleError) Error() string {
+	return fmt.Sprintf("invalid parallelism %d for wave %d, the parallelism of every wave must be positive", e.Parallelism, e.Wave)
+}
+
+// CanaryFailedError is returned when the canary unit of a dependency wave failed, so the rest of the wave
+// was not run.
+type CanaryFailedError struct {
+	UnitPath string
+	Wave     int
+}
+
+func (e CanaryFailedError) Error() string {
+	return fmt.Sprintf("canary unit '%s' of wave %d failed, not running the rest of the wave", e.UnitPath, e.Wave)
+}
+
+// EmptyRunError is returned when a run has no unit left to run and empty runs are not allowed.
+type EmptyRunError struct {
+	WorkingDir string
+}
+
+func (e EmptyRunError) Error() string {
+	return fmt.Sprintf("no units to run in %s, check the include and exclude settings of the run", e.WorkingDir)
+}
+
+// OrphanedEntriesError is returned by Controller.Validate when queue entries have no unit to run.
+type OrphanedEntriesError struct {
+	Paths []string
+}
+
+func (e OrphanedEntriesError) Error() string {
+	return fmt.Sprintf("queue entries without a matching unit: %s", strings.Join(e.Paths, ", "))
+}
+
+// CrossPartitionDependencyError is returned when a unit depends on a unit of another partition
+// and cross-partition dependencies are not allowed.
+type CrossPartitionDependencyError struct {
+	UnitPath            string
+	UnitPartition       string
+	DependencyPath      string
+	DependencyPartition string
+}
+
+func (e CrossPartitionDependencyError) Error() string {
+	return fmt.Sprintf("Unit '%s' in partition '%s' depends on '%s' in partition '%s'",
+		e.UnitPath, e.UnitPartition, e.DependencyPath, e.DependencyPartition)
+}
+
+// SchedulerDeadlockError is returned when units are waiting to run but none can make progress.
+type SchedulerDeadlockError struct {
+	Stuck    []queue.StuckEntry
+	Interval time.Duration
+}
+
+func (e SchedulerDeadlockError) Error() string {
+	stuck := make([]string, 0, len(e.Stuck))
+
+	for _, entry := range e.Stuck {
+		if len(entry.UnmetDependencies) == 0 {
+			stuck = append(stuck, entry.Path)
+			continue
+		}
+
+		stuck = append(stuck, fmt.Sprintf("%s (waiting on %s)", entry.Path, strings.Join(entry.UnmetDependencies, ", ")))
+	}
+
+	return fmt.Sprintf("scheduler made no progress for %s, stuck units: %s", e.Interval, strings.Join(stuck, "; "))
+}
+
+// DependencyWaitTimeoutError is the error of a unit that waited on its dependencies for longer than the
+// dependency wait timeout.
+type DependencyWaitTimeoutError struct {
+	UnitPath     string
+	Dependencies []string
+	Timeout      time.Duration
+}
+
+func (e DependencyWaitTimeoutError) Error() string {
+	return fmt.Sprintf("Unit '%s' timed out after waiting %s on %s", e.UnitPath, e.Timeout, strings.Join(e.Dependencies, ", "))
+}
+
+// UnitCancelledError is the error of a unit that was not run because the run was cancelled while it waited
+// for a concurrency slot.
+type UnitCancelledError struct {
+	Err      error
+	UnitPath string
+}
+
+func (e UnitCancelledError) Error() string {
+	return fmt.Sprintf("Unit '%s' was not run, the run was cancelled while it waited for a slot: %v", e.UnitPath, e.Err)
+}
+
+func (e UnitCancelledError) Unwrap() error {
+	return e.Err
+}
+
+// UnitDeadlineExceededError is the error of a unit that was not started because the deadline of the run
+// passed first.
+type UnitDeadlineExceededError struct {
+	UnitPath string
+}
+
+func (e UnitDeadlineExceededError) Error() string {
+	return fmt.Sprintf("Unit '%s' was not run, the deadline of the run passed before it started", e.UnitPath)
+}
+
+func (e UnitDeadlineExceededError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // findFailedDependency finds the first failed dependency for a given entry.
 func findFailedDependency(entry *queue.Entry, q *queue.Queue) string {
 	for _, dep := range entry.Component.Dependencies() {
-		for _, e := range q.Entries {
+		for _, e := range q.Snapshot() {
 			if e.Component.Path() == dep.Path() {
 				if e.Status == queue.StatusFailed {
 					return dep.Path()
@@ -58,3 +248,44 @@ func findFailedDependency(entry *queue.Entry, q *queue.Queue) string {
 
 	return ""
 }
+
+// GroupCancelledError is the error of a unit whose cancellation group was cancelled after another unit of the
+// group failed.
+type GroupCancelledError struct {
+	Group      string
+	FailedUnit string
+}
+
+func (e GroupCancelledError) Error() string {
+	return fmt.Sprintf("cancelled because unit '%s' of cancellation group '%s' failed", e.FailedUnit, e.Group)
+}
+
+// IncompleteDependencyChainError is the error of a unit that is not applied because some of its dependencies,
+// direct or transitive, neither succeeded in the run nor are assumed to be applied.
+type IncompleteDependencyChainError struct {
+	UnitPath     string
+	Dependencies []string
+}
+
+func (e IncompleteDependencyChainError) Error() string {
+	return fmt.Sprintf("not applying unit '%s': its dependencies %s were neither applied by the run nor assumed to be applied",
+		e.UnitPath, strings.Join(e.Dependencies, ", "))
+}
+
+// MissingPlanFilesError is returned before applying the plan files saved to the output folder when some of the
+// units to apply have no readable plan file.
+type MissingPlanFilesError struct {
+	// PlanFiles are the expected plan files that are missing or unreadable, keyed by unit path.
+	PlanFiles map[string]string
+}
+
+func (e MissingPlanFilesError) Error() string {
+	units := slices.Sorted(maps.Keys(e.PlanFiles))
+
+	missing := make([]string, 0, len(units))
+	for _, unit := range units {
+		missing = append(missing, fmt.Sprintf("%s (%s)", unit, e.PlanFiles[unit]))
+	}
+
+	return fmt.Sprintf("missing plan files for %d units: %s", len(missing), strings.Join(missing, ", "))
+}
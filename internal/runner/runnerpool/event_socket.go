@@ -0,0 +1,129 @@
+package runnerpool
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+const (
+	// eventSocketDialTimeout bounds how long connecting to the event socket may take.
+	eventSocketDialTimeout = 5 * time.Second
+	// eventSocketWriteTimeout bounds how long writing an event may block on a slow reader.
+	eventSocketWriteTimeout = 5 * time.Second
+)
+
+// WithEventSocket streams the start and finish of every unit to the Unix domain socket at the given path as
+// they happen, one JSON encoded TimelineEvent per line, e.g. for IDEs and external dashboards. The finish
+// event of a failed unit carries its error.
+//
+// The socket is optional: when it cannot be connected to, or a write to it fails, a warning is logged and
+// events are no longer streamed, but the run carries on.
+func WithEventSocket(path string) ControllerOption {
+	return func(dr *Controller) {
+		dr.eventSocket = path
+	}
+}
+
+// eventStream writes unit events to a connected event socket.
+type eventStream struct {
+	start time.Time
+	clock Clock
+	conn  net.Conn
+	enc   *json.Encoder
+	l     log.Logger
+	path  string
+	seq   int
+	mu    sync.Mutex
+}
+
+// streamEvents wraps the runner so that it streams unit events to the event socket, when one is set.
+// The returned function closes the connection and must be called once the run is over.
+func (dr *Controller) streamEvents(ctx context.Context, l log.Logger, runner UnitRunner) (UnitRunner, func()) {
+	if dr.eventSocket == "" {
+		return runner, func() {}
+	}
+
+	dialer := &net.Dialer{Timeout: eventSocketDialTimeout}
+
+	conn, err := dialer.DialContext(ctx, "unix", dr.eventSocket)
+	if err != nil {
+		l.Warnf("Not streaming unit events, failed to connect to event socket %s: %v", dr.eventSocket, err)
+		return runner, func() {}
+	}
+
+	stream := &eventStream{
+		start: dr.clock.Now(),
+		clock: dr.clock,
+		conn:  conn,
+		enc:   json.NewEncoder(conn),
+		l:     l,
+		path:  dr.eventSocket,
+	}
+
+	return func(ctx context.Context, u *component.Unit) error {
+		stream.send(u.Path(), TimelineEventStart, nil)
+
+		err := runner(ctx, u)
+
+		stream.send(u.Path(), TimelineEventFinish, err)
+
+		return err
+	}, stream.close
+}
+
+// send writes an event to the socket, disabling the stream if the write fails.
+func (s *eventStream) send(unit string, kind TimelineEventKind, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return
+	}
+
+	event := TimelineEvent{
+		Unit:   unit,
+		Kind:   kind,
+		Offset: s.clock.Now().Sub(s.start),
+		Seq:    s.seq,
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	s.seq++
+
+	// Write deadlines are enforced by the network stack, so they always follow the real clock.
+	writeErr := s.conn.SetWriteDeadline(time.Now().Add(eventSocketWriteTimeout))
+	if writeErr == nil {
+		writeErr = s.enc.Encode(event)
+	}
+
+	if writeErr != nil {
+		s.l.Warnf("Stopped streaming unit events, failed to write to event socket %s: %v", s.path, writeErr)
+		s.conn.Close() //nolint:errcheck
+		s.conn = nil
+	}
+}
+
+// close closes the connection to the socket, if it is still open.
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return
+	}
+
+	if err := s.conn.Close(); err != nil {
+		s.l.Warnf("Failed to close event socket %s: %v", s.path, err)
+	}
+
+	s.conn = nil
+}
@@ -0,0 +1,109 @@
+package runnerpool_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_EventSocket(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "events.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	eventsCh := make(chan []runnerpool.TimelineEvent, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			eventsCh <- nil
+			return
+		}
+		defer conn.Close()
+
+		var events []runnerpool.TimelineEvent
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var event runnerpool.TimelineEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				events = append(events, event)
+			}
+		}
+
+		eventsCh <- events
+	}()
+
+	// A -> B, with B failing.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "B" {
+			return errors.New("B failed")
+		}
+
+		return nil
+	}
+
+	err = runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithEventSocket(socketPath),
+	).Run(t.Context(), logger.CreateLogger())
+	require.Error(t, err)
+
+	events := <-eventsCh
+	require.Len(t, events, 4)
+
+	for i, event := range events {
+		assert.Equal(t, i, event.Seq)
+	}
+
+	assert.Equal(t, runnerpool.TimelineEvent{Unit: "A", Kind: runnerpool.TimelineEventStart}, withoutOffset(events[0]))
+	assert.Equal(t, runnerpool.TimelineEvent{Unit: "A", Kind: runnerpool.TimelineEventFinish, Seq: 1}, withoutOffset(events[1]))
+	assert.Equal(t, runnerpool.TimelineEvent{Unit: "B", Kind: runnerpool.TimelineEventStart, Seq: 2}, withoutOffset(events[2]))
+	assert.Equal(t, "B", events[3].Unit)
+	assert.Equal(t, runnerpool.TimelineEventFinish, events[3].Kind)
+	assert.Contains(t, events[3].Error, "B failed")
+}
+
+func TestController_EventSocketUnavailable(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+		runnerpool.WithEventSocket(filepath.Join(t.TempDir(), "missing.sock")),
+	).Run(t.Context(), logger.CreateLogger())
+	require.NoError(t, err)
+}
+
+func withoutOffset(event runnerpool.TimelineEvent) runnerpool.TimelineEvent {
+	event.Offset = 0
+	return event
+}
@@ -0,0 +1,34 @@
+package runnerpool
+
+// WithExclusiveUnits marks the units at the given paths as exclusive.
+//
+// An exclusive unit never runs concurrently with any other unit: it waits for every running unit to finish
+// before it starts, and no other unit starts until it has finished. Non-exclusive units keep sharing the
+// regular concurrency slots.
+func WithExclusiveUnits(paths ...string) ControllerOption {
+	return func(dr *Controller) {
+		if dr.exclusive == nil {
+			dr.exclusive = make(map[string]bool, len(paths))
+		}
+
+		for _, path := range paths {
+			dr.exclusive[path] = true
+		}
+	}
+}
+
+// acquireGate blocks until the unit at the given path is allowed to run and returns a function releasing it.
+//
+// The gate is layered over the concurrency semaphore: shared units hold a read lock and exclusive units a
+// write lock. Since a unit only waits on the gate after taking its semaphore slot, and holders of the gate
+// never wait on the semaphore, the two cannot deadlock.
+func (dr *Controller) acquireGate(path string) func() {
+	if dr.exclusive[path] {
+		dr.gate.Lock()
+		return dr.gate.Unlock
+	}
+
+	dr.gate.RLock()
+
+	return dr.gate.RUnlock
+}
@@ -0,0 +1,71 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_ExclusiveUnitRunsAlone(t *testing.T) {
+	t.Parallel()
+
+	// All units are independent, so without the exclusive gate they would all run at once.
+	units := buildComponentUnits([]string{"A", "B", "migration", "C", "D"}, nil)
+
+	var (
+		running            atomic.Int32
+		maxRunning         atomic.Int32
+		mu                 sync.Mutex
+		concurrentWithExcl bool
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			prev := maxRunning.Load()
+			if current <= prev || maxRunning.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+
+		if u.Path() == "migration" && current != 1 {
+			mu.Lock()
+			concurrentWithExcl = true
+			mu.Unlock()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		if u.Path() == "migration" && running.Load() != 1 {
+			mu.Lock()
+			concurrentWithExcl = true
+			mu.Unlock()
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(len(units)),
+		runnerpool.WithExclusiveUnits("migration"),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.False(t, concurrentWithExcl, "exclusive unit ran concurrently with other units")
+	assert.Greater(t, maxRunning.Load(), int32(1), "shared units should still run concurrently")
+}
@@ -0,0 +1,128 @@
+package runnerpool
+
+import (
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+)
+
+// FailureDetail explains the outcome of a unit of the last run, see FailureReason.
+type FailureDetail struct {
+	// Err is the error the unit returned, if it ran and failed.
+	Err error
+	// RootCause is the error of the unit that caused the failure, which is Err when the unit failed on its
+	// own, and the error of the last unit of Chain when the unit exited early because of an upstream failure.
+	RootCause error
+	// Path is the path of the unit.
+	Path string
+	// Chain holds the paths of the upstream units the failure propagated through, nearest first, ending
+	// with the unit that failed on its own. It is empty when the unit failed on its own, and may be empty
+	// for a unit that exited early because the run stopped, e.g. in fail fast mode.
+	Chain []string
+	// Status is the final status of the unit.
+	Status queue.Status
+	// Ignored is set when the unit is quarantined or non-blocking and its failure was ignored. Its Status
+	// is then StatusSucceeded, so that its dependents ran, while Err holds its error.
+	Ignored bool
+}
+
+// FailureReason returns the final status of the unit at the given path in the last run, its own error if
+// it failed, and, if it exited early, the chain of upstream failures that caused it, reconstructed from the
+// recorded errors by walking its dependencies, or its dependents for destroy commands.
+//
+// It returns an error wrapping queue.ErrEntryNotFound when the unit is not part of the run.
+func (dr *Controller) FailureReason(path string) (*FailureDetail, error) {
+	entry := dr.q.EntryByPath(path)
+	if entry == nil {
+		return nil, errors.Errorf("%w: %s", queue.ErrEntryNotFound, path)
+	}
+
+	dr.mu.Lock()
+	unitErrs := dr.unitErrs
+	ignoredErr := dr.ignoredErrs[path]
+	dr.mu.Unlock()
+
+	detail := &FailureDetail{
+		Path:   path,
+		Status: entry.Status,
+		Err:    unitErrs[path],
+		Chain:  []string{},
+	}
+
+	if ignoredErr != nil {
+		detail.Err = ignoredErr
+		detail.Ignored = true
+	}
+
+	if detail.Err != nil {
+		detail.RootCause = detail.Err
+		return detail, nil
+	}
+
+	if entry.Status != queue.StatusFailed && entry.Status != queue.StatusEarlyExit {
+		return detail, nil
+	}
+
+	visited := map[string]bool{path: true}
+
+	for current := entry; ; {
+		upstream := dr.failedUpstream(current, visited)
+		if upstream == nil {
+			break
+		}
+
+		upstreamPath := upstream.Component.Path()
+		visited[upstreamPath] = true
+		detail.Chain = append(detail.Chain, upstreamPath)
+
+		if err := unitErrs[upstreamPath]; err != nil {
+			detail.RootCause = err
+			break
+		}
+
+		current = upstream
+	}
+
+	return detail, nil
+}
+
+// failedUpstream returns the entry the failure of the given entry propagated from: one of its failed or
+// early exited dependencies, or dependents for destroy commands, not visited yet, preferring the failed
+// ones. It returns nil when there is none.
+func (dr *Controller) failedUpstream(entry *queue.Entry, visited map[string]bool) *queue.Entry {
+	var candidates []*queue.Entry
+
+	if entry.IsUp() {
+		for _, dep := range entry.Component.Dependencies() {
+			if e := dr.q.EntryByPath(dep.Path()); e != nil {
+				candidates = append(candidates, e)
+			}
+		}
+	} else {
+		for _, e := range dr.q.Snapshot() {
+			for _, dep := range e.Component.Dependencies() {
+				if dep.Path() == entry.Component.Path() {
+					candidates = append(candidates, e)
+					break
+				}
+			}
+		}
+	}
+
+	var fallback *queue.Entry
+
+	for _, candidate := range candidates {
+		if visited[candidate.Component.Path()] {
+			continue
+		}
+
+		if candidate.Status == queue.StatusFailed {
+			return candidate
+		}
+
+		if candidate.Status == queue.StatusEarlyExit && fallback == nil {
+			fallback = candidate
+		}
+	}
+
+	return fallback
+}
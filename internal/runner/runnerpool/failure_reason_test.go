@@ -0,0 +1,67 @@
+package runnerpool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_FailureReason(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C, and D on its own: A fails, so B and C exit early.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	errA := errors.New("A failed")
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			if u.Path() == "A" {
+				return errA
+			}
+
+			return nil
+		}),
+	)
+
+	require.Error(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	detail, err := controller.FailureReason("A")
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusFailed, detail.Status)
+	assert.ErrorIs(t, detail.Err, errA)
+	assert.ErrorIs(t, detail.RootCause, errA)
+	assert.Empty(t, detail.Chain)
+
+	detail, err = controller.FailureReason("C")
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusEarlyExit, detail.Status)
+	assert.NoError(t, detail.Err)
+	assert.Equal(t, []string{"B", "A"}, detail.Chain)
+	assert.ErrorIs(t, detail.RootCause, errA)
+
+	detail, err = controller.FailureReason("D")
+	require.NoError(t, err)
+	assert.Equal(t, queue.StatusSucceeded, detail.Status)
+	assert.NoError(t, detail.RootCause)
+	assert.Empty(t, detail.Chain)
+
+	_, err = controller.FailureReason("missing")
+	require.ErrorIs(t, err, queue.ErrEntryNotFound)
+}
@@ -0,0 +1,58 @@
+package runnerpool
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// WithFlakyHunt shuffles the order in which ready units are dispatched and picks a random parallelism between
+// 1 and the width of the widest dependency wave, capped by the concurrency of the controller, to surface
+// stacks that silently rely on a dispatch order or on units running one at a time.
+//
+// Both are derived from the seed, which is logged when the run starts, so a failure can be reproduced by
+// running again with the same seed. A parallelism schedule still sets the parallelism of each wave.
+func WithFlakyHunt(seed int64) ControllerOption {
+	return func(dr *Controller) {
+		dr.flakyHunt = true
+		dr.flakyHuntSeed = seed
+	}
+}
+
+// initFlakyHunt ranks the entries of the queue for dispatch and resizes the semaphore bounding the run to the
+// random parallelism of the flaky hunt, when the flaky hunt is on.
+func (dr *Controller) initFlakyHunt(l log.Logger) {
+	if !dr.flakyHunt {
+		return
+	}
+
+	seed := uint64(dr.flakyHuntSeed) //nolint:gosec
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	entries := dr.q.Snapshot()
+
+	dr.dispatchRank = make(map[string]int, len(entries))
+
+	for i, rank := range rng.Perm(len(entries)) {
+		dr.dispatchRank[entries[i].Component.Path()] = rank
+	}
+
+	width := autoParallelism(dr.q.Waves(), dr.slots.size(), len(entries))
+	parallelism := 1 + rng.IntN(width)
+
+	l.Infof("Hunting for flaky units with seed %d: shuffling the dispatch order, running up to %d units concurrently", dr.flakyHuntSeed, parallelism)
+
+	dr.slots.resize(parallelism)
+}
+
+// shuffled orders the given ready entries by their dispatch rank of the flaky hunt.
+func (dr *Controller) shuffled(entries []*queue.Entry) []*queue.Entry {
+	slices.SortStableFunc(entries, func(a, b *queue.Entry) int {
+		return cmp.Compare(dr.dispatchRank[a.Component.Path()], dr.dispatchRank[b.Component.Path()])
+	})
+
+	return entries
+}
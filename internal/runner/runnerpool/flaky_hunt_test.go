@@ -0,0 +1,56 @@
+package runnerpool_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_FlakyHunt(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{"A", "B", "C", "D", "E", "F"}
+
+	// dispatchOrder runs independent units one at a time with the given seed and returns the order they ran in.
+	dispatchOrder := func(seed int64) []string {
+		units := buildComponentUnits(paths, map[string][]string{})
+
+		var ran []string
+
+		runner := func(ctx context.Context, u *component.Unit) error {
+			ran = append(ran, u.Path())
+			return nil
+		}
+
+		err := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(runner),
+			runnerpool.WithMaxConcurrency(1),
+			runnerpool.WithFlakyHunt(seed),
+		).Run(t.Context(), logger.CreateLogger())
+		require.NoError(t, err)
+
+		return ran
+	}
+
+	// The same seed reproduces the same order.
+	for _, seed := range []int64{1, 42, 1337} {
+		order := dispatchOrder(seed)
+		assert.ElementsMatch(t, paths, order)
+		assert.Equal(t, order, dispatchOrder(seed))
+	}
+
+	// Some seed dispatches the units out of queue order.
+	shuffled := slices.ContainsFunc([]int64{1, 2, 3, 4, 5}, func(seed int64) bool {
+		return !slices.Equal(dispatchOrder(seed), paths)
+	})
+	assert.True(t, shuffled)
+}
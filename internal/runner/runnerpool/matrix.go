@@ -0,0 +1,106 @@
+package runnerpool
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	tgerrors "github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// matrixVariant is one of the runs of a unit expanded from its matrix.
+type matrixVariant struct {
+	// unit is the unit the variant was expanded from, whose configuration the variant runs.
+	unit *component.Unit
+	// lock serializes the variants of the same unit, which share its working directory.
+	lock *sync.Mutex
+	// flags are added to the command of the variant.
+	flags []string
+}
+
+// matrixVariantPath returns the path of the run of the unit at the given path for the given matrix key.
+func matrixVariantPath(path, key string) string {
+	return path + "[" + key + "]"
+}
+
+// expandUnitMatrix replaces every unit with a matrix by a unit per matrix key, wired to the dependencies and
+// dependents of the unit, and returns the resulting units along with the variants keyed by their path.
+// Excluded units are not expanded.
+func expandUnitMatrix(
+	l log.Logger,
+	opts *options.TerragruntOptions,
+	units []*component.Unit,
+	matrix map[string]map[string][]string,
+) ([]*component.Unit, map[string]matrixVariant, error) {
+	resolved := make(map[string]map[string][]string, len(matrix))
+
+	for path, entries := range matrix {
+		unit, err := resolveUnit(opts, units, path, "unit %s with a matrix")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(entries) == 0 {
+			return nil, nil, tgerrors.Errorf("matrix of unit %s has no entries", unit.Path())
+		}
+
+		resolved[unit.Path()] = entries
+	}
+
+	expanded := make([]*component.Unit, 0, len(units))
+	variants := make(map[string]matrixVariant)
+
+	for _, unit := range units {
+		entries, ok := resolved[unit.Path()]
+		if !ok || unit.Excluded() {
+			expanded = append(expanded, unit)
+			continue
+		}
+
+		var (
+			lock         = &sync.Mutex{}
+			dependencies = unit.Dependencies()
+			dependents   = unit.Dependents()
+		)
+
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			variant := component.NewUnit(matrixVariantPath(unit.Path(), key))
+			variant.SetConfigFile(unit.ConfigFile())
+			variant.StoreConfig(unit.Config())
+
+			if discoveryCtx := unit.DiscoveryContext(); discoveryCtx != nil {
+				variant.SetDiscoveryContext(discoveryCtx.Copy())
+			}
+
+			if unit.External() {
+				variant.SetExternal()
+			}
+
+			for _, dep := range dependencies {
+				variant.AddDependency(dep)
+			}
+
+			for _, dependent := range dependents {
+				dependent.AddDependency(variant)
+			}
+
+			variants[variant.Path()] = matrixVariant{unit: unit, lock: lock, flags: entries[key]}
+			expanded = append(expanded, variant)
+		}
+
+		for _, dep := range dependencies {
+			unit.RemoveDependency(dep)
+		}
+
+		for _, dependent := range dependents {
+			dependent.RemoveDependency(unit)
+		}
+
+		l.Debugf("Expanded unit %s into %d matrix runs", unit.DisplayPath(), len(entries))
+	}
+
+	return expanded, variants, nil
+}
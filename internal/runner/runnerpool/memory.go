@@ -0,0 +1,92 @@
+package runnerpool
+
+import (
+	"sync"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// MemoryEstimateFunc returns the estimated peak memory of a unit while it runs, in MiB, or zero when there is
+// no estimate.
+type MemoryEstimateFunc func(unit *component.Unit) int
+
+// WithMemoryBudget caps the sum of the memory estimates of the running units to budget MiB, in addition to
+// the concurrency limit, e.g. to prevent running out of memory on stacks mixing small units with units
+// managing large providers.
+//
+// Ready units are packed into the budget: a unit whose estimate does not fit in what is left of the budget is
+// held back while smaller ready units start, and starts once enough running units finish. A unit whose
+// estimate exceeds the budget runs alone, once no other unit with an estimate is running. Units without an
+// estimate are not limited. A zero or negative budget disables the limit, which is the default.
+func WithMemoryBudget(budget int, estimate MemoryEstimateFunc) ControllerOption {
+	return func(dr *Controller) {
+		if budget <= 0 || estimate == nil {
+			return
+		}
+
+		dr.memory = &memoryBudget{
+			slots:    newSlotSemaphore(budget),
+			estimate: estimate,
+			reserved: make(map[string]int),
+		}
+	}
+}
+
+// memoryBudget tracks the memory reserved by the running units against the memory budget of the run.
+type memoryBudget struct {
+	slots    *slotSemaphore
+	estimate MemoryEstimateFunc
+	// reserved holds the memory reserved by each running unit, keyed by path.
+	reserved map[string]int
+	mu       sync.Mutex
+}
+
+// reserveMemory reserves the estimated memory of the unit of the entry, and returns false when it does not
+// fit in what is left of the budget, in which case the entry is left ready to be dispatched later.
+func (dr *Controller) reserveMemory(l log.Logger, ent *queue.Entry) bool {
+	if dr.memory == nil {
+		return true
+	}
+
+	unit := dr.unit(ent.Component.Path())
+	if unit == nil {
+		return true
+	}
+
+	estimate := dr.memory.estimate(unit)
+	if estimate <= 0 {
+		return true
+	}
+
+	reserved := dr.memory.slots.tryAcquireWeight(estimate)
+	if reserved == 0 {
+		l.Debugf("Runner Pool Controller: holding back %s, its memory estimate of %d MiB does not fit in the memory budget",
+			ent.Component.DisplayPath(), estimate)
+
+		return false
+	}
+
+	dr.memory.mu.Lock()
+	dr.memory.reserved[ent.Component.Path()] = reserved
+	dr.memory.mu.Unlock()
+
+	return true
+}
+
+// releaseMemory gives back the memory reserved by the unit at the given path, if any.
+func (dr *Controller) releaseMemory(path string) {
+	if dr.memory == nil {
+		return
+	}
+
+	dr.memory.mu.Lock()
+	reserved, ok := dr.memory.reserved[path]
+	delete(dr.memory.reserved, path)
+	dr.memory.mu.Unlock()
+
+	if ok {
+		dr.memory.slots.releaseWeight(reserved)
+	}
+}
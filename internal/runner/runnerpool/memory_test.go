@@ -0,0 +1,182 @@
+package runnerpool_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_MemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	estimates := map[string]int{"A": 600, "B": 600, "C": 300, "D": 100, "E": 5000, "F": 0}
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D", "E", "F"}, nil)
+
+	var (
+		mu         sync.Mutex
+		running    = map[string]bool{}
+		maxInUse   int
+		ranWithE   []string
+		ran        int
+		usedMemory = func() int {
+			used := 0
+			for path := range running {
+				used += estimates[path]
+			}
+
+			return used
+		}
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		running[u.Path()] = true
+		ran++
+
+		if running["E"] {
+			for path := range running {
+				if path != "E" && estimates[path] > 0 {
+					ranWithE = append(ranWithE, path)
+				}
+			}
+		} else {
+			maxInUse = max(maxInUse, usedMemory())
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		delete(running, u.Path())
+		mu.Unlock()
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(len(units)),
+		runnerpool.WithMemoryBudget(1000, func(unit *component.Unit) int {
+			return estimates[unit.Path()]
+		}),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.Equal(t, len(units), ran)
+	assert.LessOrEqual(t, maxInUse, 1000)
+	assert.Empty(t, ranWithE, "a unit exceeding the budget should run alone")
+}
+
+func TestRunner_MemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	logFile := filepath.Join(rootDir, "tofu.log")
+
+	files := map[string]string{
+		"modules/unit/main.tf": "",
+		// The fake binary logs the start and end of every apply.
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0"; exit 0 ;;
+  init) exit 0 ;;
+esac
+echo "start $TF_VAR_name" >> ` + logFile + `
+sleep 0.3
+echo "end $TF_VAR_name" >> ` + logFile + `
+exit 0
+`,
+	}
+
+	var units component.Components
+
+	for _, name := range []string{"a", "b", "c"} {
+		files[name+"/terragrunt.hcl"] = `terraform {
+  source = "../modules/unit"
+}
+
+inputs = {
+  name = "` + name + `"
+}
+`
+		units = append(units, component.NewUnit(filepath.Join(rootDir, name)).WithConfig(&config.TerragruntConfig{}))
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.Parallelism = len(units)
+	opts.MemoryBudget = 100
+	// a and b do not fit in the budget together, while c has no estimate.
+	opts.UnitMemoryEstimates = map[string]int{"a": 80, "b": 80}
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units)
+	require.NoError(t, err)
+
+	require.NoError(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+	runLog, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+
+	events := strings.Split(strings.TrimSpace(string(runLog)), "\n")
+	require.Len(t, events, 2*len(units))
+
+	if slices.Index(events, "start a") < slices.Index(events, "start b") {
+		assert.Less(t, slices.Index(events, "end a"), slices.Index(events, "start b"), events)
+	} else {
+		assert.Less(t, slices.Index(events, "end b"), slices.Index(events, "start a"), events)
+	}
+}
+
+func TestNewRunnerPoolStack_UnknownMemoryEstimateUnit(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.MemoryBudget = 100
+	opts.UnitMemoryEstimates = map[string]int{"missing": 80}
+
+	units := component.Components{component.NewUnit(filepath.Join(rootDir, "a")).WithConfig(&config.TerragruntConfig{})}
+
+	_, err = runnerpool.NewRunnerPoolStack(context.Background(), logger.CreateLogger(), opts, units)
+	require.Error(t, err)
+}
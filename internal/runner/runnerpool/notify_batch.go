@@ -0,0 +1,47 @@
+package runnerpool
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+)
+
+// notifyBatchPause is the pause between two batches of units made ready at once, when batching is enabled.
+const notifyBatchPause = 10 * time.Millisecond
+
+// WithNotifyBatchSize dispatches the units that become ready at once in batches of at most size units, with
+// a short pause between batches, e.g. to smooth the wakeup storm when a unit with dozens of dependents
+// finishes, instead of having all of them contend for a concurrency slot at the same time.
+//
+// A zero or negative size dispatches every ready unit right away, which is the default.
+func WithNotifyBatchSize(size int) ControllerOption {
+	return func(dr *Controller) {
+		dr.notifyBatchSize = size
+	}
+}
+
+// nextBatch returns the ready entries to dispatch in this scheduling pass, and whether some were held back
+// for a later pass.
+func (dr *Controller) nextBatch(entries []*queue.Entry) ([]*queue.Entry, bool) {
+	if dr.notifyBatchSize <= 0 || len(entries) <= dr.notifyBatchSize {
+		return entries, false
+	}
+
+	return entries[:dr.notifyBatchSize], true
+}
+
+// pauseBeforeNextBatch waits for the pause between two batches, then signals the scheduling loop to dispatch
+// the next one. It returns early when the context is cancelled.
+func (dr *Controller) pauseBeforeNextBatch(ctx context.Context) {
+	select {
+	case <-dr.clock.After(notifyBatchPause):
+	case <-ctx.Done():
+		return
+	}
+
+	select {
+	case dr.readyCh <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,70 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_NotifyBatchSize(t *testing.T) {
+	t.Parallel()
+
+	// A has five dependents that all become ready when it finishes.
+	dependents := []string{"B1", "B2", "B3", "B4", "B5"}
+
+	depMap := make(map[string][]string, len(dependents))
+	for _, path := range dependents {
+		depMap[path] = []string{"A"}
+	}
+
+	units := buildComponentUnits(append([]string{"A"}, dependents...), depMap)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	clock := &fakeClock{}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			mu.Lock()
+			ran = append(ran, u.Path())
+			mu.Unlock()
+
+			return nil
+		}),
+		runnerpool.WithMaxConcurrency(len(units)),
+		runnerpool.WithNotifyBatchSize(2),
+		runnerpool.WithClock(clock),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.Len(t, ran, len(units))
+	assert.Equal(t, "A", ran[0])
+
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	pauses := 0
+
+	for _, wait := range clock.waits {
+		if wait == 10*time.Millisecond {
+			pauses++
+		}
+	}
+
+	// Five ready dependents in batches of two take at least two pauses.
+	assert.GreaterOrEqual(t, pauses, 2)
+}
@@ -0,0 +1,313 @@
+package runnerpool
+
+import (
+	"maps"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// runnerOption is a common.Option that configures a runner pool Runner.
+// It is a no-op when applied to any other StackRunner implementation.
+type runnerOption func(*Runner)
+
+// Apply applies the option to the runner if it is a runner pool Runner.
+func (o runnerOption) Apply(stack common.StackRunner) {
+	if rnr, ok := stack.(*Runner); ok {
+		o(rnr)
+	}
+}
+
+// WithUnitOutputMemoryBudget limits the total number of bytes buffered by all unit writers during a run.
+// When the budget is exceeded, the largest buffer is flushed early, interleaving its output with other units.
+func WithUnitOutputMemoryBudget(limit int) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.outputBudget = NewOutputBudget(limit)
+	})
+}
+
+// WithOutputFilter passes every line of output of each unit, on stdout and stderr, along with its log lines,
+// through the given filter before it is written, e.g. to redact secrets leaked by OpenTofu/Terraform. By
+// default, the output is written as is.
+func WithOutputFilter(filter OutputFilter) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.outputFilter = filter
+	})
+}
+
+// WithGroupedOutput holds the whole output of each unit until it finishes, and writes it as a single block
+// between a header and a footer, so that the output of the run reads as a sequence of complete units in
+// completion order. The stderr of each unit, along with its log lines, is grouped in a block of its own.
+// Past limit bytes of output, or DefaultGroupedOutputLimit if limit is zero or less, or when the memory
+// budget is exceeded, the output of a unit is streamed instead.
+func WithGroupedOutput(limit int) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.groupedOutput = true
+		rnr.groupedOutputLimit = limit
+	})
+}
+
+// WithResultCache makes the runner skip units whose inputs are unchanged since their last successful run.
+func WithResultCache(cache common.ResultCache) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithResultCache(cache))
+	})
+}
+
+// WithControllerOptions passes additional options to the controller that schedules the units of a run.
+func WithControllerOptions(opts ...ControllerOption) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.controllerOpts = append(rnr.controllerOpts, opts...)
+	})
+}
+
+// WithTarget restricts the run to the unit at the given path and everything it transitively depends on.
+// Every other unit is excluded. When includeDependents is set, the units that transitively depend on the
+// target are run as well. Relative paths are resolved against the working directory.
+func WithTarget(path string, includeDependents bool) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.target = &unitTarget{path: path, includeDependents: includeDependents}
+	})
+}
+
+// WithAssumeAppliedExcept runs only the units at the given paths and treats every other unit as already applied.
+// Unlike WithTarget, the dependencies of the selected units are not run either. Like any dependency outside
+// of the run, their existing state is used, so the selected units can still read their outputs.
+// Relative paths are resolved against the working directory.
+func WithAssumeAppliedExcept(paths ...string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.applyOnly = append(rnr.applyOnly, paths...)
+	})
+}
+
+// WithStartWave resumes a staged run from the dependency wave at the given index, e.g. after it failed in
+// that wave, treating the units of every earlier wave as already applied. Waves are computed over the units
+// left to run once the other exclusions apply, and the start wave of a run checkpointed with
+// Queue.MarshalGraph can be inferred with StartWaveFromGraph.
+//
+// An index past the last wave fails the run, while zero or a negative index starts from the first wave.
+func WithStartWave(index int) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.startWave = index
+	})
+}
+
+// WithAlreadyApplied decides right before each unit runs whether it is already applied, e.g. by checking a
+// deployment record, instead of when the run is set up as with WithAssumeAppliedExcept. A unit that is already
+// applied is not run and is reported as assumed applied, while an error fails that unit only.
+func WithAlreadyApplied(alreadyApplied common.AlreadyAppliedFunc) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithAlreadyApplied(alreadyApplied))
+	})
+}
+
+// WithUnitContext adds values scoped to each unit, e.g. a trace ID, to the context the unit runs with.
+// They are visible to the run of the unit and to the JSON conversion of its plan alike, along with the path
+// of the unit, which is available through common.UnitPathFromContext. Every runner adds the path of the unit
+// to the attributes of the spans of its calls, see unitSpanAttributes.
+func WithUnitContext(unitContext common.UnitContextFunc) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithUnitContext(unitContext))
+	})
+}
+
+// WithOnSuccess calls the given function after every successful run of a unit, before the units waiting on
+// it are started, e.g. to replicate its state to a secondary backend for disaster recovery. An error returned
+// by the function fails the unit when failUnit is set, and is only logged as a warning otherwise.
+func WithOnSuccess(onSuccess common.UnitSuccessFunc, failUnit bool) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithOnSuccess(onSuccess, failUnit))
+	})
+}
+
+// WithPlanFileResolver overrides the plan file converted to JSON for each unit, e.g. to decrypt or relocate the
+// plan written by the run before the show call reads it. By default, the plan file the unit run writes to is
+// converted. An error returned by the resolver fails that unit only.
+func WithPlanFileResolver(resolver common.PlanFileResolver) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithPlanFileResolver(resolver))
+	})
+}
+
+// WithStrictReporting fails a unit whose result cannot be recorded in the report, instead of only logging
+// the failure, so that a run never succeeds with an incomplete report.
+func WithStrictReporting() common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.strictReporting = true
+	})
+}
+
+// WithStalePlanCheck records the outputs of the dependencies of every unit planned into the output folder, and
+// fails a unit without applying it when the outputs of its dependencies changed since its plan was made, so
+// that a plan computed from outdated dependency outputs is never applied.
+func WithStalePlanCheck() common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithStalePlanCheck())
+	})
+}
+
+// WithFullDependencyChain fails every unit applied by the run with a dependency, direct or transitive, that
+// neither succeeded in the run nor is assumed to be applied, e.g. because it was excluded, so that no unit is
+// applied on top of unsatisfied upstream state.
+func WithFullDependencyChain() common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.requireFullChain = true
+	})
+}
+
+// WithMinFreeDisk fails every unit whose working directory has less than minBytes of free disk space right
+// before it runs, without running it, so that a full disk does not leave half-written state or plan files.
+func WithMinFreeDisk(minBytes uint64) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithMinFreeDisk(minBytes))
+	})
+}
+
+// WithPathDisplay sets the function transforming the path of each unit into the path it is displayed with in
+// logs and in the report, e.g. relative to the root of the stack. Units are still identified by their canonical
+// paths, only how they are displayed changes.
+func WithPathDisplay(display component.PathDisplayFunc) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.pathDisplay = display
+	})
+}
+
+// WithChangedFiles runs only the units whose directory contains one of the given changed files, e.g. the files
+// changed since the last commit, and treats the other units as configured by unchanged. When includeDependents
+// is set, the units depending on a changed unit, directly or indirectly, are run as well.
+// Relative paths are resolved against the working directory.
+func WithChangedFiles(files []string, unchanged UnchangedUnits, includeDependents bool) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.changes = &changedFiles{
+			files:             files,
+			unchanged:         unchanged,
+			includeDependents: includeDependents,
+		}
+	})
+}
+
+// WithSkipPredicate excludes the units matched by the given predicate from the run, e.g. all units using a given
+// backend. The reason returned by the predicate is recorded as the cause of the exclusion in the report.
+func WithSkipPredicate(skip SkipPredicate) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.skipPredicate = skip
+	})
+}
+
+// WithExcludeGlobs excludes the units whose path matches the given glob patterns from the run, e.g.
+// "**/experimental/**". Patterns are checked in order and the last one matching a unit decides: a pattern
+// prefixed with "!" includes the units it matches again, e.g. to allowlist a unit within an excluded
+// directory. Relative patterns are resolved against the working directory.
+//
+// Excluded units are reported as excluded, like units excluded by an exclude block.
+func WithExcludeGlobs(patterns ...string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.excludeGlobs = append(rnr.excludeGlobs, patterns...)
+	})
+}
+
+// WithQuarantinedUnits quarantines the units at the given paths, e.g. units known to fail intermittently.
+// The failure of a quarantined unit is reported as quarantined and logged, but it does not fail the run and
+// its dependents still run. Relative paths are resolved against the working directory.
+func WithQuarantinedUnits(paths ...string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.quarantined = append(rnr.quarantined, paths...)
+	})
+}
+
+// WithNonBlockingUnits marks the units at the given paths as non-blocking, e.g. informational units such as
+// reporting units. The failure of a non-blocking unit is logged and reported as a failure, but it does not fail
+// the run and its dependents still run. Relative paths are resolved against the working directory.
+func WithNonBlockingUnits(paths ...string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.nonBlocking = append(rnr.nonBlocking, paths...)
+	})
+}
+
+// WithOutputResolver resolves the outputs of the units assumed to be applied, e.g. with
+// WithAssumeAppliedExcept, with the given resolver instead of reading them from their state when a unit
+// that runs depends on them.
+func WithOutputResolver(resolver OutputResolver) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.outputs = resolver
+	})
+}
+
+// WithUnitLogLevels overrides the log level used for the units at the given paths, e.g. to debug a single
+// unit of a large run without raising the verbosity of the others. Relative paths are resolved against the
+// working directory.
+func WithUnitLogLevels(levels map[string]log.Level) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		if rnr.logLevels == nil {
+			rnr.logLevels = make(map[string]log.Level, len(levels))
+		}
+
+		maps.Copy(rnr.logLevels, levels)
+	})
+}
+
+// WithUnitCommands overrides the command run for the units at the given paths, e.g. to only plan a target
+// while its dependencies are applied, so that the plan is reviewed against their fresh outputs. The units
+// keep the arguments of the run, without -auto-approve unless they run apply or destroy.
+// Relative paths are resolved against the working directory.
+func WithUnitCommands(commands map[string]string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		if rnr.commands == nil {
+			rnr.commands = make(map[string]string, len(commands))
+		}
+
+		maps.Copy(rnr.commands, commands)
+	})
+}
+
+// WithUnitMatrix runs each of the units at the given paths once per entry of its matrix, e.g. to validate a
+// unit against several regions in a single run. The matrix of a unit maps a key to the flags added to the
+// command of that run, such as -var=region=eu-west-1. Relative paths are resolved against the working
+// directory, and it is an error for a path not to match a discovered unit.
+//
+// Every run of the unit is scheduled and reported on its own, at the path of the unit suffixed with the key
+// in brackets, e.g. app[eu-west-1]. The runs share the dependencies of the unit, and the units depending on
+// it wait for all of them. As they share the working directory of the unit, its runs never run concurrently.
+func WithUnitMatrix(matrix map[string]map[string][]string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		if rnr.matrix == nil {
+			rnr.matrix = make(map[string]map[string][]string, len(matrix))
+		}
+
+		maps.Copy(rnr.matrix, matrix)
+	})
+}
+
+// WithOptionsTransform sets a transform that adjusts the options of each unit right before it runs.
+// An error returned by the transform fails that unit only.
+func WithOptionsTransform(transform common.OptionsTransform) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithOptionsTransform(transform))
+	})
+}
+
+// WithUnitCPUProfiles captures a CPU profile of each unit run under the given folder.
+// CPU profiles are process-wide, so they are only meaningful when units run one at a time (parallelism 1).
+func WithUnitCPUProfiles(dir string) common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.cpuProfileDir = dir
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithCPUProfileDir(dir))
+	})
+}
+
+// WithSyncedOutputs fsyncs the JSON plan output of each unit before the unit is marked finished,
+// so the outputs consumed by its dependents survive a crash of the host.
+func WithSyncedOutputs() common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithSyncedOutputs())
+	})
+}
+
+// WithJSONOutputOnlyForDependedUnits converts the plan of a unit to JSON only when another unit reads its outputs,
+// which saves a show call per leaf unit, or unit that others merely order after, on stacks with many of them.
+func WithJSONOutputOnlyForDependedUnits() common.Option {
+	return runnerOption(func(rnr *Runner) {
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithJSONOutputOnlyForDependedUnits())
+	})
+}
@@ -0,0 +1,116 @@
+package runnerpool
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// parallelismFileInterval is how often the file of --parallelism-file is read during a run.
+const parallelismFileInterval = time.Second
+
+// ParallelismControlFunc adjusts the parallelism of a run while it runs, e.g. from the load of the system,
+// by calling setParallelism as often as needed. It runs in its own goroutine from the start of the run, and
+// its context is cancelled once the run is over.
+type ParallelismControlFunc func(ctx context.Context, setParallelism func(n int))
+
+// WithParallelismControl sets a function that adjusts the parallelism of the run while it runs.
+// See Controller.SetParallelism for how changes apply.
+func WithParallelismControl(control ParallelismControlFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.parallelismControl = control
+	}
+}
+
+// SetParallelism changes how many units may run at once. Growing it starts waiting units right away, while
+// shrinking it only holds back new units until enough running units finish, without interrupting any.
+// Values below 1 are treated as 1.
+//
+// It is safe to call while the controller runs. In staged execution, a parallelism schedule still sets the
+// parallelism of each wave as it starts.
+func (dr *Controller) SetParallelism(n int) {
+	dr.slots.resize(n)
+}
+
+// Parallelism returns how many units may currently run at once.
+func (dr *Controller) Parallelism() int {
+	return dr.slots.size()
+}
+
+// startParallelismControl runs the parallelism control function, if any, until the returned function is
+// called, which waits for it to return.
+func (dr *Controller) startParallelismControl(ctx context.Context) func() {
+	if dr.parallelismControl == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		dr.parallelismControl(ctx, dr.SetParallelism)
+	})
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// parallelismFromFile returns a ParallelismControlFunc setting the parallelism of the run to the number held
+// by the file at the given path, read every interval, e.g. so that an external autoscaler can adjust the run
+// to the load of the system. A missing file or a file not holding a positive number keeps the parallelism
+// unchanged, with a warning for the latter.
+func parallelismFromFile(l log.Logger, path string, interval time.Duration) ParallelismControlFunc {
+	return func(ctx context.Context, setParallelism func(n int)) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last int
+
+		for {
+			n, err := readParallelismFile(path)
+
+			switch {
+			case err == nil && n != last:
+				l.Infof("Running up to %d units concurrently, as set in %s", n, path)
+				setParallelism(n)
+
+				last = n
+			case err != nil && !errors.Is(err, os.ErrNotExist) && last >= 0:
+				l.Warnf("Ignoring the parallelism file %s: %v", path, err)
+
+				// Only warn again once the file held a valid number.
+				last = -1
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// readParallelismFile returns the positive number held by the file at the given path.
+func readParallelismFile(path string) (int, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil || n < 1 {
+		return 0, errors.Errorf("%q is not a positive number", strings.TrimSpace(string(contents)))
+	}
+
+	return n, nil
+}
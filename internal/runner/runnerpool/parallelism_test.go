@@ -0,0 +1,186 @@
+package runnerpool_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_ParallelismControl(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D"}, nil)
+
+	var (
+		running    atomic.Int64
+		startOnce  sync.Once
+		started    = make(chan struct{})
+		allRunning = make(chan struct{})
+	)
+
+	// Every unit waits for all of them to run at once, which only happens once the parallelism is raised.
+	runner := func(ctx context.Context, u *component.Unit) error {
+		startOnce.Do(func() { close(started) })
+
+		if running.Add(1) == int64(len(units)) {
+			close(allRunning)
+		}
+
+		select {
+		case <-allRunning:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("units did not run concurrently")
+		}
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithParallelismControl(func(ctx context.Context, setParallelism func(n int)) {
+			select {
+			case <-started:
+				setParallelism(len(units))
+			case <-ctx.Done():
+			}
+		}),
+	)
+
+	assert.Equal(t, 1, controller.Parallelism())
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.Equal(t, len(units), controller.Parallelism())
+}
+
+func TestController_SetParallelismShrinks(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D"}, nil)
+
+	var (
+		running int
+		maxSeen int
+		mu      sync.Mutex
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		running++
+		maxSeen = max(maxSeen, running)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(4),
+	)
+
+	controller.SetParallelism(0)
+	assert.Equal(t, 1, controller.Parallelism())
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.Equal(t, 1, maxSeen)
+}
+
+func TestRunner_ParallelismFile(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	logFile := filepath.Join(rootDir, "tofu.log")
+	parallelismFile := filepath.Join(rootDir, "parallelism")
+
+	files := map[string]string{
+		"modules/unit/main.tf": "",
+		"parallelism":          "1\n",
+		// The fake binary logs the start and end of every apply.
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0"; exit 0 ;;
+  init) exit 0 ;;
+esac
+echo "start $TF_VAR_name" >> ` + logFile + `
+sleep 0.3
+echo "end $TF_VAR_name" >> ` + logFile + `
+exit 0
+`,
+	}
+
+	var units component.Components
+
+	for _, name := range []string{"a", "b", "c"} {
+		files[name+"/terragrunt.hcl"] = `terraform {
+  source = "../modules/unit"
+}
+
+inputs = {
+  name = "` + name + `"
+}
+`
+		units = append(units, component.NewUnit(filepath.Join(rootDir, name)).WithConfig(&config.TerragruntConfig{}))
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.Parallelism = len(units)
+	opts.ParallelismFile = parallelismFile
+
+	l := logger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units)
+	require.NoError(t, err)
+
+	require.NoError(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+	runLog, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+
+	// With a parallelism of 1, every unit ends before the next one starts.
+	events := strings.Split(strings.TrimSpace(string(runLog)), "\n")
+	require.Len(t, events, 2*len(units))
+
+	for i := 0; i < len(events); i += 2 {
+		assert.True(t, strings.HasPrefix(events[i], "start "), events)
+		assert.Equal(t, "end "+strings.TrimPrefix(events[i], "start "), events[i+1], events)
+	}
+}
@@ -0,0 +1,184 @@
+package runnerpool
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// PartitionFunc maps the path of a unit to the name of the partition it belongs to.
+type PartitionFunc func(path string) string
+
+// CrossPartitionPolicy controls how dependencies between units of different partitions are handled.
+type CrossPartitionPolicy int
+
+const (
+	// CrossPartitionError fails the run when a unit depends on a unit of another partition.
+	CrossPartitionError CrossPartitionPolicy = iota
+	// CrossPartitionOrder orders partitions so that every partition runs after the partitions it depends on.
+	CrossPartitionOrder
+)
+
+// WithPartitions runs the units in isolated batches, one partition at a time.
+//
+// Units are grouped into partitions with the given function, and a partition only starts once every unit of
+// the previous partition has finished. Units within a partition run in parallel as usual.
+// Partitions run in alphabetical order, unless the policy is CrossPartitionOrder and dependencies between
+// partitions require a different order.
+func WithPartitions(partitionOf PartitionFunc, policy CrossPartitionPolicy) ControllerOption {
+	return func(dr *Controller) {
+		dr.partitionOf = partitionOf
+		dr.partitionPolicy = policy
+	}
+}
+
+// TopLevelDirPartition returns a PartitionFunc grouping units by their top-level directory below root,
+// e.g. one partition per account directory.
+func TopLevelDirPartition(root string) PartitionFunc {
+	return func(path string) string {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return path
+		}
+
+		first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+
+		return first
+	}
+}
+
+// initPartitions computes the order of the partitions and the entries that belong to each of them.
+func (dr *Controller) initPartitions(l log.Logger) error {
+	entriesByPartition := map[string]queue.Entries{}
+	// after maps a partition to the partitions that must run before it.
+	after := map[string]map[string]bool{}
+
+	entries := dr.q.Snapshot()
+
+	for _, e := range entries {
+		name := dr.partitionOf(e.Component.Path())
+		entriesByPartition[name] = append(entriesByPartition[name], e)
+
+		if after[name] == nil {
+			after[name] = map[string]bool{}
+		}
+	}
+
+	for _, e := range entries {
+		name := dr.partitionOf(e.Component.Path())
+
+		for _, dep := range e.Component.Dependencies() {
+			if dr.q.EntryByPath(dep.Path()) == nil {
+				continue
+			}
+
+			depName := dr.partitionOf(dep.Path())
+			if depName == name {
+				continue
+			}
+
+			if dr.partitionPolicy == CrossPartitionError {
+				return errors.New(CrossPartitionDependencyError{
+					UnitPath:            e.Component.Path(),
+					UnitPartition:       name,
+					DependencyPath:      dep.Path(),
+					DependencyPartition: depName,
+				})
+			}
+
+			// Up commands run dependencies first, down commands run dependents first.
+			if e.IsUp() {
+				after[name][depName] = true
+			} else {
+				after[depName][name] = true
+			}
+		}
+	}
+
+	order, err := orderPartitions(after)
+	if err != nil {
+		return err
+	}
+
+	dr.partitionIndex = make(map[string]int, len(order))
+	dr.partitions = make([]queue.Entries, len(order))
+	dr.currentPartition = 0
+
+	for i, name := range order {
+		dr.partitionIndex[name] = i
+		dr.partitions[i] = entriesByPartition[name]
+	}
+
+	l.Debugf("Runner Pool Controller: running %d partitions in order %v", len(order), order)
+
+	return nil
+}
+
+// orderPartitions sorts the partitions topologically, breaking ties alphabetically.
+func orderPartitions(after map[string]map[string]bool) ([]string, error) {
+	remaining := make([]string, 0, len(after))
+	for name := range after {
+		remaining = append(remaining, name)
+	}
+
+	slices.Sort(remaining)
+
+	order := make([]string, 0, len(remaining))
+	done := make(map[string]bool, len(remaining))
+
+	for len(remaining) > 0 {
+		idx := slices.IndexFunc(remaining, func(name string) bool {
+			for before := range after[name] {
+				if !done[before] {
+					return false
+				}
+			}
+
+			return true
+		})
+		if idx == -1 {
+			return nil, errors.Errorf("dependencies between partitions %s form a cycle", strings.Join(remaining, ", "))
+		}
+
+		done[remaining[idx]] = true
+		order = append(order, remaining[idx])
+		remaining = slices.Delete(remaining, idx, idx+1)
+	}
+
+	return order, nil
+}
+
+// advancePartitions moves the controller to the next partition once every entry of the current one has finished.
+func (dr *Controller) advancePartitions(l log.Logger) {
+	for dr.currentPartition < len(dr.partitions) && dr.q.AllFinished(dr.partitions[dr.currentPartition]) {
+		dr.currentPartition++
+
+		if dr.currentPartition < len(dr.partitions) {
+			l.Debugf("Runner Pool Controller: starting partition %d with %d tasks",
+				dr.currentPartition, len(dr.partitions[dr.currentPartition]))
+		}
+	}
+}
+
+// inCurrentPartition filters ready entries down to those that belong to the current partition.
+// Entries of partitions unknown when the run started are only started once every known partition has finished.
+func (dr *Controller) inCurrentPartition(entries []*queue.Entry) []*queue.Entry {
+	out := make([]*queue.Entry, 0, len(entries))
+
+	for _, e := range entries {
+		idx, ok := dr.partitionIndex[dr.partitionOf(e.Component.Path())]
+		if !ok {
+			idx = len(dr.partitions)
+		}
+
+		if idx <= dr.currentPartition {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
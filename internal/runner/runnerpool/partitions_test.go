@@ -0,0 +1,111 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_PartitionsRunSequentially(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits(
+		[]string{"/live/a/vpc", "/live/a/app", "/live/b/vpc", "/live/b/app"},
+		map[string][]string{
+			"/live/a/app": {"/live/a/vpc"},
+			"/live/b/app": {"/live/b/vpc"},
+		},
+	)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ran = append(ran, u.Path())
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(4),
+		runnerpool.WithPartitions(runnerpool.TopLevelDirPartition("/live"), runnerpool.CrossPartitionError),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	require.Len(t, ran, 4)
+
+	// Partition a fully completes before partition b starts.
+	assert.ElementsMatch(t, []string{"/live/a/vpc", "/live/a/app"}, ran[:2])
+	assert.ElementsMatch(t, []string{"/live/b/vpc", "/live/b/app"}, ran[2:])
+}
+
+func TestController_CrossPartitionDependencies(t *testing.T) {
+	t.Parallel()
+
+	// Partition a depends on partition b, so b has to run first when ordering is allowed.
+	newUnits := func() []*component.Unit {
+		return buildComponentUnits(
+			[]string{"/live/a/app", "/live/b/vpc"},
+			map[string][]string{
+				"/live/a/app": {"/live/b/vpc"},
+			},
+		)
+	}
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		units := newUnits()
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+			runnerpool.WithPartitions(runnerpool.TopLevelDirPartition("/live"), runnerpool.CrossPartitionError),
+		)
+
+		err := controller.Run(t.Context(), logger.CreateLogger())
+
+		var crossErr runnerpool.CrossPartitionDependencyError
+		require.ErrorAs(t, err, &crossErr)
+		assert.Equal(t, "/live/a/app", crossErr.UnitPath)
+		assert.Equal(t, "b", crossErr.DependencyPartition)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		t.Parallel()
+
+		units := newUnits()
+
+		var ran []string
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+				ran = append(ran, u.Path())
+				return nil
+			}),
+			runnerpool.WithMaxConcurrency(1),
+			runnerpool.WithPartitions(runnerpool.TopLevelDirPartition("/live"), runnerpool.CrossPartitionOrder),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+		assert.Equal(t, []string{"/live/b/vpc", "/live/a/app"}, ran)
+	})
+}
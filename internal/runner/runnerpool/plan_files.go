@@ -0,0 +1,56 @@
+package runnerpool
+
+import (
+	"os"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/tf"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// VerifyPlanFiles checks, before an apply of the plan files saved to the output folder of the given options,
+// that every unit to apply has a readable plan file, and returns a MissingPlanFilesError listing the units
+// that do not, so that the run fails up front instead of partway through.
+//
+// Excluded units, units assumed to be applied and units whose command is overridden to something else than
+// an apply are not checked. Nothing is checked without an output folder.
+func (rnr *Runner) VerifyPlanFiles(opts *options.TerragruntOptions) error {
+	if opts.OutputFolder == "" {
+		return nil
+	}
+
+	missing := make(map[string]string)
+
+	for _, entry := range rnr.queue.Entries {
+		unit, ok := entry.Component.(*component.Unit)
+		if !ok || unit.Excluded() || rnr.assumedApplied[unit.Path()] {
+			continue
+		}
+
+		// A run expanded from the matrix of a unit runs the command of that unit, and applies its plan.
+		if variant, ok := rnr.variants[unit.Path()]; ok {
+			unit = variant.unit
+		}
+
+		if cmd, ok := rnr.commands[unit.Path()]; ok && cmd != tf.CommandNameApply {
+			continue
+		}
+
+		planFile := unit.OutputFile(opts.RootWorkingDir, opts.OutputFolder)
+
+		f, err := os.Open(planFile)
+		if err != nil {
+			missing[entry.Component.Path()] = planFile
+			continue
+		}
+
+		f.Close() //nolint:errcheck
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return errors.New(MissingPlanFilesError{PlanFiles: missing})
+}
@@ -0,0 +1,74 @@
+package runnerpool_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestVerifyPlanFiles(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := helpers.TmpDirWOSymlinks(t)
+	outDir := filepath.Join(tmpDir, "out")
+
+	discoveryCtx := &component.DiscoveryContext{WorkingDir: tmpDir}
+
+	units := component.Components{}
+
+	for _, name := range []string{"vpc", "db", "app"} {
+		units = append(units, component.NewUnit(filepath.Join(tmpDir, name)).
+			WithConfig(&config.TerragruntConfig{}).
+			WithDiscoveryContext(discoveryCtx))
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(tmpDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.TerraformCommand = "apply"
+	opts.OutputFolder = outDir
+
+	stack, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		units,
+		// db is only planned, so none of the runs of its matrix needs a plan file.
+		runnerpool.WithUnitCommands(map[string]string{filepath.Join(tmpDir, "db"): "plan"}),
+		runnerpool.WithUnitMatrix(map[string]map[string][]string{
+			filepath.Join(tmpDir, "db"): {
+				"eu": {"-var=region=eu-west-1"},
+				"us": {"-var=region=us-east-1"},
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	planFile := filepath.Join(outDir, "vpc", "tfplan.tfplan")
+	require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o755))
+	require.NoError(t, os.WriteFile(planFile, []byte("plan"), 0o600))
+
+	err = stack.(*runnerpool.Runner).VerifyPlanFiles(opts)
+	require.Error(t, err)
+
+	var missingErr runnerpool.MissingPlanFilesError
+	require.True(t, errors.As(err, &missingErr))
+	assert.Equal(t, map[string]string{
+		filepath.Join(tmpDir, "app"): filepath.Join(outDir, "app", "tfplan.tfplan"),
+	}, missingErr.PlanFiles)
+
+	opts.OutputFolder = ""
+	require.NoError(t, stack.(*runnerpool.Runner).VerifyPlanFiles(opts))
+}
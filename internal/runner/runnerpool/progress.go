@@ -0,0 +1,26 @@
+package runnerpool
+
+import (
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// WithProgressLogging controls whether the controller logs a progress line, such as
+// "[37/120] finished unitX (3 running, 80 waiting)", every time a unit completes.
+// Progress logging is disabled by default.
+func WithProgressLogging(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.progress = enabled
+	}
+}
+
+// logProgress logs how far the run has progressed after the given unit completed with the given outcome.
+func (dr *Controller) logProgress(l log.Logger, unit *component.Unit, outcome string) {
+	if !dr.progress {
+		return
+	}
+
+	p := dr.q.Progress()
+
+	l.Infof("[%d/%d] %s %s (%d running, %d waiting)", p.Finished, p.Total, outcome, unit.DisplayPath(), p.Running, p.Waiting)
+}
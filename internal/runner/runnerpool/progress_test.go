@@ -0,0 +1,112 @@
+package runnerpool_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_ProgressLogging(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> C
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "C" {
+			return assert.AnError
+		}
+
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithProgressLogging(true),
+	)
+
+	require.Error(t, controller.Run(t.Context(), l))
+
+	output := buf.String()
+	assert.Contains(t, output, "[1/3] finished A (0 running, 2 waiting)")
+	assert.Contains(t, output, "[2/3] finished B (0 running, 1 waiting)")
+	assert.Contains(t, output, "[3/3] failed C (0 running, 0 waiting)")
+}
+
+func TestController_ProgressLoggingDisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	buf := new(bytes.Buffer)
+	l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), l))
+	assert.NotContains(t, buf.String(), "[1/1]")
+}
+
+func TestRunner_ProgressLogging(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{true, false} {
+		rootDir := helpers.TmpDirWOSymlinks(t)
+
+		// The unit has no configuration, so it fails.
+		vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.WorkingDir = rootDir
+		opts.TerraformCommand = "plan"
+		opts.TerraformCliArgs = iacargs.New("plan")
+		opts.ProgressLogging = enabled
+
+		buf := new(bytes.Buffer)
+		l := thlogger.CreateLogger()
+		l.SetOptions(log.WithOutput(buf))
+
+		stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{vpc})
+		require.NoError(t, err)
+
+		require.Error(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+		if enabled {
+			assert.Contains(t, buf.String(), "[1/1] failed "+vpc.DisplayPath())
+		} else {
+			assert.NotContains(t, buf.String(), "[1/1]")
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package runnerpool
+
+import (
+	"github.com/gruntwork-io/terragrunt/internal/component"
+)
+
+// WithReleaseFinishedUnits makes the controller drop the parsed configuration of every unit once it has
+// finished, so that the memory held by a run over tens of thousands of units shrinks as it progresses
+// instead of peaking at the end.
+//
+// The configuration of a unit is read by the runs of its dependencies, which check whether it reads their
+// outputs, so a unit is only released once it and all its dependencies in the run have finished. The outcome
+// of released units stays available through the report, which only keeps a compact record of each run.
+func WithReleaseFinishedUnits(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.releaseFinished = enabled
+	}
+}
+
+// releaseUnit drops the resources held by the given finished unit and by its dependents, when enabled, as soon as
+// no run reads their configuration anymore.
+func (dr *Controller) releaseUnit(unit *component.Unit) {
+	if !dr.releaseFinished {
+		return
+	}
+
+	if dr.releasable(unit) {
+		unit.StoreConfig(nil)
+	}
+
+	for _, dependent := range unit.Dependents() {
+		if dependent, ok := dependent.(*component.Unit); ok && dr.releasable(dependent) {
+			dependent.StoreConfig(nil)
+		}
+	}
+}
+
+// releasable reports whether the given unit and all its dependencies that are part of the run have finished.
+func (dr *Controller) releasable(unit *component.Unit) bool {
+	if _, ok := dr.finishOrder.Load(unit.Path()); !ok {
+		return false
+	}
+
+	for _, dep := range unit.Dependencies() {
+		if dr.unit(dep.Path()) == nil {
+			continue
+		}
+
+		if _, ok := dr.finishOrder.Load(dep.Path()); !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,105 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_ReleaseFinishedUnits(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{false, true} {
+		units := buildComponentUnits(
+			[]string{"A", "B"},
+			map[string][]string{
+				"B": {"A"},
+			},
+		)
+
+		for _, u := range units {
+			u.StoreConfig(&config.TerragruntConfig{})
+		}
+
+		var (
+			mu   sync.Mutex
+			seen = map[string]bool{}
+		)
+
+		runner := func(ctx context.Context, u *component.Unit) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			seen[u.Path()] = u.Config() != nil
+
+			return nil
+		}
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(runner),
+			runnerpool.WithReleaseFinishedUnits(enabled),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), log.New()))
+
+		// Units are released only once they have finished running.
+		assert.Equal(t, map[string]bool{"A": true, "B": true}, seen)
+
+		for _, u := range units {
+			assert.Equal(t, enabled, u.Config() == nil, u.Path())
+		}
+	}
+}
+
+func TestController_ReleaseFinishedUnitsAfterDependencies(t *testing.T) {
+	t.Parallel()
+
+	// Destroying B before A, where the run of A reads the configuration of its dependent B.
+	units := buildComponentUnits(
+		[]string{"A", "B"},
+		map[string][]string{
+			"B": {"A"},
+		},
+	)
+
+	for _, u := range units {
+		u.StoreConfig(&config.TerragruntConfig{})
+		u.SetDiscoveryContext(&component.DiscoveryContext{Cmd: "destroy"})
+	}
+
+	var dependentConfig bool
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "A" {
+			dependentConfig = units[1].Config() != nil
+		}
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithReleaseFinishedUnits(true),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+
+	// B finished first, but is only released once A, which reads its configuration, finished as well.
+	assert.True(t, dependentConfig)
+
+	for _, u := range units {
+		assert.Nil(t, u.Config(), u.Path())
+	}
+}
@@ -0,0 +1,57 @@
+package runnerpool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// WithReportSnapshots writes the report to the given file every interval while units are running, so that
+// a run that crashes midway still leaves a report of the units that completed behind.
+//
+// Every snapshot replaces the file atomically, and a snapshot that fails only logs a warning. Snapshots
+// stop once every unit has finished, before the caller writes the final report.
+// A zero or negative interval disables snapshots, which is the default.
+func WithReportSnapshots(r *report.Report, path string, interval time.Duration) ControllerOption {
+	return func(dr *Controller) {
+		dr.snapshotReport = r
+		dr.snapshotFile = path
+		dr.snapshotInterval = interval
+	}
+}
+
+// snapshotReports starts writing report snapshots, when enabled. The returned function stops them, waiting
+// for a snapshot that is being written, and must be called once the run is over.
+func (dr *Controller) snapshotReports(l log.Logger) func() {
+	if dr.snapshotReport == nil || dr.snapshotFile == "" || dr.snapshotInterval <= 0 {
+		return func() {}
+	}
+
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+
+	wg.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-dr.clock.After(dr.snapshotInterval):
+				if err := dr.snapshotReport.WriteToFile(dr.snapshotFile); err != nil {
+					l.Warnf("Failed to write report snapshot to %s: %v", dr.snapshotFile, err)
+					continue
+				}
+
+				l.Debugf("Wrote report snapshot to %s", dr.snapshotFile)
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
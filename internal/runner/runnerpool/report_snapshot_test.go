@@ -0,0 +1,89 @@
+package runnerpool_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_ReportSnapshots(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := helpers.TmpDirWOSymlinks(t)
+	reportFile := filepath.Join(tmpDir, "report.json")
+
+	unitA := filepath.Join(tmpDir, "A")
+	unitB := filepath.Join(tmpDir, "B")
+
+	// A <- B, where B only finishes once a snapshot shows that A completed.
+	units := buildComponentUnits(
+		[]string{unitA, unitB},
+		map[string][]string{unitB: {unitA}},
+	)
+
+	l := logger.CreateLogger()
+	r := report.NewReport().WithFormat(report.FormatJSON)
+
+	readSnapshot := func() string {
+		content, err := os.ReadFile(reportFile)
+		if err != nil {
+			return ""
+		}
+
+		return string(content)
+	}
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		run, err := report.NewRun(u.Path())
+		if err != nil {
+			return err
+		}
+
+		if err := r.AddRun(l, run); err != nil {
+			return err
+		}
+
+		if u.Path() == unitB {
+			if !assert.Eventually(t, func() bool {
+				snapshot, parseErr := report.ParseJSONRuns([]byte(readSnapshot()))
+
+				return parseErr == nil && len(snapshot) == 2 &&
+					snapshot[0].Result == string(report.ResultSucceeded)
+			}, 5*time.Second, 10*time.Millisecond) {
+				return nil
+			}
+		}
+
+		return r.EndRun(l, u.Path(), report.WithResult(report.ResultSucceeded))
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithReportSnapshots(r, reportFile, 10*time.Millisecond),
+	).Run(t.Context(), l)
+	require.NoError(t, err)
+
+	// Snapshots stop once the run is over.
+	require.NoError(t, os.Remove(reportFile))
+	time.Sleep(50 * time.Millisecond)
+	assert.NoFileExists(t, reportFile)
+
+	// Temporary files of the snapshots are not left behind.
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -7,10 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gruntwork-io/terragrunt/internal/configbridge"
 	"github.com/gruntwork-io/terragrunt/internal/iacargs"
@@ -21,6 +25,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/internal/component"
 	tgerrors "github.com/gruntwork-io/terragrunt/internal/errors"
 	"github.com/gruntwork-io/terragrunt/internal/experiment"
+	"github.com/gruntwork-io/terragrunt/internal/glob"
 	"github.com/gruntwork-io/terragrunt/internal/os/stdout"
 	"github.com/gruntwork-io/terragrunt/internal/queue"
 	"github.com/gruntwork-io/terragrunt/internal/report"
@@ -36,8 +41,45 @@ import (
 
 // Runner implements the Stack interface for runner pool execution.
 type Runner struct {
-	Stack *component.Stack
-	queue *queue.Queue
+	Stack          *component.Stack
+	queue          *queue.Queue
+	outputBudget   *OutputBudget
+	outputFilter   OutputFilter
+	unitRunnerOpts []common.UnitRunnerOption
+	controllerOpts []ControllerOption
+	target         *unitTarget
+	// assumedApplied holds the paths of the units that are not run because they are assumed to be applied.
+	assumedApplied map[string]bool
+	// logLevels holds the log level overrides of individual units, keyed by unit path.
+	logLevels map[string]log.Level
+	// commands holds the command overrides of individual units, keyed by unit path.
+	commands map[string]string
+	// matrix holds the matrix of the units run once per matrix key, keyed by unit path.
+	matrix map[string]map[string][]string
+	// variants holds the runs expanded from the matrix of a unit, keyed by their path.
+	variants      map[string]matrixVariant
+	cpuProfileDir string
+	applyOnly     []string
+	changes       *changedFiles
+	skipPredicate SkipPredicate
+	excludeGlobs  []string
+	quarantined   []string
+	nonBlocking   []string
+	outputs       OutputResolver
+	// skipReasons holds the reasons returned by the skip predicate for the units it excluded, keyed by path.
+	skipReasons map[string]string
+	// startWave is the index of the dependency wave the run starts from, the units of earlier waves being
+	// assumed to be applied.
+	startWave int
+	// strictReporting fails the units, and the run, whose result cannot be recorded in the report.
+	strictReporting bool
+	// requireFullChain fails applied units with a dependency that neither succeeded nor is assumed to be applied.
+	requireFullChain bool
+	// groupedOutput holds the output of each unit until it finishes, up to groupedOutputLimit bytes.
+	groupedOutput      bool
+	groupedOutputLimit int
+	// pathDisplay transforms the paths of the units displayed in logs and in the report, when set.
+	pathDisplay component.PathDisplayFunc
 }
 
 // CloneUnitOptions clones TerragruntOptions for a specific unit.
@@ -140,6 +182,71 @@ func BuildUnitOpts(l log.Logger, stackOpts *options.TerragruntOptions, unit *com
 	return unitOpts, unitLogger, nil
 }
 
+// prepareStackCliArgs adds the flags every unit of the run gets to the CLI args of the stack, and reports whether
+// the CLI args of the stack must be synced into the options of each unit.
+func prepareStackCliArgs(stackOpts *options.TerragruntOptions) bool {
+	terraformCmd := stackOpts.TerraformCommand
+
+	// Mutate stackOpts CLI args at the top level - these get cloned into per-unit opts later
+	if slices.Contains(config.TerraformCommandsNeedInput, terraformCmd) {
+		stackOpts.TerraformCliArgs.InsertFlag(0, "-input=false")
+	}
+
+	needsCliSync := false
+
+	switch terraformCmd {
+	case tf.CommandNameApply, tf.CommandNameDestroy:
+		if stackOpts.RunAllAutoApprove {
+			stackOpts.TerraformCliArgs.InsertFlag(0, "-auto-approve")
+		}
+
+		needsCliSync = true
+	case tf.CommandNameShow, tf.CommandNamePlan:
+		needsCliSync = true
+	}
+
+	if slices.Contains(config.TerraformCommandsNeedInput, terraformCmd) {
+		needsCliSync = true
+	}
+
+	return needsCliSync
+}
+
+// setUnitCliArgs sets the command and CLI args the given unit of the run is run with, honoring per-unit command
+// overrides and the flags of matrix runs.
+func (rnr *Runner) setUnitCliArgs(
+	l log.Logger,
+	stackOpts *options.TerragruntOptions,
+	unitOpts *options.TerragruntOptions,
+	u *component.Unit,
+	needsCliSync bool,
+) {
+	unitPath := u.Path()
+
+	variant, isVariant := rnr.variants[unitPath]
+	if isVariant {
+		unitPath = variant.unit.Path()
+	}
+
+	cmd, overridden := rnr.commands[unitPath]
+	if overridden {
+		unitOpts.TerraformCommand = cmd
+	}
+
+	// Sync CLI args from stackOpts into unit opts
+	if needsCliSync {
+		syncUnitCliArgs(l, stackOpts, unitOpts, u)
+	}
+
+	if overridden {
+		overrideUnitCommand(unitOpts, cmd)
+	}
+
+	if isVariant {
+		unitOpts.TerraformCliArgs = unitOpts.TerraformCliArgs.Clone().AppendFlag(variant.flags...)
+	}
+}
+
 // syncUnitCliArgs applies CLI argument synchronization for a single unit.
 // It merges/clones flags from stackOpts and computes and appends the plan file if needed.
 func syncUnitCliArgs(l log.Logger, stackOpts *options.TerragruntOptions, unitOpts *options.TerragruntOptions, unit *component.Unit) {
@@ -246,7 +353,7 @@ func NewRunnerPoolStack(
 
 	// Apply options (including report) BEFORE resolving units so that
 	// the report is available during unit resolution for tracking exclusions
-	rnr = rnr.WithOptions(runnerOpts...)
+	rnr = rnr.WithOptions(append([]common.Option{WithUnitContext(unitSpanAttributes)}, runnerOpts...)...)
 
 	// Resolve units from discovery
 	units := make([]*component.Unit, 0, len(nonStackComponents))
@@ -263,6 +370,10 @@ func NewRunnerPoolStack(
 		units = append(units, unit)
 	}
 
+	if rnr.pathDisplay != nil {
+		applyPathDisplay(units, rnr.pathDisplay)
+	}
+
 	// Check for units with Git refs but no remote state configuration
 	checkLocalStateWithGitRefs(l, units)
 	rnr.Stack.Units = units
@@ -276,6 +387,145 @@ func NewRunnerPoolStack(
 		applyFilterAllowDestroyExclusions(l, opts, units)
 	}
 
+	if rnr.target != nil {
+		if err := applyTargetExclusions(l, opts, units, rnr.target); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rnr.applyOnly) > 0 {
+		assumed, err := applyAssumeApplied(l, opts, units, rnr.applyOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.assumedApplied = assumed
+	}
+
+	if rnr.changes != nil {
+		unchanged := applyChangedFiles(l, opts, units, rnr.changes)
+
+		if rnr.changes.unchanged == UnchangedAssumeApplied {
+			if rnr.assumedApplied == nil {
+				rnr.assumedApplied = make(map[string]bool, len(unchanged))
+			}
+
+			maps.Copy(rnr.assumedApplied, unchanged)
+		}
+	}
+
+	if rnr.skipPredicate != nil {
+		rnr.skipReasons = applySkipPredicate(l, units, rnr.skipPredicate)
+	}
+
+	// The globs of the options come last, so that they decide over the globs set with WithExcludeGlobs.
+	if excludeGlobs := append(slices.Clone(rnr.excludeGlobs), opts.ExcludeGlobs...); len(excludeGlobs) > 0 {
+		if err := applyExcludeGlobs(l, opts, units, excludeGlobs); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rnr.quarantined) > 0 {
+		quarantined, err := resolveUnitPaths(opts, units, "quarantined", rnr.quarantined)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.controllerOpts = append(rnr.controllerOpts, WithQuarantine(quarantined...))
+		rnr.unitRunnerOpts = append(rnr.unitRunnerOpts, common.WithQuarantinedUnits(quarantined...))
+	}
+
+	if len(rnr.nonBlocking) > 0 {
+		nonBlocking, err := resolveUnitPaths(opts, units, "non-blocking", rnr.nonBlocking)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.controllerOpts = append(rnr.controllerOpts, WithNonBlocking(nonBlocking...))
+	}
+
+	if opts.MemoryBudget > 0 && len(opts.UnitMemoryEstimates) > 0 {
+		estimates, err := resolveUnitMemoryEstimates(opts, units, opts.UnitMemoryEstimates)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.controllerOpts = append(rnr.controllerOpts, WithMemoryBudget(opts.MemoryBudget, func(unit *component.Unit) int {
+			return estimates[unit.Path()]
+		}))
+	}
+
+	if len(rnr.logLevels) > 0 {
+		levels, err := resolveUnitLogLevels(opts, units, rnr.logLevels)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.logLevels = levels
+	}
+
+	// The command of the options replaces a function set with WithOnSuccess.
+	if opts.UnitSuccessCmd != "" {
+		onSuccess, err := unitSuccessCmd(l, opts.UnitSuccessCmd)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr = rnr.WithOptions(WithOnSuccess(onSuccess, opts.FailOnUnitSuccessCmdError))
+	}
+
+	// The command of the options replaces a resolver set with WithPlanFileResolver.
+	if opts.PlanFileResolverCmd != "" {
+		resolver, err := planFileResolverCmd(l, opts.PlanFileResolverCmd)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr = rnr.WithOptions(WithPlanFileResolver(resolver))
+	}
+
+	// The overrides of the options come last, so that they decide over those set with WithUnitCommands.
+	if len(opts.UnitCommands) > 0 {
+		rnr = rnr.WithOptions(WithUnitCommands(opts.UnitCommands))
+	}
+
+	if len(rnr.commands) > 0 {
+		commands, err := resolveUnitCommands(opts, units, rnr.commands)
+		if err != nil {
+			return nil, err
+		}
+
+		rnr.commands = commands
+	}
+
+	if rnr.startWave > 0 {
+		assumed, err := applyStartWave(l, units, rnr.startWave)
+		if err != nil {
+			return nil, err
+		}
+
+		if rnr.assumedApplied == nil {
+			rnr.assumedApplied = make(map[string]bool, len(assumed))
+		}
+
+		maps.Copy(rnr.assumedApplied, assumed)
+	}
+
+	if len(rnr.matrix) > 0 {
+		expanded, variants, err := expandUnitMatrix(l, opts, units, rnr.matrix)
+		if err != nil {
+			return nil, err
+		}
+
+		units = expanded
+		rnr.Stack.Units = units
+		rnr.variants = variants
+
+		if rnr.pathDisplay != nil {
+			applyPathDisplay(units, rnr.pathDisplay)
+		}
+	}
+
 	// Build queue from resolved units (which have canonical absolute paths).
 	// Filter out excluded units so they are not shown in lists or scheduled.
 	filtered := filterUnitsToComponents(units)
@@ -313,6 +563,20 @@ func filterUnitsToComponents(units []*component.Unit) component.Components {
 func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.TerragruntOptions, r *report.Report) error {
 	terraformCmd := stackOpts.TerraformCommand
 
+	if stackOpts.FailOnEmptyRun && len(rnr.queue.Entries) == 0 {
+		return tgerrors.New(EmptyRunError{WorkingDir: stackOpts.WorkingDir})
+	}
+
+	if r != nil && rnr.pathDisplay != nil {
+		r.WithPathDisplay(rnr.pathDisplay)
+	}
+
+	if terraformCmd == tf.CommandNameApply && !stackOpts.TerraformCliArgs.HasPlanFile() {
+		if err := rnr.VerifyPlanFiles(stackOpts); err != nil {
+			return err
+		}
+	}
+
 	if stackOpts.OutputFolder != "" {
 		for _, u := range rnr.Stack.Units {
 			planFile := u.OutputFile(stackOpts.RootWorkingDir, stackOpts.OutputFolder)
@@ -322,30 +586,38 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 		}
 	}
 
-	// Mutate stackOpts CLI args at the top level - these get cloned into per-unit opts later
-	if slices.Contains(config.TerraformCommandsNeedInput, terraformCmd) {
-		stackOpts.TerraformCliArgs.InsertFlag(0, "-input=false")
+	if rnr.cpuProfileDir != "" && stackOpts.Parallelism != 1 {
+		l.Warnf("CPU profiles are process-wide, so unit profiles are only meaningful with a parallelism of 1; units running concurrently with a profiled unit will not be profiled")
 	}
 
-	needsCliSync := false
-	isPlan := false
+	needsCliSync := prepareStackCliArgs(stackOpts)
+	isPlan := terraformCmd == tf.CommandNamePlan
 
-	switch terraformCmd {
-	case tf.CommandNameApply, tf.CommandNameDestroy:
-		if stackOpts.RunAllAutoApprove {
-			stackOpts.TerraformCliArgs.InsertFlag(0, "-auto-approve")
-		}
+	strictReporting := rnr.strictReporting || stackOpts.StrictReporting
 
-		needsCliSync = true
-	case tf.CommandNameShow:
-		needsCliSync = true
-	case tf.CommandNamePlan:
-		isPlan = true
-		needsCliSync = true
+	unitRunnerOpts := rnr.unitRunnerOpts
+	if strictReporting {
+		unitRunnerOpts = append(slices.Clone(unitRunnerOpts), common.WithStrictReporting())
 	}
 
-	if slices.Contains(config.TerraformCommandsNeedInput, terraformCmd) {
-		needsCliSync = true
+	if stackOpts.FailOnStalePlan {
+		unitRunnerOpts = append(slices.Clone(unitRunnerOpts), common.WithStalePlanCheck())
+	}
+
+	if stackOpts.MinFreeDiskBytes > 0 {
+		unitRunnerOpts = append(slices.Clone(unitRunnerOpts), common.WithMinFreeDisk(uint64(stackOpts.MinFreeDiskBytes)))
+	}
+
+	requireFullChain := rnr.requireFullChain || stackOpts.RequireFullDependencyChain
+
+	groupedOutput, groupedOutputLimit := rnr.groupedOutput || stackOpts.GroupedOutput, rnr.groupedOutputLimit
+	if groupedOutputLimit <= 0 {
+		groupedOutputLimit = DefaultGroupedOutputLimit
+	}
+
+	outputBudget := rnr.outputBudget
+	if outputBudget == nil && stackOpts.UnitOutputMemoryBudget > 0 {
+		outputBudget = NewOutputBudget(stackOpts.UnitOutputMemoryBudget)
 	}
 
 	// Pre-allocate plan error buffers keyed by unit path
@@ -356,7 +628,20 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 			planErrorBuffers[u.Path()] = &bytes.Buffer{}
 		}
 
-		defer rnr.summarizePlanAllErrors(l, planErrorBuffers)
+		// The dependency paths are read before the run, as finished units may release their configuration.
+		defer rnr.summarizePlanAllErrors(l, planErrorBuffers, declaredDependencyPaths(rnr.Stack.Units))
+	}
+
+	// With strict reporting, the failures to record the result of a unit fail the run, instead of only being logged.
+	var reportingErrs *tgerrors.MultiError
+
+	reportingFailure := func(unitPath, format string, reportErr error) {
+		if strictReporting {
+			reportingErrs = reportingErrs.Append(tgerrors.New(common.ReportingError{UnitPath: unitPath, Err: reportErr}))
+			return
+		}
+
+		l.Errorf(format, unitPath, reportErr)
 	}
 
 	// Emit report entries for excluded units that haven't been reported yet.
@@ -382,7 +667,7 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 
 				run, err := r.EnsureRun(l, unitPath, ensureOpts...)
 				if err != nil {
-					l.Errorf("Error ensuring run for unit %s: %v", unitPath, err)
+					reportingFailure(unitPath, "Error ensuring run for unit %s: %v", err)
 					continue
 				}
 
@@ -393,30 +678,59 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 					// Determine the reason for exclusion
 					// External dependencies that are assumed already applied are excluded with --queue-exclude-external
 					reason := report.ReasonExcludeBlock
+					endOpts := []report.EndOption{report.WithResult(report.ResultExcluded)}
 
-					if err := r.EndRun(
-						l,
-						run.Path,
-						report.WithResult(report.ResultExcluded),
-						report.WithReason(reason),
-					); err != nil {
-						l.Errorf("Error ending run for unit %s: %v", unitPath, err)
+					if rnr.assumedApplied[unitPath] {
+						reason = report.ReasonAssumedApplied
+					}
+
+					if skipReason, ok := rnr.skipReasons[unitPath]; ok {
+						reason = report.ReasonSkipPredicate
+						endOpts = append(endOpts, report.WithCauseSkipPredicate(skipReason))
+					}
+
+					endOpts = append(endOpts, report.WithReason(reason))
+
+					if err := r.EndRun(l, run.Path, endOpts...); err != nil {
+						reportingFailure(unitPath, "Error ending run for unit %s: %v", err)
 					}
 				}
 			}
 		}
 	}
 
+	// The tasks only run once the controller is set, and consult it about the failures it ignored.
+	var controller *Controller
+
 	task := func(ctx context.Context, u *component.Unit) error {
+		// A run expanded from the matrix of a unit runs the configuration of that unit.
+		unit := u
+
+		variant, isVariant := rnr.variants[u.Path()]
+		if isVariant {
+			unit = variant.unit
+		}
+
 		// Build per-unit opts and logger on demand
-		unitOpts, unitLogger, err := BuildUnitOpts(l, stackOpts, u)
+		unitOpts, unitLogger, err := BuildUnitOpts(l, stackOpts, unit)
 		if err != nil {
 			return tgerrors.Errorf("failed to build opts for unit %s: %w", u.Path(), err)
 		}
 
-		// Sync CLI args from stackOpts into unit opts
-		if needsCliSync {
-			syncUnitCliArgs(l, stackOpts, unitOpts, u)
+		if isVariant && !stackOpts.Writers.LogShowAbsPaths {
+			unitLogger = unitLogger.WithField(placeholders.WorkDirKeyName, u.DisplayPath())
+		}
+
+		if level, ok := rnr.logLevels[unit.Path()]; ok {
+			unitLogger = unitLogger.WithOptions(log.WithLevel(level))
+		}
+
+		rnr.setUnitCliArgs(l, stackOpts, unitOpts, u, needsCliSync)
+
+		if requireFullChain && unitOpts.TerraformCommand == tf.CommandNameApply {
+			if err := rnr.checkDependencyChain(controller, u); err != nil {
+				return err
+			}
 		}
 
 		// Wrap ErrWriter with plan error buffer for plan commands
@@ -433,9 +747,37 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 			"terragrunt_config_path": unitOpts.TerragruntConfigPath,
 		}, func(childCtx context.Context) error {
 			// Wrap the writer to buffer unit-scoped output
-			unitWriter := NewUnitWriter(unitOpts.Writers.Writer)
+			unitWriter := NewUnitWriter(unitOpts.Writers.Writer).WithBudget(outputBudget)
+			if rnr.outputFilter != nil {
+				unitWriter = unitWriter.WithFilter(u.Path(), rnr.outputFilter)
+			}
+
+			if groupedOutput {
+				unitWriter = unitWriter.WithGrouping(u.DisplayPath(), groupedOutputLimit)
+			}
+
 			unitOpts.Writers.Writer = unitWriter
-			unitRunner := common.NewUnitRunner(u)
+
+			// Stderr and the log lines of the unit pass through the filter too, as OpenTofu/Terraform print the
+			// values of their error diagnostics there. In grouped mode, they are held the same way, in a block of
+			// their own.
+			var errWriter *UnitWriter
+
+			if groupedOutput || rnr.outputFilter != nil {
+				errWriter = NewUnitWriter(unitOpts.Writers.ErrWriter).WithBudget(outputBudget)
+				if rnr.outputFilter != nil {
+					errWriter = errWriter.WithFilter(u.Path(), rnr.outputFilter)
+				}
+
+				if groupedOutput {
+					errWriter = errWriter.WithGrouping(u.DisplayPath()+" (stderr)", groupedOutputLimit)
+				}
+
+				unitOpts.Writers.ErrWriter = errWriter
+				unitLogger = unitLogger.WithOptions(log.WithOutput(errWriter))
+			}
+
+			unitRunner := common.NewUnitRunner(u, unitRunnerOpts...)
 
 			// Get credentials BEFORE config parsing — sops_decrypt_file() and
 			// get_aws_account_id() in locals need auth-provider credentials
@@ -474,25 +816,97 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 				err = flushErr
 			}
 
+			if errWriter != nil {
+				if flushErr := errWriter.Flush(); flushErr != nil && err == nil {
+					err = flushErr
+				}
+			}
+
 			return err
 		})
 	}
 
+	if err := rnr.resolveAssumedOutputs(ctx, l); err != nil {
+		return err
+	}
+
 	rnr.queue.FailFast = stackOpts.FailFast
 	rnr.queue.IgnoreDependencyOrder = stackOpts.IgnoreDependencyOrder
 	// Allow continuing the queue when dependencies fail if requested via CLI
 	rnr.queue.IgnoreDependencyErrors = stackOpts.IgnoreDependencyErrors
-	controller := NewController(
+	controllerOpts := append([]ControllerOption{
+		WithRunner(task),
+		WithMaxConcurrency(rnr.parallelism(l, stackOpts)),
+		WithContinueOnError(stackOpts.ContinueOnError),
+		WithMaxReportedErrors(stackOpts.MaxReportedErrors),
+		WithMaxStartsPerSecond(stackOpts.MaxStartsPerSecond),
+		WithFanOutWarning(stackOpts.FanOutWarningThreshold),
+		WithProgressLogging(stackOpts.ProgressLogging),
+		WithReleaseFinishedUnits(stackOpts.ReleaseFinishedUnits),
+		WithParallelismSchedule(stackOpts.ParallelismSchedule...),
+		WithEventSocket(stackOpts.EventSocketPath),
+		WithResultWebhook(stackOpts.ResultWebhook, r),
+		WithReportSnapshots(r, stackOpts.ReportFile, time.Duration(stackOpts.ReportSnapshotInterval)*time.Second),
+	}, rnr.controllerOpts...)
+
+	if stackOpts.FlakyHuntSeed != 0 {
+		controllerOpts = append(controllerOpts, WithFlakyHunt(stackOpts.FlakyHuntSeed))
+	}
+
+	if stackOpts.ParallelismFile != "" {
+		controllerOpts = append(controllerOpts, WithParallelismControl(parallelismFromFile(l, stackOpts.ParallelismFile, parallelismFileInterval)))
+	}
+
+	if stackOpts.ConfirmEachUnit {
+		controllerOpts = append(controllerOpts, WithApproveUnit(PromptApproveUnit(l, stackOpts), true))
+	}
+
+	if stackOpts.Canaries {
+		controllerOpts = append(controllerOpts, WithCanaries(nil))
+	}
+
+	if stackOpts.WaveBarrierOnFailure {
+		controllerOpts = append(controllerOpts, WithWaveBarrier(func(_ int, results RunSummary) bool {
+			return len(results.Failed) > 0
+		}))
+	}
+
+	if stackOpts.GroupErrorsByType {
+		controllerOpts = append(controllerOpts, WithErrorGroups(nil))
+	}
+
+	if stackOpts.GroupErrorsBySubtree {
+		controllerOpts = append(controllerOpts, WithErrorSubtrees(nil))
+	}
+
+	if rnr.outputFilter != nil {
+		controllerOpts = append(controllerOpts, WithResultWebhookFilter(rnr.outputFilter))
+	}
+
+	// The runs expanded from the matrix of a unit share its working directory, so they run one at a time.
+	if len(rnr.variants) > 0 {
+		controllerOpts = append(controllerOpts, WithUnitLocks(func(path string) *sync.Mutex {
+			if variant, ok := rnr.variants[path]; ok {
+				return variant.lock
+			}
+
+			return nil
+		}))
+	}
+
+	controller = NewController(
 		rnr.queue,
 		rnr.Stack.Units,
-		WithRunner(task),
-		WithMaxConcurrency(stackOpts.Parallelism),
+		controllerOpts...,
 	)
 
 	err := controller.Run(ctx, l)
 
-	// Emit report entries for early exit and failed units after controller completes
+	// Emit report entries for early exit, failed and skipped units after controller completes
 	if r != nil {
+		timeouts := dependencyWaitTimeouts(err)
+		cancelled := controller.Cancelled()
+
 		// Build a quick lookup of queue entry status by path to avoid nested scans
 		statusByPath := make(map[string]queue.Status, len(rnr.queue.Entries))
 		for _, qe := range rnr.queue.Entries {
@@ -500,11 +914,11 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 		}
 
 		for _, entry := range rnr.queue.Entries {
-			// Handle both early exit and failed units to ensure they're in the report
-			if entry.Status == queue.StatusEarlyExit || entry.Status == queue.StatusFailed {
+			// Handle early exit, failed and skipped units to ensure they're in the report
+			if entry.Status == queue.StatusEarlyExit || entry.Status == queue.StatusFailed || entry.Status == queue.StatusSkipped {
 				unit := rnr.Stack.FindUnitByPath(entry.Component.Path())
 				if unit == nil {
-					l.Warnf("Could not find unit for entry: %s", entry.Component.Path())
+					l.Warnf("Could not find unit for entry: %s", entry.Component.DisplayPath())
 					continue
 				}
 
@@ -525,7 +939,7 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 
 				run, reportErr := r.EnsureRun(l, unitPath, ensureOpts...)
 				if reportErr != nil {
-					l.Errorf("Error ensuring run for unit %s: %v", unitPath, reportErr)
+					reportingFailure(unitPath, "Error ensuring run for unit %s: %v", reportErr)
 					continue
 				}
 
@@ -540,13 +954,25 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 						break
 					}
 
-					if status == queue.StatusEarlyExit && failedAncestor == "" {
+					if (status == queue.StatusEarlyExit || status == queue.StatusSkipped) && failedAncestor == "" {
 						// Use early exit dependency as fallback
 						failedAncestor = filepath.Base(dep.Path())
 					}
 				}
 
 				switch entry.Status { //nolint:exhaustive
+				case queue.StatusSkipped:
+					endOpts := []report.EndOption{
+						report.WithResult(report.ResultExcluded),
+						report.WithReason(report.ReasonUserSkipped),
+					}
+					if failedAncestor != "" {
+						endOpts = append(endOpts, report.WithCauseAncestorExit(failedAncestor))
+					}
+
+					if endErr := r.EndRun(l, run.Path, endOpts...); endErr != nil {
+						reportingFailure(unitPath, "Error ending run for skipped unit %s: %v", endErr)
+					}
 				case queue.StatusEarlyExit:
 					endOpts := []report.EndOption{
 						report.WithResult(report.ResultEarlyExit),
@@ -557,7 +983,7 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 					}
 
 					if endErr := r.EndRun(l, run.Path, endOpts...); endErr != nil {
-						l.Errorf("Error ending run for early exit unit %s: %v", unitPath, endErr)
+						reportingFailure(unitPath, "Error ending run for early exit unit %s: %v", endErr)
 					}
 				case queue.StatusFailed:
 					// For failed units, check if they failed due to dependency errors
@@ -566,8 +992,26 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 						report.WithResult(report.ResultFailed),
 						report.WithReason(report.ReasonRunError),
 					}
-					if failedAncestor != "" {
-						// If a dependency failed, treat this as early exit due to ancestor error
+					if cancelErr, ok := cancelled[unitPath]; ok {
+						reason := report.ReasonCancelled
+						if tgerrors.Is(cancelErr, context.DeadlineExceeded) {
+							reason = report.ReasonDeadlineExceeded
+						}
+
+						endOpts = []report.EndOption{
+							report.WithResult(report.ResultFailed),
+							report.WithReason(reason),
+							report.WithCauseRunError(cancelErr.Error()),
+						}
+					} else if timeout, ok := timeouts[unitPath]; ok {
+						endOpts = []report.EndOption{
+							report.WithResult(report.ResultFailed),
+							report.WithReason(report.ReasonDependencyTimeout),
+							report.WithCauseAncestorExit(filepath.Base(timeout.Dependencies[0])),
+						}
+					} else if failedAncestor != "" && !rnr.queue.IgnoreDependencyErrors {
+						// If a dependency failed, treat this as early exit due to ancestor error.
+						// When dependency errors are ignored, the unit did run and failed on its own.
 						endOpts = []report.EndOption{
 							report.WithResult(report.ResultEarlyExit),
 							report.WithReason(report.ReasonAncestorError),
@@ -576,49 +1020,265 @@ func (rnr *Runner) Run(ctx context.Context, l log.Logger, stackOpts *options.Ter
 					}
 
 					if endErr := r.EndRun(l, run.Path, endOpts...); endErr != nil {
-						l.Errorf("Error ending run for failed unit %s: %v", unitPath, endErr)
+						reportingFailure(unitPath, "Error ending run for failed unit %s: %v", endErr)
 					}
 				}
 			}
 		}
 	}
 
+	if reportingErr := reportingErrs.ErrorOrNil(); reportingErr != nil {
+		return tgerrors.Join(err, reportingErr)
+	}
+
 	return err
 }
 
-// LogUnitDeployOrder logs the order of units to be processed.
-// When the dag-queue-display experiment is enabled, the output is rendered as a DAG tree
-// showing dependency relationships between units. Otherwise, a flat list is shown.
-func (rnr *Runner) LogUnitDeployOrder(l log.Logger, isDestroy bool, showAbsPaths bool, experiments experiment.Experiments) error {
-	if experiments.Evaluate(experiment.DAGQueueDisplay) {
-		return rnr.logUnitDeployOrderDAG(l, isDestroy, showAbsPaths)
+// OutputResolver returns the outputs of a unit that is not run because it is assumed to be applied, in the
+// format of `terraform output -json`, e.g. from a cache or from the state of the unit. Returning nil outputs
+// leaves the unit's outputs to be fetched from its state as usual.
+type OutputResolver func(ctx context.Context, l log.Logger, unit *component.Unit) ([]byte, error)
+
+// resolveAssumedOutputs resolves the outputs of the units assumed to be applied with the output resolver, and
+// makes them available to the dependency blocks of the units that run.
+func (rnr *Runner) resolveAssumedOutputs(ctx context.Context, l log.Logger) error {
+	if rnr.outputs == nil {
+		return nil
 	}
 
-	return rnr.logUnitDeployOrderFlat(l, showAbsPaths)
-}
+	for path := range rnr.assumedApplied {
+		unit := rnr.Stack.FindUnitByPath(path)
+		if unit == nil {
+			continue
+		}
 
-// logUnitDeployOrderDAG renders the queue as a DAG tree showing dependency relationships.
-// Independent units (siblings in the tree) may run concurrently.
-func (rnr *Runner) logUnitDeployOrderDAG(l log.Logger, isDestroy bool, showAbsPaths bool) error {
-	components := rnr.queueComponents()
-	listed := rnr.buildListedComponents(components, isDestroy, showAbsPaths)
+		outputs, err := rnr.outputs(ctx, l, unit)
+		if err != nil {
+			return tgerrors.Errorf("failed to resolve outputs of unit %s assumed to be applied: %w", path, err)
+		}
 
-	shouldColor := !l.Formatter().DisabledColors() && !stdout.IsRedirected()
-	s := dag.NewTreeStyler(shouldColor)
-	t := dag.GenerateDAGTree(listed, s)
-	t = s.Style(t)
+		if outputs == nil {
+			continue
+		}
 
-	header := deployOrderHeader(isDestroy)
+		configPath := config.GetDefaultConfigPath(path)
+		if unit.ConfigFile() != "" {
+			configPath = filepath.Join(path, unit.ConfigFile())
+		}
 
-	l.Info(header + t.String())
+		config.CacheDependencyOutputJSON(ctx, configPath, outputs)
+
+		l.Debugf("Resolved outputs of unit %s assumed to be applied", path)
+	}
 
 	return nil
 }
 
-// logUnitDeployOrderFlat renders the queue as a flat list of units.
-func (rnr *Runner) logUnitDeployOrderFlat(l log.Logger, showAbsPaths bool) error {
-	var sb strings.Builder
-
+// parallelism returns the number of units to run concurrently. With ParallelismFile, this is the number the
+// file holds, if valid. With ParallelismAuto, this is the width of the widest wave of the queue, capped by
+// the configured parallelism and the number of CPUs.
+func (rnr *Runner) parallelism(l log.Logger, stackOpts *options.TerragruntOptions) int {
+	if stackOpts.ParallelismFile != "" {
+		// The file is read before the run starts, so that no unit starts with another parallelism.
+		if parallelism, err := readParallelismFile(stackOpts.ParallelismFile); err == nil {
+			return parallelism
+		}
+	}
+
+	if !stackOpts.ParallelismAuto {
+		return stackOpts.Parallelism
+	}
+
+	parallelism := autoParallelism(rnr.queue.Waves(), stackOpts.Parallelism, runtime.NumCPU())
+
+	l.Infof("Running up to %d units concurrently, based on the width of the dependency graph", parallelism)
+
+	return parallelism
+}
+
+// autoParallelism returns the number of entries of the widest wave, capped by maxParallelism and cpus.
+// The result is always at least 1.
+func autoParallelism(waves []queue.Entries, maxParallelism, cpus int) int {
+	width := 1
+
+	for _, wave := range waves {
+		width = max(width, len(wave))
+	}
+
+	if maxParallelism > 0 {
+		width = min(width, maxParallelism)
+	}
+
+	return max(min(width, cpus), 1)
+}
+
+// RunDestroyThenApply destroys the units of the stack in reverse dependency order, then applies them again
+// in dependency order, in a single pass over the same graph.
+//
+// Each phase runs over its own copy of the queue, so the phases do not affect each other's scheduling,
+// and records its runs in its own report. The apply phase is skipped if the destroy phase fails.
+func (rnr *Runner) RunDestroyThenApply(
+	ctx context.Context,
+	l log.Logger,
+	stackOpts *options.TerragruntOptions,
+	destroyReport, applyReport *report.Report,
+) error {
+	destroyQueue, err := rnr.queue.CloneForCommand(tf.CommandNameDestroy)
+	if err != nil {
+		return err
+	}
+
+	applyQueue, err := rnr.queue.CloneForCommand(tf.CommandNameApply)
+	if err != nil {
+		return err
+	}
+
+	l.Infof("Destroying %d units before applying them again", len(destroyQueue.Entries))
+
+	// The apply phase still needs the configuration of the units, so only it releases them.
+	destroyOpts := stackOpts.Clone()
+	destroyOpts.ReleaseFinishedUnits = false
+
+	if err := rnr.runPhase(ctx, l, destroyOpts, destroyQueue, tf.CommandNameDestroy, destroyReport); err != nil {
+		return tgerrors.Errorf("destroy phase failed, skipping apply phase: %w", err)
+	}
+
+	l.Infof("Applying %d units after destroying them", len(applyQueue.Entries))
+
+	if err := rnr.runPhase(ctx, l, stackOpts, applyQueue, tf.CommandNameApply, applyReport); err != nil {
+		return tgerrors.Errorf("apply phase failed: %w", err)
+	}
+
+	return nil
+}
+
+// runPhase runs the given command over the given queue. For the duration of the phase, the discovery
+// context of every unit is switched to the command, so that units do not run the command they were
+// discovered for instead.
+func (rnr *Runner) runPhase(
+	ctx context.Context,
+	l log.Logger,
+	stackOpts *options.TerragruntOptions,
+	q *queue.Queue,
+	cmd string,
+	r *report.Report,
+) error {
+	phaseOpts := stackOpts.Clone()
+	phaseOpts.TerraformCommand = cmd
+	phaseOpts.TerraformCliArgs = stackOpts.TerraformCliArgs.Clone().SetCommand(cmd)
+
+	originals := make(map[*component.Unit]*component.DiscoveryContext, len(rnr.Stack.Units))
+
+	for _, u := range rnr.Stack.Units {
+		dc := u.DiscoveryContext()
+		originals[u] = dc
+
+		phaseCtx := &component.DiscoveryContext{}
+		if dc != nil {
+			phaseCtx = dc.Copy()
+		}
+
+		phaseCtx.Cmd = cmd
+		phaseCtx.Args = nil
+		u.SetDiscoveryContext(phaseCtx)
+	}
+
+	defer func() {
+		for u, dc := range originals {
+			u.SetDiscoveryContext(dc)
+		}
+	}()
+
+	phase := *rnr
+	phase.queue = q
+
+	return phase.Run(ctx, l, phaseOpts, r)
+}
+
+// ValidateConfigs parses the configuration of every unit in the stack, in parallel, without running Terraform.
+// Dependency outputs are not fetched, so mocked outputs are used where they are declared.
+//
+// Units are validated regardless of dependency order and a failing unit does not prevent the others from being
+// validated: the returned error aggregates the errors of all units whose configuration is invalid.
+func (rnr *Runner) ValidateConfigs(ctx context.Context, l log.Logger, stackOpts *options.TerragruntOptions) error {
+	q, err := rnr.queue.CloneForCommand(stackOpts.TerraformCommand)
+	if err != nil {
+		return err
+	}
+
+	q.FailFast = false
+	q.IgnoreDependencyOrder = true
+	q.IgnoreDependencyErrors = true
+
+	validate := func(ctx context.Context, u *component.Unit) error {
+		if u.Excluded() {
+			return nil
+		}
+
+		unitOpts, unitLogger, err := BuildUnitOpts(l, stackOpts, u)
+		if err != nil {
+			return tgerrors.Errorf("failed to build opts for unit %s: %w", u.Path(), err)
+		}
+
+		if _, err := creds.ObtainCredsForParsing(ctx, unitLogger, unitOpts.AuthProviderCmd, unitOpts.Env, configbridge.ShellRunOptsFromOpts(unitOpts)); err != nil {
+			return err
+		}
+
+		parseCtx, pctx := configbridge.NewParsingContext(ctx, unitLogger, unitOpts)
+		pctx.SkipOutput = true
+
+		if _, err := config.ReadTerragruntConfig(parseCtx, unitLogger, pctx, pctx.ParserOptions); err != nil {
+			return err
+		}
+
+		unitLogger.Debugf("Configuration of %s is valid", u.Path())
+
+		return nil
+	}
+
+	l.Infof("Validating the configuration of %d units", len(q.Entries))
+
+	return NewController(
+		q,
+		rnr.Stack.Units,
+		WithRunner(validate),
+		WithMaxConcurrency(stackOpts.Parallelism),
+	).Run(ctx, l)
+}
+
+// LogUnitDeployOrder logs the order of units to be processed.
+// When the dag-queue-display experiment is enabled, the output is rendered as a DAG tree
+// showing dependency relationships between units. Otherwise, a flat list is shown.
+func (rnr *Runner) LogUnitDeployOrder(l log.Logger, isDestroy bool, showAbsPaths bool, experiments experiment.Experiments) error {
+	if experiments.Evaluate(experiment.DAGQueueDisplay) {
+		return rnr.logUnitDeployOrderDAG(l, isDestroy, showAbsPaths)
+	}
+
+	return rnr.logUnitDeployOrderFlat(l, showAbsPaths)
+}
+
+// logUnitDeployOrderDAG renders the queue as a DAG tree showing dependency relationships.
+// Independent units (siblings in the tree) may run concurrently.
+func (rnr *Runner) logUnitDeployOrderDAG(l log.Logger, isDestroy bool, showAbsPaths bool) error {
+	components := rnr.queueComponents()
+	listed := rnr.buildListedComponents(components, isDestroy, showAbsPaths)
+
+	shouldColor := !l.Formatter().DisabledColors() && !stdout.IsRedirected()
+	s := dag.NewTreeStyler(shouldColor)
+	t := dag.GenerateDAGTree(listed, s)
+	t = s.Style(t)
+
+	header := deployOrderHeader(isDestroy)
+
+	l.Info(header + t.String())
+
+	return nil
+}
+
+// logUnitDeployOrderFlat renders the queue as a flat list of units.
+func (rnr *Runner) logUnitDeployOrderFlat(l log.Logger, showAbsPaths bool) error {
+	var sb strings.Builder
+
 	for _, unit := range rnr.queue.Entries {
 		unitPath := unit.Component.DisplayPath()
 		if showAbsPaths {
@@ -725,8 +1385,22 @@ func (rnr *Runner) ListStackDependentUnits() map[string][]string {
 	return dependentUnits
 }
 
+// declaredDependencyPaths returns the paths listed in the dependencies block of each unit, keyed by unit path.
+func declaredDependencyPaths(units []*component.Unit) map[string][]string {
+	paths := make(map[string][]string, len(units))
+
+	for _, unit := range units {
+		if cfg := unit.Config(); cfg != nil && cfg.Dependencies != nil && len(cfg.Dependencies.Paths) > 0 {
+			paths[unit.Path()] = cfg.Dependencies.Paths
+		}
+	}
+
+	return paths
+}
+
 // summarizePlanAllErrors summarizes all errors encountered during the plan phase across all units in the stack.
-func (rnr *Runner) summarizePlanAllErrors(l log.Logger, errorStreams map[string]*bytes.Buffer) {
+// The dependency paths declared by each unit are given keyed by unit path.
+func (rnr *Runner) summarizePlanAllErrors(l log.Logger, errorStreams map[string]*bytes.Buffer, dependencyPaths map[string][]string) {
 	for _, unit := range rnr.Stack.Units {
 		buf := errorStreams[unit.Path()]
 		if buf == nil {
@@ -744,9 +1418,8 @@ func (rnr *Runner) summarizePlanAllErrors(l log.Logger, errorStreams map[string]
 			var dependenciesMsg string
 
 			if len(unit.Dependencies()) > 0 {
-				cfg := unit.Config()
-				if cfg != nil && cfg.Dependencies != nil && len(cfg.Dependencies.Paths) > 0 {
-					dependenciesMsg = fmt.Sprintf(" contains dependencies to %v and", cfg.Dependencies.Paths)
+				if paths := dependencyPaths[unit.Path()]; len(paths) > 0 {
+					dependenciesMsg = fmt.Sprintf(" contains dependencies to %v and", paths)
 				} else {
 					dependenciesMsg = " contains dependencies and"
 				}
@@ -891,6 +1564,12 @@ func FilterDiscoveredUnits(discovered component.Components, units []*component.U
 	return filtered
 }
 
+// unitSpanAttributes adds the path of the unit to the attributes of the spans opened for it, so that the spans
+// of its run and of the JSON conversion of its plan can be told apart from those of the other units.
+func unitSpanAttributes(ctx context.Context, unit *component.Unit) context.Context {
+	return telemetry.ContextWithSpanAttributes(ctx, map[string]any{"unit_path": unit.Path()})
+}
+
 // WithOptions updates the stack with the provided options.
 func (rnr *Runner) WithOptions(opts ...common.Option) *Runner {
 	for _, opt := range opts {
@@ -917,7 +1596,7 @@ func applyPreventDestroyExclusions(l log.Logger, units []*component.Unit) {
 			protectedUnits[unit.Path()] = true
 			unit.SetExcluded(true)
 
-			l.Debugf("Unit %s is protected by prevent_destroy flag", unit.Path())
+			l.Debugf("Unit %s is protected by prevent_destroy flag", unit.DisplayPath())
 		}
 	}
 
@@ -940,7 +1619,7 @@ func applyPreventDestroyExclusions(l log.Logger, units []*component.Unit) {
 		if dependencyPaths[unit.Path()] && !protectedUnits[unit.Path()] {
 			unit.SetExcluded(true)
 
-			l.Debugf("Unit %s is excluded because it's a dependency of a protected unit", unit.Path())
+			l.Debugf("Unit %s is excluded because it's a dependency of a protected unit", unit.DisplayPath())
 		}
 	}
 }
@@ -970,6 +1649,402 @@ func applyFilterAllowDestroyExclusions(l log.Logger, opts *options.TerragruntOpt
 	}
 }
 
+// unitTarget describes a unit to run together with the units it needs.
+type unitTarget struct {
+	path              string
+	includeDependents bool
+}
+
+// applyTargetExclusions excludes every unit outside the transitive-dependency closure of the target unit,
+// so that only the target and the units it needs are run. When includeDependents is set,
+// the transitive dependents of the target are kept as well.
+func applyTargetExclusions(l log.Logger, opts *options.TerragruntOptions, units []*component.Unit, target *unitTarget) error {
+	targetUnit, err := resolveUnit(opts, units, target.path, "target unit %s")
+	if err != nil {
+		return err
+	}
+
+	targetPath := targetUnit.Path()
+
+	keep := map[string]bool{targetPath: true}
+	collectDependencies(targetUnit, keep)
+
+	if target.includeDependents {
+		collectDependents(units, targetPath, keep)
+	}
+
+	for _, unit := range units {
+		if keep[unit.Path()] || unit.Excluded() {
+			continue
+		}
+
+		unit.SetExcluded(true)
+
+		l.Debugf("Unit %s is excluded because it is not needed by target %s", unit.DisplayPath(), targetPath)
+	}
+
+	return nil
+}
+
+// applyAssumeApplied excludes every unit that is not one of the given targets from the run, and returns
+// the paths of the units it excluded. Excluded units are dropped from the queue, and the queue treats
+// dependencies outside of it as already applied, so the targets still run after them.
+func applyAssumeApplied(l log.Logger, opts *options.TerragruntOptions, units []*component.Unit, targets []string) (map[string]bool, error) {
+	keep := make(map[string]bool, len(targets))
+
+	for _, target := range targets {
+		targetUnit, err := resolveUnit(opts, units, target, "target unit %s")
+		if err != nil {
+			return nil, err
+		}
+
+		keep[targetUnit.Path()] = true
+	}
+
+	assumed := make(map[string]bool, len(units))
+
+	for _, unit := range units {
+		if keep[unit.Path()] || unit.Excluded() {
+			continue
+		}
+
+		unit.SetExcluded(true)
+		assumed[unit.Path()] = true
+
+		l.Debugf("Unit %s is assumed to be already applied", unit.DisplayPath())
+	}
+
+	return assumed, nil
+}
+
+// StartWaveFromGraph returns the index of the first dependency wave of a queue serialized with
+// Queue.MarshalGraph in which a unit did not succeed, the wave to resume the run from with WithStartWave.
+func StartWaveFromGraph(data []byte) (int, error) {
+	q, err := queue.UnmarshalGraph(data)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.FirstUnfinishedWave(), nil
+}
+
+// applyStartWave excludes the units of the dependency waves before the given one from the run, and returns
+// the paths of the units it excluded. Like with applyAssumeApplied, the units of the later waves still run
+// after them, against their existing state.
+func applyStartWave(l log.Logger, units []*component.Unit, startWave int) (map[string]bool, error) {
+	q, err := queue.NewQueue(filterUnitsToComponents(units))
+	if err != nil {
+		return nil, err
+	}
+
+	waves := q.Waves()
+	if startWave >= len(waves) {
+		return nil, tgerrors.Errorf("start wave %d is out of range, the run has %d waves", startWave, len(waves))
+	}
+
+	assumed := make(map[string]bool)
+
+	for _, wave := range waves[:startWave] {
+		for _, e := range wave {
+			unit, ok := e.Component.(*component.Unit)
+			if !ok {
+				continue
+			}
+
+			unit.SetExcluded(true)
+			assumed[unit.Path()] = true
+
+			l.Debugf("Unit %s is assumed to be already applied, its wave is before wave %d", unit.DisplayPath(), startWave)
+		}
+	}
+
+	return assumed, nil
+}
+
+// UnchangedUnits controls how the units without changed files are treated by WithChangedFiles.
+type UnchangedUnits int
+
+const (
+	// UnchangedAssumeApplied treats the unchanged units as already applied, and reports them as such.
+	UnchangedAssumeApplied UnchangedUnits = iota
+	// UnchangedExcluded excludes the unchanged units, and reports them as excluded.
+	UnchangedExcluded
+)
+
+type changedFiles struct {
+	files             []string
+	unchanged         UnchangedUnits
+	includeDependents bool
+}
+
+// applyChangedFiles excludes every unit whose directory contains none of the changed files, and returns the
+// paths of the units it excluded. A changed file belongs to the deepest unit whose directory contains it, so
+// a change to a nested unit does not affect the unit above it. When includeDependents is set, the transitive
+// dependents of the changed units are kept as well.
+func applyChangedFiles(l log.Logger, opts *options.TerragruntOptions, units []*component.Unit, changes *changedFiles) map[string]bool {
+	keep := make(map[string]bool, len(units))
+
+	for _, file := range changes.files {
+		filePath := file
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(opts.WorkingDir, filePath)
+		}
+
+		filePath = filepath.Clean(filePath)
+
+		var owner *component.Unit
+
+		for _, unit := range units {
+			if !util.HasPathPrefix(filePath, unit.Path()) {
+				continue
+			}
+
+			if owner == nil || len(unit.Path()) > len(owner.Path()) {
+				owner = unit
+			}
+		}
+
+		if owner != nil {
+			keep[owner.Path()] = true
+		}
+	}
+
+	if changes.includeDependents {
+		for path := range maps.Clone(keep) {
+			collectDependents(units, path, keep)
+		}
+	}
+
+	unchanged := make(map[string]bool, len(units))
+
+	for _, unit := range units {
+		if keep[unit.Path()] || unit.Excluded() {
+			continue
+		}
+
+		unit.SetExcluded(true)
+		unchanged[unit.Path()] = true
+
+		l.Debugf("Unit %s is skipped because none of its files changed", unit.DisplayPath())
+	}
+
+	return unchanged
+}
+
+// SkipPredicate decides whether a unit is skipped based on its configuration, and returns the reason it is
+// skipped for. The configuration of the unit is available through unit.Config(), and may be nil.
+type SkipPredicate func(unit *component.Unit) (bool, string)
+
+// applySkipPredicate excludes every unit the predicate matches, and returns the reasons of the units it
+// excluded, keyed by path.
+func applySkipPredicate(l log.Logger, units []*component.Unit, skip SkipPredicate) map[string]string {
+	reasons := make(map[string]string)
+
+	for _, unit := range units {
+		if unit.Excluded() {
+			continue
+		}
+
+		skipped, reason := skip(unit)
+		if !skipped {
+			continue
+		}
+
+		unit.SetExcluded(true)
+		reasons[unit.Path()] = reason
+
+		l.Debugf("Unit %s is skipped by the skip predicate: %s", unit.DisplayPath(), reason)
+	}
+
+	return reasons
+}
+
+// excludeGlob is a compiled exclude pattern, which includes the units it matches instead when negated.
+type excludeGlob struct {
+	matcher glob.Matcher
+	negated bool
+}
+
+// applyExcludeGlobs excludes every unit whose path matches the given patterns, the last matching pattern
+// deciding whether a unit is excluded or included again. Returns an error if a pattern is not a valid glob.
+func applyExcludeGlobs(l log.Logger, opts *options.TerragruntOptions, units []*component.Unit, patterns []string) error {
+	globs := make([]excludeGlob, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+
+		canonical, err := util.CanonicalPath(strings.TrimPrefix(pattern, "!"), opts.WorkingDir)
+		if err != nil {
+			return err
+		}
+
+		matcher, err := glob.Compile(filepath.ToSlash(canonical))
+		if err != nil {
+			return tgerrors.Errorf("invalid exclude glob %s: %w", pattern, err)
+		}
+
+		globs = append(globs, excludeGlob{matcher: matcher, negated: negated})
+	}
+
+	for _, unit := range units {
+		if unit.Excluded() {
+			continue
+		}
+
+		excluded := false
+
+		for _, g := range globs {
+			if g.matcher.Match(filepath.ToSlash(unit.Path())) {
+				excluded = !g.negated
+			}
+		}
+
+		if !excluded {
+			continue
+		}
+
+		unit.SetExcluded(true)
+
+		l.Debugf("Unit %s is excluded by an exclude glob", unit.DisplayPath())
+	}
+
+	return nil
+}
+
+// applyPathDisplay makes the units display their paths with the given function in logs.
+func applyPathDisplay(units []*component.Unit, display component.PathDisplayFunc) {
+	for _, unit := range units {
+		unit.SetPathDisplay(display)
+	}
+}
+
+// resolveUnit resolves the given unit path against the working directory and returns the discovered unit at
+// that path. The error returned when there is none names the unit with the given format, e.g. "target unit %s".
+func resolveUnit(opts *options.TerragruntOptions, units []*component.Unit, path, unitFormat string) (*component.Unit, error) {
+	unitPath := path
+	if !filepath.IsAbs(unitPath) {
+		unitPath = filepath.Join(opts.WorkingDir, unitPath)
+	}
+
+	unitPath = filepath.Clean(unitPath)
+
+	idx := slices.IndexFunc(units, func(u *component.Unit) bool { return u.Path() == unitPath })
+	if idx == -1 {
+		return nil, tgerrors.Errorf(unitFormat+" not found in discovered units", unitPath)
+	}
+
+	return units[idx], nil
+}
+
+// resolveUnitPaths resolves the given paths of quarantined or non-blocking units, as told by kind, against the
+// working directory, and returns an error if any of them does not match a discovered unit.
+func resolveUnitPaths(opts *options.TerragruntOptions, units []*component.Unit, kind string, paths []string) ([]string, error) {
+	resolved := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		unit, err := resolveUnit(opts, units, path, kind+" unit %s")
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = append(resolved, unit.Path())
+	}
+
+	return resolved, nil
+}
+
+// resolveUnitLogLevels resolves the paths of the given log level overrides against the working directory,
+// and returns an error if any of them does not match a discovered unit.
+func resolveUnitLogLevels(opts *options.TerragruntOptions, units []*component.Unit, levels map[string]log.Level) (map[string]log.Level, error) {
+	resolved := make(map[string]log.Level, len(levels))
+
+	for path, level := range levels {
+		unit, err := resolveUnit(opts, units, path, "unit %s with a log level override")
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[unit.Path()] = level
+	}
+
+	return resolved, nil
+}
+
+// resolveUnitCommands resolves the paths of the given command overrides against the working directory,
+// and returns an error if any of them does not match a discovered unit.
+func resolveUnitCommands(opts *options.TerragruntOptions, units []*component.Unit, commands map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(commands))
+
+	for path, cmd := range commands {
+		unit, err := resolveUnit(opts, units, path, "unit %s with a command override")
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[unit.Path()] = cmd
+	}
+
+	return resolved, nil
+}
+
+// resolveUnitMemoryEstimates resolves the paths of the given memory estimates against the working directory,
+// and returns an error if any of them does not match a discovered unit.
+func resolveUnitMemoryEstimates(opts *options.TerragruntOptions, units []*component.Unit, estimates map[string]int) (map[string]int, error) {
+	resolved := make(map[string]int, len(estimates))
+
+	for path, estimate := range estimates {
+		unit, err := resolveUnit(opts, units, path, "unit %s with a memory estimate")
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[unit.Path()] = estimate
+	}
+
+	return resolved, nil
+}
+
+// overrideUnitCommand makes the unit options run the given command with the arguments of the stack
+// command, dropping -auto-approve for commands that only plan.
+func overrideUnitCommand(unitOpts *options.TerragruntOptions, cmd string) {
+	unitOpts.TerraformCommand = cmd
+	unitOpts.TerraformCliArgs = overrideCommandArgs(unitOpts.TerraformCliArgs, cmd)
+}
+
+// overrideCommandArgs returns a copy of the arguments running the given command instead, without -auto-approve
+// unless the command is apply or destroy.
+func overrideCommandArgs(args *iacargs.IacArgs, cmd string) *iacargs.IacArgs {
+	args = args.Clone().SetCommand(cmd)
+
+	if cmd != tf.CommandNameApply && cmd != tf.CommandNameDestroy {
+		args.RemoveFlag("-auto-approve")
+	}
+
+	return args
+}
+
+// collectDependents collects the paths of all units that depend on the unit at the given path,
+// directly or indirectly.
+func collectDependents(units []*component.Unit, path string, paths map[string]bool) {
+	pending := []string{path}
+
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		for _, unit := range units {
+			if paths[unit.Path()] || !slices.ContainsFunc(unit.Dependencies(), func(dep component.Component) bool {
+				return dep.Path() == current
+			}) {
+				continue
+			}
+
+			paths[unit.Path()] = true
+			pending = append(pending, unit.Path())
+		}
+	}
+}
+
 // collectDependencies collects dependency paths for a unit with a bounded recursion depth.
 func collectDependencies(unit *component.Unit, paths map[string]bool) {
 	collectDependenciesBounded(unit, paths, 0)
@@ -1,8 +1,11 @@
 package runnerpool_test
 
 import (
+	"bytes"
 	"context"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,9 +13,15 @@ import (
 
 	"github.com/gruntwork-io/terragrunt/internal/component"
 	"github.com/gruntwork-io/terragrunt/internal/experiment"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
 	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/internal/telemetry"
 	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
 	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
 	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
 )
 
@@ -218,6 +227,680 @@ func TestNewRunnerPoolStack_FilterAllowDestroy(t *testing.T) {
 	require.True(t, foundVPC, "expected /tmp/test/vpc unit in stack")
 }
 
+func TestNewRunnerPoolStack_WithTarget(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		expectedIncluded  []string
+		includeDependents bool
+	}{
+		{
+			name:             "target and dependencies",
+			expectedIncluded: []string{"/tmp/test/vpc", "/tmp/test/db"},
+		},
+		{
+			name:              "target, dependencies and dependents",
+			includeDependents: true,
+			expectedIncluded:  []string{"/tmp/test/vpc", "/tmp/test/db", "/tmp/test/app"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// vpc <- db <- app, and an unrelated dns unit
+			vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+			db := component.NewUnit("/tmp/test/db").WithConfig(&config.TerragruntConfig{})
+			db.AddDependency(vpc)
+
+			app := component.NewUnit("/tmp/test/app").WithConfig(&config.TerragruntConfig{})
+			app.AddDependency(db)
+
+			dns := component.NewUnit("/tmp/test/dns").WithConfig(&config.TerragruntConfig{})
+
+			opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+			require.NoError(t, err)
+
+			opts.WorkingDir = "/tmp/test"
+
+			runner, err := runnerpool.NewRunnerPoolStack(
+				context.Background(),
+				thlogger.CreateLogger(),
+				opts,
+				component.Components{vpc, db, app, dns},
+				runnerpool.WithTarget("db", tc.includeDependents),
+			)
+			require.NoError(t, err)
+
+			var included []string
+
+			for _, u := range runner.GetStack().Units {
+				if !u.Excluded() {
+					included = append(included, u.Path())
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expectedIncluded, included)
+		})
+	}
+}
+
+func TestNewRunnerPoolStack_WithUnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc},
+		runnerpool.WithTarget("/tmp/test/missing", false),
+	)
+	require.Error(t, err)
+}
+
+func TestNewRunnerPoolStack_WithAssumeAppliedExcept(t *testing.T) {
+	t.Parallel()
+
+	// vpc <- db <- app, and db <- worker
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+	db := component.NewUnit("/tmp/test/db").WithConfig(&config.TerragruntConfig{})
+	db.AddDependency(vpc)
+
+	app := component.NewUnit("/tmp/test/app").WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(db)
+
+	worker := component.NewUnit("/tmp/test/worker").WithConfig(&config.TerragruntConfig{})
+	worker.AddDependency(db)
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc, db, app, worker},
+		runnerpool.WithAssumeAppliedExcept("app", "/tmp/test/worker"),
+	)
+	require.NoError(t, err)
+
+	var included []*component.Unit
+
+	for _, u := range runner.GetStack().Units {
+		if !u.Excluded() {
+			included = append(included, u)
+		}
+	}
+
+	require.ElementsMatch(t, []string{"/tmp/test/app", "/tmp/test/worker"}, unitPaths(included))
+
+	// The dependents of the units assumed to be applied are scheduled right away.
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	controller := runnerpool.NewController(
+		buildQueue(t, included),
+		included,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			ran = append(ran, u.Path())
+
+			return nil
+		}),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), thlogger.CreateLogger()))
+	assert.ElementsMatch(t, []string{"/tmp/test/app", "/tmp/test/worker"}, ran)
+}
+
+func TestNewRunnerPoolStack_WithAssumeAppliedExceptUnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc},
+		runnerpool.WithAssumeAppliedExcept("/tmp/test/missing"),
+	)
+	require.Error(t, err)
+}
+
+func TestNewRunnerPoolStack_WithStartWave(t *testing.T) {
+	t.Parallel()
+
+	// vpc <- db <- app, and vpc <- cache
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+	db := component.NewUnit("/tmp/test/db").WithConfig(&config.TerragruntConfig{})
+	db.AddDependency(vpc)
+
+	cache := component.NewUnit("/tmp/test/cache").WithConfig(&config.TerragruntConfig{})
+	cache.AddDependency(vpc)
+
+	app := component.NewUnit("/tmp/test/app").WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(db)
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+
+	// Checkpoint a run that failed in the second wave.
+	checkpoint, err := queue.NewQueue(component.Components{vpc, db, cache, app})
+	require.NoError(t, err)
+
+	checkpoint.SetEntryStatus(checkpoint.EntryByPath("/tmp/test/vpc"), queue.StatusSucceeded)
+	checkpoint.SetEntryStatus(checkpoint.EntryByPath("/tmp/test/cache"), queue.StatusSucceeded)
+	checkpoint.SetEntryStatus(checkpoint.EntryByPath("/tmp/test/db"), queue.StatusFailed)
+
+	data, err := checkpoint.MarshalGraph()
+	require.NoError(t, err)
+
+	startWave, err := runnerpool.StartWaveFromGraph(data)
+	require.NoError(t, err)
+	require.Equal(t, 1, startWave)
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc, db, cache, app},
+		runnerpool.WithStartWave(startWave),
+	)
+	require.NoError(t, err)
+
+	var included []*component.Unit
+
+	for _, u := range runner.GetStack().Units {
+		if !u.Excluded() {
+			included = append(included, u)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"/tmp/test/db", "/tmp/test/cache", "/tmp/test/app"}, unitPaths(included))
+}
+
+func TestNewRunnerPoolStack_WithStartWaveOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc},
+		runnerpool.WithStartWave(1),
+	)
+	require.Error(t, err)
+}
+
+func TestNewRunnerPoolStack_WithChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		expected          []string
+		includeDependents bool
+	}{
+		{name: "changed units only", expected: []string{"/tmp/test/vpc", "/tmp/test/db/replica"}},
+		{
+			name:              "changed units and their dependents",
+			includeDependents: true,
+			expected:          []string{"/tmp/test/vpc", "/tmp/test/db", "/tmp/test/app", "/tmp/test/db/replica"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// vpc <- db <- app, with replica nested in the db directory
+			vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+			db := component.NewUnit("/tmp/test/db").WithConfig(&config.TerragruntConfig{})
+			db.AddDependency(vpc)
+
+			replica := component.NewUnit("/tmp/test/db/replica").WithConfig(&config.TerragruntConfig{})
+
+			app := component.NewUnit("/tmp/test/app").WithConfig(&config.TerragruntConfig{})
+			app.AddDependency(db)
+
+			opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+			require.NoError(t, err)
+
+			opts.WorkingDir = "/tmp/test"
+
+			runner, err := runnerpool.NewRunnerPoolStack(
+				context.Background(),
+				thlogger.CreateLogger(),
+				opts,
+				component.Components{vpc, db, replica, app},
+				runnerpool.WithChangedFiles(
+					[]string{"vpc/main.tf", "/tmp/test/db/replica/terragrunt.hcl", "/tmp/test/README.md"},
+					runnerpool.UnchangedAssumeApplied,
+					tc.includeDependents,
+				),
+			)
+			require.NoError(t, err)
+
+			var included []*component.Unit
+
+			for _, u := range runner.GetStack().Units {
+				if !u.Excluded() {
+					included = append(included, u)
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expected, unitPaths(included))
+		})
+	}
+}
+
+func TestNewRunnerPoolStack_WithSkipPredicate(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+	legacy := component.NewUnit("/tmp/test/legacy").WithConfig(&config.TerragruntConfig{TerraformBinary: "terraform"})
+	unparsed := component.NewUnit("/tmp/test/unparsed")
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc, legacy, unparsed},
+		runnerpool.WithSkipPredicate(func(unit *component.Unit) (bool, string) {
+			if cfg := unit.Config(); cfg != nil && cfg.TerraformBinary == "terraform" {
+				return true, "pinned to terraform"
+			}
+
+			return false, ""
+		}),
+	)
+	require.NoError(t, err)
+
+	var included []*component.Unit
+
+	for _, u := range runner.GetStack().Units {
+		if !u.Excluded() {
+			included = append(included, u)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"/tmp/test/vpc", "/tmp/test/unparsed"}, unitPaths(included))
+}
+
+func TestNewRunnerPoolStack_WithExcludeGlobs(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+	experimental := component.NewUnit("/tmp/test/apps/experimental/beta").WithConfig(&config.TerragruntConfig{})
+	allowed := component.NewUnit("/tmp/test/apps/experimental/stable").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc, experimental, allowed},
+		runnerpool.WithExcludeGlobs("**/experimental/**", "!apps/experimental/stable"),
+	)
+	require.NoError(t, err)
+
+	var included []*component.Unit
+
+	for _, u := range runner.GetStack().Units {
+		if !u.Excluded() {
+			included = append(included, u)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"/tmp/test/vpc", "/tmp/test/apps/experimental/stable"}, unitPaths(included))
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})},
+		runnerpool.WithExcludeGlobs("[vpc"),
+	)
+	require.Error(t, err)
+}
+
+func TestNewRunnerPoolStack_ExcludeGlobsOption(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+	experimental := component.NewUnit("/tmp/test/apps/experimental/beta").WithConfig(&config.TerragruntConfig{})
+	allowed := component.NewUnit("/tmp/test/apps/experimental/stable").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+	opts.ExcludeGlobs = []string{"!apps/experimental/stable"}
+
+	// The globs of the options decide over the globs of WithExcludeGlobs.
+	runner, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc, experimental, allowed},
+		runnerpool.WithExcludeGlobs("**/experimental/**"),
+	)
+	require.NoError(t, err)
+
+	var included []*component.Unit
+
+	for _, u := range runner.GetStack().Units {
+		if !u.Excluded() {
+			included = append(included, u)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"/tmp/test/vpc", "/tmp/test/apps/experimental/stable"}, unitPaths(included))
+}
+
+func TestRunner_WithUnitCommands(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	var units component.Components
+
+	for _, name := range []string{"dep", "target"} {
+		unitDir := filepath.Join(rootDir, name)
+		require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+
+		units = append(units, component.NewUnit(unitDir).WithConfig(&config.TerragruntConfig{}))
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.RunAllAutoApprove = true
+
+	// A run adds its flags to the arguments of the options, so the second run starts from a copy.
+	flagOpts := opts.Clone()
+	flagOpts.UnitCommands = map[string]string{"dep": "plan", "target": "apply"}
+
+	var (
+		mu   sync.Mutex
+		args = map[string][]string{}
+	)
+
+	// The transform sees the options each unit would run with, and stops the unit before it runs.
+	transform := func(u *component.Unit, unitOpts *options.TerragruntOptions) (*options.TerragruntOptions, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		args[filepath.Base(u.Path())] = append([]string{unitOpts.TerraformCommand}, unitOpts.TerraformCliArgs.Slice()...)
+
+		return nil, assert.AnError
+	}
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		opts,
+		units,
+		runnerpool.WithUnitCommands(map[string]string{"target": "plan"}),
+		runnerpool.WithOptionsTransform(transform),
+	)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, nil), assert.AnError)
+
+	assert.Equal(t, map[string][]string{
+		"dep":    {"apply", "apply", "-auto-approve", "-input=false"},
+		"target": {"plan", "plan", "-input=false"},
+	}, args)
+
+	// The overrides of the options, as set by --unit-command, decide over those of WithUnitCommands.
+	runner, err = runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		flagOpts,
+		units,
+		runnerpool.WithUnitCommands(map[string]string{"target": "plan"}),
+		runnerpool.WithOptionsTransform(transform),
+	)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, runner.Run(t.Context(), thlogger.CreateLogger(), flagOpts, nil), assert.AnError)
+
+	assert.Equal(t, map[string][]string{
+		"dep":    {"plan", "plan", "-input=false"},
+		"target": {"apply", "apply", "-auto-approve", "-input=false"},
+	}, args)
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		opts,
+		units,
+		runnerpool.WithUnitCommands(map[string]string{"missing": "plan"}),
+	)
+	require.Error(t, err)
+}
+
+func TestRunner_UnitSpanAttributes(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	files := map[string]string{
+		"vpc/terragrunt.hcl": "",
+		"vpc/main.tf":        "",
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+esac
+exit 0
+`,
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+
+	l := thlogger.CreateLogger()
+
+	var spans bytes.Buffer
+
+	tlm, err := telemetry.NewTelemeter(t.Context(), l, "terragrunt", "test", &spans, &telemetry.Options{TraceExporter: "console"})
+	require.NoError(t, err)
+
+	ctx := telemetry.ContextWithTelemeter(t.Context(), tlm)
+
+	runner, err := runnerpool.NewRunnerPoolStack(ctx, l, opts, component.Components{vpc})
+	require.NoError(t, err)
+
+	require.NoError(t, runner.Run(ctx, l, opts, nil))
+	require.NoError(t, tlm.Shutdown(ctx))
+
+	// The spans of the commands run for the unit carry its path.
+	assert.Contains(t, spans.String(), `"Name":"run_`+opts.TFPath+`"`)
+	assert.Contains(t, spans.String(), `{"Key":"unit_path","Value":{"Type":"STRING","Value":"`+vpc.Path()+`"}}`)
+}
+
+func TestRunner_WithUnitMatrix(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	units := map[string]*component.Unit{}
+
+	for _, name := range []string{"dep", "app", "consumer"} {
+		unitDir := filepath.Join(rootDir, name)
+		require.NoError(t, os.MkdirAll(unitDir, os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(unitDir, "terragrunt.hcl"), nil, 0o644))
+
+		units[name] = component.NewUnit(unitDir).WithConfig(&config.TerragruntConfig{})
+	}
+
+	// dep <- app <- consumer
+	units["app"].AddDependency(units["dep"])
+	units["consumer"].AddDependency(units["app"])
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+
+	var (
+		mu   sync.Mutex
+		args = map[string][]string{}
+	)
+
+	// The transform sees the options each run of the matrix would use, and stops it before it runs.
+	transform := func(u *component.Unit, unitOpts *options.TerragruntOptions) (*options.TerragruntOptions, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		args[filepath.Base(u.Path())] = unitOpts.TerraformCliArgs.Slice()
+		assert.Equal(t, filepath.Join(rootDir, "app", "terragrunt.hcl"), unitOpts.TerragruntConfigPath)
+
+		return nil, assert.AnError
+	}
+
+	runner, err := runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{units["dep"], units["app"], units["consumer"]},
+		runnerpool.WithUnitMatrix(map[string]map[string][]string{
+			"app": {
+				"eu": {"-var=region=eu-west-1"},
+				"us": {"-var=region=us-east-1"},
+			},
+		}),
+		runnerpool.WithOptionsTransform(transform),
+		runnerpool.WithAlreadyApplied(func(ctx context.Context, u *component.Unit) (bool, error) {
+			return filepath.Base(u.Path()) == "dep", nil
+		}),
+	)
+	require.NoError(t, err)
+
+	// Both runs of the matrix share the dependency of the unit, and its dependent waits for both of them.
+	appEU := filepath.Join(rootDir, "app[eu]")
+	appUS := filepath.Join(rootDir, "app[us]")
+
+	paths := func(components component.Components) []string {
+		result := make([]string, 0, len(components))
+		for _, c := range components {
+			result = append(result, c.Path())
+		}
+
+		return result
+	}
+
+	assert.ElementsMatch(t, []string{appEU, appUS}, paths(units["dep"].Dependents()))
+	assert.ElementsMatch(t, []string{appEU, appUS}, paths(units["consumer"].Dependencies()))
+	assert.Nil(t, runner.GetStack().FindUnitByPath(units["app"].Path()))
+
+	r := report.NewReport()
+	require.ErrorIs(t, runner.Run(t.Context(), thlogger.CreateLogger(), opts, r), assert.AnError)
+
+	assert.Equal(t, map[string][]string{
+		"app[eu]": {"plan", "-input=false", "-var=region=eu-west-1"},
+		"app[us]": {"plan", "-input=false", "-var=region=us-east-1"},
+	}, args)
+
+	// Each run of the matrix is reported on its own.
+	for _, path := range []string{appEU, appUS} {
+		run, err := r.GetRun(path)
+		require.NoError(t, err)
+		assert.Equal(t, report.ResultFailed, run.Result)
+	}
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{units["dep"]},
+		runnerpool.WithUnitMatrix(map[string]map[string][]string{"missing": {"eu": nil}}),
+	)
+	require.Error(t, err)
+}
+
+func TestNewRunnerPoolStack_WithUnitLogLevels(t *testing.T) {
+	t.Parallel()
+
+	vpc := component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.WorkingDir = "/tmp/test"
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc},
+		runnerpool.WithUnitLogLevels(map[string]log.Level{"vpc": log.DebugLevel}),
+	)
+	require.NoError(t, err)
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{vpc},
+		runnerpool.WithUnitLogLevels(map[string]log.Level{"missing": log.DebugLevel}),
+	)
+	require.Error(t, err)
+}
+
 // buildTestRunner creates a Runner with simple unit components for testing.
 func buildTestRunner(t *testing.T, workDir string, unitPaths []string) *runnerpool.Runner {
 	t.Helper()
@@ -43,3 +43,86 @@ func TestDiscoveryResolverMatchesLegacyPaths(t *testing.T) {
 	require.Len(t, units, 1)
 	require.Equal(t, tmpDir, units[0].Path())
 }
+
+func TestRunnerPoolRun_FailOnEmptyRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		failOnEmptyRun bool
+	}{
+		{name: "empty run succeeds by default"},
+		{name: "empty run fails when requested", failOnEmptyRun: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+			require.NoError(t, err)
+
+			opts.WorkingDir = "/tmp/test"
+			opts.TerraformCommand = "plan"
+			opts.FailOnEmptyRun = tc.failOnEmptyRun
+
+			l := thlogger.CreateLogger()
+
+			runner, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{})
+			require.NoError(t, err)
+
+			err = runner.Run(context.Background(), l, opts, nil)
+			if !tc.failOnEmptyRun {
+				require.NoError(t, err)
+				return
+			}
+
+			var emptyErr runnerpool.EmptyRunError
+			require.ErrorAs(t, err, &emptyErr)
+			require.Equal(t, "/tmp/test", emptyErr.WorkingDir)
+		})
+	}
+}
+
+func TestRunnerPoolValidateConfigs(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := helpers.TmpDirWOSymlinks(t)
+
+	validDir := filepath.Join(tmpDir, "valid")
+	invalidDir := filepath.Join(tmpDir, "invalid")
+
+	for dir, cfg := range map[string]string{
+		validDir:   "inputs = {\n  name = \"valid\"\n}\n",
+		invalidDir: "inputs = {\n",
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(""), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.DefaultTerragruntConfigPath), []byte(cfg), 0o600))
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(tmpDir, config.DefaultTerragruntConfigPath))
+	require.NoError(t, err)
+
+	opts.WorkingDir = tmpDir
+	opts.RootWorkingDir = tmpDir
+	opts.TerraformCommand = "plan"
+
+	l := thlogger.CreateLogger()
+
+	discovered := component.Components{
+		component.NewUnit(validDir).WithConfig(&config.TerragruntConfig{}),
+		component.NewUnit(invalidDir).WithConfig(&config.TerragruntConfig{}),
+	}
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, discovered)
+	require.NoError(t, err)
+
+	rnr, ok := stack.(*runnerpool.Runner)
+	require.True(t, ok)
+
+	err = rnr.ValidateConfigs(context.Background(), l, opts)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid")
+	require.NotContains(t, err.Error(), "[valid]")
+}
@@ -0,0 +1,108 @@
+package runnerpool
+
+import (
+	"context"
+	"sync"
+)
+
+// slotSemaphore is a counting semaphore bounding how many units run at once, whose size can change while
+// units hold slots. Growing it lets waiting units acquire the new slots right away, while shrinking it makes
+// new acquisitions wait until enough slots are released to get under the new size.
+type slotSemaphore struct {
+	// changed is closed and replaced whenever a slot is released or the size changes, to wake up waiters.
+	changed chan struct{}
+	limit   int
+	inUse   int
+	mu      sync.Mutex
+}
+
+// newSlotSemaphore returns a semaphore with the given number of slots, at least one.
+func newSlotSemaphore(size int) *slotSemaphore {
+	return &slotSemaphore{
+		changed: make(chan struct{}),
+		limit:   max(size, 1),
+	}
+}
+
+// acquire takes a slot, waiting until one is free, giving up with the cause of the cancellation when the
+// context is cancelled first, so a cancelled run never blocks on a slot that is not released.
+func (s *slotSemaphore) acquire(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		s.mu.Lock()
+
+		if s.inUse < s.limit {
+			s.inUse++
+			s.mu.Unlock()
+
+			return nil
+		}
+
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// release gives a slot back.
+func (s *slotSemaphore) release() {
+	s.releaseWeight(1)
+}
+
+// tryAcquireWeight takes n slots if they are free, without waiting, and returns the number of slots taken, or
+// zero if they are not free. A weight larger than the size of the semaphore takes every slot, so it waits for
+// every slot to be free.
+func (s *slotSemaphore) tryAcquireWeight(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n = min(max(n, 1), s.limit)
+
+	if s.inUse+n > s.limit {
+		return 0
+	}
+
+	s.inUse += n
+
+	return n
+}
+
+// releaseWeight gives n slots back.
+func (s *slotSemaphore) releaseWeight(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inUse -= n
+	s.notify()
+}
+
+// resize sets the number of slots, at least one. Slots in use beyond the new size are kept until released.
+func (s *slotSemaphore) resize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = max(size, 1)
+	s.notify()
+}
+
+// size returns the number of slots.
+func (s *slotSemaphore) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.limit
+}
+
+// notify wakes up the waiters. It must be called with the lock held.
+func (s *slotSemaphore) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,290 @@
+package runnerpool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/codegen"
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/configbridge"
+	tgerrors "github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/experiment"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/runner/run"
+	"github.com/gruntwork-io/terragrunt/internal/runner/run/creds"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runcfg"
+	"github.com/gruntwork-io/terragrunt/internal/tf"
+	"github.com/gruntwork-io/terragrunt/internal/util"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+// WriteShellScript writes a standalone bash script to w that runs the command of the given options in every unit
+// of the run, one at a time, dependencies first, or dependents first for a destroy. The script stops at the first
+// failing unit, so that it can be audited and executed without Terragrunt, e.g. in an air-gapped environment.
+//
+// The configuration of every unit is parsed to reproduce what a run does: each unit runs in a subshell in its
+// working directory in the download dir, e.g. .terragrunt-cache, after writing the files of its generate blocks
+// and remote state, with its inputs exported as TF_VAR_* variables, the env vars and arguments of its
+// extra_arguments, and the backend config of its remote state passed to init. Like a run, init runs first for
+// commands that need it, unless auto-init is disabled.
+//
+// The script does not download the sources of the units, so their working directories must already be
+// populated, e.g. by a previous run. Units excluded from the run are left out, while per-unit command
+// overrides and matrix runs are honored.
+func (rnr *Runner) WriteShellScript(ctx context.Context, l log.Logger, w io.Writer, opts *options.TerragruntOptions) error {
+	stackOpts := opts.Clone()
+	needsCliSync := prepareStackCliArgs(stackOpts)
+
+	var sb strings.Builder
+
+	sb.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&sb, "# Runs '%s' in each unit of the run queue, in dependency order.\n", stackOpts.TerraformCommand)
+	sb.WriteString("set -e\n")
+
+	for i, wave := range rnr.queue.Waves() {
+		for _, entry := range wave {
+			u, ok := entry.Component.(*component.Unit)
+			if !ok || u.Excluded() {
+				continue
+			}
+
+			script, err := rnr.unitShellScript(ctx, l, stackOpts, u, needsCliSync)
+			if err != nil {
+				return tgerrors.Errorf("failed to write the script of unit %s: %w", u.Path(), err)
+			}
+
+			fmt.Fprintf(&sb, "\n# Unit %s (wave %d)\n", u.DisplayPath(), i)
+			sb.WriteString(script)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// unitShellScript returns the part of the script running the given unit, or a comment when the exclude block of
+// the unit prevents it from running the command.
+func (rnr *Runner) unitShellScript(
+	ctx context.Context,
+	l log.Logger,
+	stackOpts *options.TerragruntOptions,
+	u *component.Unit,
+	needsCliSync bool,
+) (string, error) {
+	// A run expanded from the matrix of a unit runs the configuration of that unit.
+	unit := u
+	if variant, ok := rnr.variants[u.Path()]; ok {
+		unit = variant.unit
+	}
+
+	unitOpts, unitLogger, err := BuildUnitOpts(l, stackOpts, unit)
+	if err != nil {
+		return "", err
+	}
+
+	rnr.setUnitCliArgs(l, stackOpts, unitOpts, u, needsCliSync)
+
+	if _, err := creds.ObtainCredsForParsing(ctx, unitLogger, unitOpts.AuthProviderCmd, unitOpts.Env, configbridge.ShellRunOptsFromOpts(unitOpts)); err != nil {
+		return "", err
+	}
+
+	parseCtx, pctx := configbridge.NewParsingContext(ctx, unitLogger, unitOpts)
+
+	cfg, err := config.ReadTerragruntConfig(parseCtx, unitLogger, pctx, pctx.ParserOptions)
+	if err != nil {
+		return "", err
+	}
+
+	runCfg := cfg.ToRunConfig(unitLogger)
+	runOpts := configbridge.NewRunOptions(unitOpts)
+
+	if runCfg.Exclude.ShouldPreventRun(runOpts.TerraformCommand) {
+		return "# Not run due to its exclude block with no_run = true\n", nil
+	}
+
+	workingDir, err := unitWorkingDir(unitLogger, runOpts, runCfg)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("(\n")
+	fmt.Fprintf(&sb, "cd %s\n", shellQuote(workingDir))
+
+	if err := writeGeneratedFiles(&sb, unitLogger, runCfg); err != nil {
+		return "", err
+	}
+
+	// Like a run, the env vars of the extra_arguments of the command also apply to its init.
+	command := runOpts.TerraformCliArgs.First()
+	extraEnv := extraArgsEnvVars(runCfg, command)
+
+	for _, name := range slices.Sorted(maps.Keys(extraEnv)) {
+		fmt.Fprintf(&sb, "export %s=%s\n", name, shellQuote(extraEnv[name]))
+	}
+
+	env, err := run.ToTerraformEnvVars(unitLogger, runCfg.Inputs)
+	if err != nil {
+		return "", err
+	}
+
+	// The inputs do not override the env vars already set.
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		fmt.Fprintf(&sb, "[ -n \"${%s+set}\" ] || export %s=%s\n", name, name, shellQuote(env[name]))
+	}
+
+	nullVars, err := nullInputsFile(runCfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Like a run, the file of the null inputs is removed once the command ran.
+	if nullVars != nil {
+		writeShellFile(&sb, run.NullTFVarsFile, nullVars)
+	}
+
+	if command != tf.CommandNameInit && runOpts.AutoInit && !slices.Contains(run.TerraformCommandsThatDoNotNeedInit, command) {
+		initArgs := iacargs.New().SetCommand(tf.CommandNameInit)
+		if l.Formatter().DisabledColors() || runOpts.TerraformCliArgs.Contains(tf.FlagNameNoColor) {
+			initArgs.AppendFlag(tf.FlagNameNoColor)
+		}
+
+		writeShellCommand(&sb, unitLogger, runOpts, runCfg, initArgs, extraArgsEnvVars(runCfg, tf.CommandNameInit))
+	}
+
+	writeShellCommand(&sb, unitLogger, runOpts, runCfg, runOpts.TerraformCliArgs, nil)
+
+	if nullVars != nil {
+		fmt.Fprintf(&sb, "rm -f %s\n", shellQuote(run.NullTFVarsFile))
+	}
+
+	sb.WriteString(")\n")
+
+	return sb.String(), nil
+}
+
+// unitWorkingDir returns the directory in the download dir the OpenTofu/Terraform code of the unit is run in.
+func unitWorkingDir(l log.Logger, opts *run.Options, cfg *runcfg.RunConfig) (string, error) {
+	downloadDir := opts.DownloadDir
+
+	// Like a run, the download dir of the configuration is only used when the default one was not changed.
+	if _, defaultDownloadDir := util.DefaultWorkingAndDownloadDirs(opts.TerragruntConfigPath); downloadDir == defaultDownloadDir && cfg.DownloadDir != "" {
+		downloadDir = cfg.DownloadDir
+	}
+
+	sourceURL, err := runcfg.GetTerraformSourceURL(opts.Source, opts.SourceMap, opts.OriginalTerragruntConfigPath, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := tf.NewSource(l, sourceURL, downloadDir, opts.WorkingDir, opts.Experiments.Evaluate(experiment.Symlinks))
+	if err != nil {
+		return "", err
+	}
+
+	return source.WorkingDir, nil
+}
+
+// writeGeneratedFiles writes the commands writing the files of the generate blocks and remote state of the unit.
+func writeGeneratedFiles(sb *strings.Builder, l log.Logger, cfg *runcfg.RunConfig) error {
+	for _, genCfg := range cfg.GenerateConfigs {
+		if genCfg.Disable {
+			continue
+		}
+
+		writeShellFile(sb, genCfg.Path, codegen.FileContents(&genCfg))
+	}
+
+	if cfg.RemoteState.Config != nil && cfg.RemoteState.Generate != nil {
+		genCfg, err := cfg.RemoteState.GenerateConfig(l)
+		if err != nil {
+			return err
+		}
+
+		writeShellFile(sb, genCfg.Path, codegen.FileContents(genCfg))
+	}
+
+	return nil
+}
+
+// nullInputsFile returns the contents of the tfvars file holding the null inputs of the unit, which cannot be
+// passed as env vars, or nil if there are none.
+func nullInputsFile(cfg *runcfg.RunConfig) ([]byte, error) {
+	nullInputs := make(map[string]any)
+
+	for name, value := range cfg.Inputs {
+		if value == nil {
+			nullInputs[name] = nil
+		}
+	}
+
+	if len(nullInputs) == 0 {
+		return nil, nil
+	}
+
+	contents, err := json.MarshalIndent(nullInputs, "", "  ")
+	if err != nil {
+		return nil, tgerrors.New(err)
+	}
+
+	return contents, nil
+}
+
+// extraArgsEnvVars returns the env vars the extra_arguments of the unit set for the given command.
+func extraArgsEnvVars(cfg *runcfg.RunConfig, command string) map[string]string {
+	env := map[string]string{}
+
+	for i := range cfg.Terraform.ExtraArgs {
+		if slices.Contains(cfg.Terraform.ExtraArgs[i].Commands, command) {
+			maps.Copy(env, cfg.Terraform.ExtraArgs[i].EnvVars)
+		}
+	}
+
+	return env
+}
+
+// writeShellFile writes the command writing the given contents to the file at the given path.
+func writeShellFile(sb *strings.Builder, path string, contents []byte) {
+	fmt.Fprintf(sb, "printf '%%s' %s > %s\n", shellQuote(string(contents)), shellQuote(path))
+}
+
+// writeShellCommand writes the OpenTofu/Terraform command running the given args, preceded by the given env vars.
+// As in a run, the extra_arguments and, for init, the backend config of the remote state are inserted after the
+// command.
+func writeShellCommand(sb *strings.Builder, l log.Logger, opts *run.Options, cfg *runcfg.RunConfig, args *iacargs.IacArgs, env map[string]string) {
+	cmdOpts := opts.Clone()
+	cmdOpts.TerraformCliArgs = args.Clone()
+
+	cmdOpts.InsertTerraformCliArgs(run.FilterTerraformExtraArgs(l, cmdOpts, cfg)...)
+
+	if cmdOpts.TerraformCliArgs.First() == tf.CommandNameInit && cfg.RemoteState.Config != nil {
+		cmdOpts.InsertTerraformCliArgs(cfg.RemoteState.GetTFInitArgs()...)
+	}
+
+	words := make([]string, 0, len(env)+1)
+
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		words = append(words, name+"="+shellQuote(env[name]))
+	}
+
+	words = append(words, shellQuote(opts.TFPath))
+	for _, arg := range cmdOpts.TerraformCliArgs.Slice() {
+		words = append(words, shellQuote(arg))
+	}
+
+	sb.WriteString(strings.Join(words, " ") + "\n")
+}
+
+// shellQuote quotes s as a single word for bash.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
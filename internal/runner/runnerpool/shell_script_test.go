@@ -0,0 +1,148 @@
+package runnerpool_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestWriteShellScript(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	logFile := filepath.Join(rootDir, "tofu.log")
+
+	files := map[string]string{
+		"modules/vpc/main.tf": `terraform {
+  backend "local" {}
+}
+`,
+		"modules/app/main.tf": "",
+		"vpc/terragrunt.hcl": `terraform {
+  source = "../modules/vpc"
+
+  extra_arguments "lock" {
+    commands  = ["apply"]
+    arguments = ["-lock=false"]
+    env_vars  = { TF_LOG = "warn" }
+  }
+}
+
+remote_state {
+  backend = "local"
+  config  = { path = "vpc.tfstate" }
+}
+
+inputs = {
+  name = "vpc"
+  tags = { team = "net" }
+}
+`,
+		"app's/terragrunt.hcl": `terraform {
+  source = "../modules/app"
+}
+
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite"
+  contents  = "# provider config"
+}
+
+inputs = {
+  name = "it's"
+  zone = null
+}
+`,
+		// The fake binary logs every invocation with its working dir, its env and the files it sees.
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0"; exit 0 ;;
+esac
+{
+  echo "$(pwd) $*"
+  env | grep -E '^(TF_VAR_|TF_LOG=)' | sort
+  for f in *.tf *.json .*.json; do [ -f "$f" ] && { echo "== $f"; cat "$f"; }; done
+} >> ` + logFile + `
+exit 0
+`,
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+	app := component.NewUnit(filepath.Join(rootDir, "app's")).WithConfig(&config.TerragruntConfig{})
+	app.AddDependency(vpc)
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+
+	l := thlogger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(
+		context.Background(),
+		l,
+		opts,
+		component.Components{app, vpc},
+		runnerpool.WithUnitCommands(map[string]string{filepath.Join(rootDir, "app's"): "plan"}),
+	)
+	require.NoError(t, err)
+
+	runner := stack.(*runnerpool.Runner)
+
+	// A real run populates the working dirs and logs the invocations the script has to reproduce.
+	require.NoError(t, runner.Run(t.Context(), l, opts.Clone(), nil))
+
+	runLog, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(logFile))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, runner.WriteShellScript(t.Context(), l, &buf, opts))
+
+	script := buf.String()
+
+	assert.True(t, strings.HasPrefix(script, "#!/usr/bin/env bash\n"))
+	assert.Contains(t, script, "\nset -e\n")
+	assert.Contains(t, script, ".terragrunt-cache")
+	assert.Less(t, strings.Index(script, "vpc/.terragrunt-cache"), strings.Index(script, "app'\\''s/.terragrunt-cache"))
+
+	scriptFile := filepath.Join(rootDir, "run.sh")
+	require.NoError(t, os.WriteFile(scriptFile, buf.Bytes(), 0o755))
+
+	out, err := exec.CommandContext(t.Context(), "bash", scriptFile).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	scriptLog, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(runLog), string(scriptLog))
+	assert.Contains(t, string(runLog), " init -backend-config=path=vpc.tfstate")
+	assert.Contains(t, string(runLog), "TF_VAR_name=it's")
+	assert.Contains(t, string(runLog), "== provider.tf")
+	assert.Contains(t, string(runLog), "== .terragrunt-null-vars.auto.tfvars.json")
+}
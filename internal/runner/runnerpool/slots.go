@@ -0,0 +1,74 @@
+package runnerpool
+
+import (
+	"maps"
+
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/puzpuzpuz/xsync/v3"
+)
+
+// SlotFunc is a hook invoked with the path of a unit and the number of concurrency slots in use,
+// including the unit's own slot when it is acquired and excluding it once it is released.
+type SlotFunc func(path string, inUse int)
+
+// WithSlotHooks sets hooks that are invoked when a unit acquires and releases a concurrency slot,
+// e.g. to export gauges of running units. Either hook may be nil.
+//
+// The hooks are invoked synchronously from the scheduling loop and the unit goroutines, so they must be
+// cheap and safe for concurrent use.
+func WithSlotHooks(onAcquire, onRelease SlotFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.onSlotAcquire = onAcquire
+		dr.onSlotRelease = onRelease
+	}
+}
+
+// slotAcquired notifies the acquire hook that the unit at the given path took a concurrency slot.
+func (dr *Controller) slotAcquired(path string) {
+	inUse := dr.slotsInUse.Add(1)
+
+	if dr.onSlotAcquire != nil {
+		dr.onSlotAcquire(path, int(inUse))
+	}
+}
+
+// slotReleased notifies the release hook that the unit at the given path gave its concurrency slot back.
+func (dr *Controller) slotReleased(path string) {
+	inUse := dr.slotsInUse.Add(-1)
+
+	if dr.onSlotRelease != nil {
+		dr.onSlotRelease(path, int(inUse))
+	}
+}
+
+// cancelEntry fails the entry of a unit that was not run because the run was cancelled while it waited for
+// a concurrency slot.
+func (dr *Controller) cancelEntry(l log.Logger, ent *queue.Entry, results *xsync.MapOf[string, error], cause error) {
+	path := ent.Component.Path()
+	err := errors.New(UnitCancelledError{UnitPath: path, Err: cause})
+
+	l.Debugf("Runner Pool Controller: %s not run: %v", path, err)
+
+	dr.q.FailEntry(ent)
+	dr.storeResult(results, path, err)
+
+	dr.mu.Lock()
+	if dr.cancelled == nil {
+		dr.cancelled = make(map[string]error)
+	}
+
+	dr.cancelled[path] = err
+	dr.mu.Unlock()
+}
+
+// Cancelled returns the errors of the units that were not run because the run was cancelled while they
+// waited for a concurrency slot, or because the deadline of the run passed before they started, keyed by
+// unit path.
+func (dr *Controller) Cancelled() map[string]error {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return maps.Clone(dr.cancelled)
+}
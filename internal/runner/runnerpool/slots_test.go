@@ -0,0 +1,146 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_SlotHooks(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D"}, nil)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	var (
+		mu       sync.Mutex
+		acquired []string
+		released []string
+		maxInUse int
+	)
+
+	onAcquire := func(path string, inUse int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		acquired = append(acquired, path)
+		maxInUse = max(maxInUse, inUse)
+	}
+
+	onRelease := func(path string, _ int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		released = append(released, path)
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+		runnerpool.WithSlotHooks(onAcquire, onRelease),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.ElementsMatch(t, []string{"A", "B", "C", "D"}, acquired)
+	assert.ElementsMatch(t, []string{"A", "B", "C", "D"}, released)
+	assert.LessOrEqual(t, maxInUse, 2)
+	assert.Positive(t, maxInUse)
+}
+
+func TestController_SlotHooksOptional(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, nil)
+
+	var acquired []string
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+		runnerpool.WithSlotHooks(func(path string, inUse int) { acquired = append(acquired, path) }, nil),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.Equal(t, []string{"A"}, acquired)
+}
+
+func TestController_CancelledWhileWaitingForSlot(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C"}, nil)
+
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+
+	aStarted := make(chan struct{})
+	release := make(chan struct{})
+
+	// A holds the only slot and ignores the cancellation until it is released.
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		ran = append(ran, u.Path())
+		mu.Unlock()
+
+		if u.Path() == "A" {
+			close(aStarted)
+			<-release
+		}
+
+		return nil
+	}
+
+	q := buildQueue(t, units)
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- controller.Run(ctx, logger.CreateLogger())
+	}()
+
+	<-aStarted
+	cancel()
+
+	require.Eventually(t, func() bool {
+		return len(controller.Cancelled()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	close(release)
+	require.NoError(t, <-errCh)
+
+	cancelled := controller.Cancelled()
+	for _, path := range []string{"B", "C"} {
+		var cancelErr runnerpool.UnitCancelledError
+		require.ErrorAs(t, cancelled[path], &cancelErr)
+		require.ErrorIs(t, cancelled[path], context.Canceled)
+		assert.Equal(t, path, cancelErr.UnitPath)
+		assert.Equal(t, queue.StatusFailed, q.EntryByPath(path).Status)
+	}
+
+	assert.Equal(t, []string{"A"}, ran)
+}
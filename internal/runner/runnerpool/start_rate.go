@@ -0,0 +1,46 @@
+package runnerpool
+
+import (
+	"context"
+	"time"
+)
+
+// WithMaxStartsPerSecond caps how many units start per second, e.g. to protect rate limited APIs when a
+// wide wave of units becomes ready at once. Unlike the concurrency limit, which bounds how many units run at
+// the same time, this spaces out the starts evenly, one every 1/n seconds.
+//
+// The limit applies before a unit takes a concurrency slot, and a unit whose run is cancelled while waiting
+// to start is not run. A zero or negative limit does not limit the start rate, which is the default.
+func WithMaxStartsPerSecond(n int) ControllerOption {
+	return func(dr *Controller) {
+		dr.maxStartsPerSecond = n
+	}
+}
+
+// waitForStart blocks until the start rate allows another unit to start, giving up with the cause of the
+// cancellation when the context is cancelled first.
+func (dr *Controller) waitForStart(ctx context.Context) error {
+	if dr.maxStartsPerSecond <= 0 {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+
+	now := dr.clock.Now()
+
+	if wait := dr.nextStart.Sub(now); wait > 0 {
+		select {
+		case <-dr.clock.After(wait):
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	} else {
+		dr.nextStart = now
+	}
+
+	dr.nextStart = dr.nextStart.Add(time.Second / time.Duration(dr.maxStartsPerSecond))
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package runnerpool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_MaxStartsPerSecond(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B", "C", "D"}, map[string][]string{})
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		return nil
+	}
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxStartsPerSecond(2),
+		runnerpool.WithClock(clock),
+	).Run(t.Context(), logger.CreateLogger())
+	require.NoError(t, err)
+
+	// The first unit starts right away, and every other unit half a second after the previous one.
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}, clock.waits)
+}
+
+// stoppedClock never lets a wait elapse.
+type stoppedClock struct {
+	fakeClock
+}
+
+func (c *stoppedClock) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func TestController_MaxStartsPerSecondCancelled(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, map[string][]string{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var ran []string
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		ran = append(ran, u.Path())
+		cancel()
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxStartsPerSecond(1),
+		runnerpool.WithClock(&stoppedClock{fakeClock{now: time.Unix(0, 0)}}),
+	)
+
+	// The unit waiting to start gives up once the run is cancelled.
+	err := controller.Run(ctx, logger.CreateLogger())
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, ran, 1)
+
+	cancelled := controller.Cancelled()
+	require.Len(t, cancelled, 1)
+
+	for path, err := range cancelled {
+		var cancelErr runnerpool.UnitCancelledError
+		require.ErrorAs(t, err, &cancelErr)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, path, cancelErr.UnitPath)
+		assert.NotEqual(t, ran[0], path)
+	}
+}
@@ -0,0 +1,77 @@
+package runnerpool_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestRunner_StrictReportingOfEarlyExit(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	for _, strict := range []bool{false, true} {
+		// The report only accepts absolute paths, so the results of these units cannot be recorded: vpc fails as
+		// it has no configuration, and app exits early.
+		vpc := component.NewUnit("vpc").WithConfig(&config.TerragruntConfig{})
+		app := component.NewUnit("app").WithConfig(&config.TerragruntConfig{})
+		app.AddDependency(vpc)
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.WorkingDir = rootDir
+		opts.TerraformCommand = "apply"
+		opts.TerraformCliArgs = iacargs.New("apply")
+		opts.StrictReporting = strict
+
+		l := thlogger.CreateLogger()
+
+		stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, component.Components{vpc, app})
+		require.NoError(t, err)
+
+		err = stack.Run(t.Context(), l, opts, report.NewReport())
+		require.Error(t, err)
+
+		if !strict {
+			assert.Empty(t, reportingErrorPaths(err))
+			continue
+		}
+
+		assert.ElementsMatch(t, []string{"vpc", "app"}, reportingErrorPaths(err))
+	}
+}
+
+// reportingErrorPaths returns the paths of the units of the reporting errors found in the tree of err.
+func reportingErrorPaths(err error) []string {
+	if reportingErr, ok := err.(common.ReportingError); ok {
+		return []string{reportingErr.UnitPath}
+	}
+
+	var paths []string
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range wrapped.Unwrap() {
+			paths = append(paths, reportingErrorPaths(err)...)
+		}
+	case interface{ Unwrap() error }:
+		paths = append(paths, reportingErrorPaths(wrapped.Unwrap())...)
+	}
+
+	return paths
+}
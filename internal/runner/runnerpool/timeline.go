@@ -0,0 +1,263 @@
+package runnerpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// TimelineEventKind is the kind of a recorded dispatch event.
+type TimelineEventKind string
+
+const (
+	TimelineEventStart  TimelineEventKind = "start"
+	TimelineEventFinish TimelineEventKind = "finish"
+)
+
+// TimelineEvent is a single unit start or finish recorded during a run.
+type TimelineEvent struct {
+	Unit string            `json:"unit"`
+	Kind TimelineEventKind `json:"kind"`
+	// Error is the error message of a failed unit, set on finish events only.
+	Error string `json:"error,omitempty"`
+	// Offset is the time elapsed between the start of the recording and the event.
+	Offset time.Duration `json:"offset"`
+	// Seq is the position of the event in the timeline.
+	Seq int `json:"seq"`
+}
+
+// TimelineRecorder records the order in which the units of a run start and finish.
+type TimelineRecorder struct {
+	clock  Clock
+	start  time.Time
+	events []TimelineEvent
+	mu     sync.Mutex
+}
+
+// NewTimelineRecorder creates a recorder whose event offsets are relative to now.
+func NewTimelineRecorder() *TimelineRecorder {
+	return newTimelineRecorder(realClock{})
+}
+
+// newTimelineRecorder creates a recorder whose event offsets are measured with the given clock, relative to now.
+func newTimelineRecorder(clock Clock) *TimelineRecorder {
+	return &TimelineRecorder{clock: clock, start: clock.Now()}
+}
+
+// Wrap returns a UnitRunner that records the start and finish of every unit it runs.
+func (rec *TimelineRecorder) Wrap(runner UnitRunner) UnitRunner {
+	return func(ctx context.Context, u *component.Unit) error {
+		rec.record(u.Path(), TimelineEventStart, nil)
+
+		err := runner(ctx, u)
+
+		rec.record(u.Path(), TimelineEventFinish, err)
+
+		return err
+	}
+}
+
+// Events returns the events recorded so far, in the order they happened.
+func (rec *TimelineRecorder) Events() []TimelineEvent {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	events := make([]TimelineEvent, len(rec.events))
+	copy(events, rec.events)
+
+	return events
+}
+
+// WriteFile writes the recorded events to the given file, one JSON object per line.
+func (rec *TimelineRecorder) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.New(err)
+	}
+
+	enc := json.NewEncoder(f)
+
+	for _, event := range rec.Events() {
+		if err := enc.Encode(event); err != nil {
+			f.Close() //nolint:errcheck
+			return errors.New(err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+func (rec *TimelineRecorder) record(unit string, kind TimelineEventKind, err error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	event := TimelineEvent{
+		Unit:   unit,
+		Kind:   kind,
+		Offset: rec.clock.Now().Sub(rec.start),
+		Seq:    len(rec.events),
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	rec.events = append(rec.events, event)
+}
+
+// ReadTimeline reads the events written by TimelineRecorder.WriteFile.
+func ReadTimeline(path string) ([]TimelineEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	var events []TimelineEvent
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event TimelineEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, errors.Errorf("invalid timeline event in %s: %w", path, err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return events, nil
+}
+
+// WithTimelineRecording records the start and finish of every unit and writes the timeline to the given
+// file once the run is over. The timeline can be replayed with a TimelineReplayer.
+func WithTimelineRecording(path string) ControllerOption {
+	return func(dr *Controller) {
+		dr.timelineFile = path
+	}
+}
+
+// recordTimeline wraps the runner with a timeline recorder when timeline recording is enabled.
+// The returned function writes the timeline file and must be called once the run is over.
+func (dr *Controller) recordTimeline(l log.Logger, runner UnitRunner) (UnitRunner, func()) {
+	if dr.timelineFile == "" {
+		return runner, func() {}
+	}
+
+	rec := newTimelineRecorder(dr.clock)
+
+	return rec.Wrap(runner), func() {
+		if err := rec.WriteFile(dr.timelineFile); err != nil {
+			l.Warnf("Failed to write run timeline to %s: %v", dr.timelineFile, err)
+		}
+	}
+}
+
+// ErrUnitNotInTimeline is returned when replaying a unit whose start and finish were not both recorded.
+var ErrUnitNotInTimeline = errors.New("unit not found in recorded timeline")
+
+// TimelineReplayer replays a recorded timeline instead of running units.
+//
+// Every unit waits until all events recorded before its start have been replayed, then starts, waits again
+// until all events recorded before its finish have been replayed, and finishes with the recorded outcome.
+// This reproduces the recorded dispatch order exactly, provided the controller schedules units the same way
+// it did during the recording. The recorded offsets are not enforced.
+type TimelineReplayer struct {
+	units    map[string]*replayedUnit
+	recorder *TimelineRecorder
+	turns    []chan struct{}
+}
+
+type replayedUnit struct {
+	err    error
+	start  int
+	finish int
+}
+
+// NewTimelineReplayer creates a replayer for the given events, in the order they were recorded.
+func NewTimelineReplayer(events []TimelineEvent) *TimelineReplayer {
+	rp := &TimelineReplayer{
+		units:    make(map[string]*replayedUnit, len(events)/2), //nolint:mnd
+		recorder: NewTimelineRecorder(),
+		turns:    make([]chan struct{}, len(events)+1),
+	}
+
+	for i := range rp.turns {
+		rp.turns[i] = make(chan struct{})
+	}
+
+	close(rp.turns[0])
+
+	for i, event := range events {
+		unit, ok := rp.units[event.Unit]
+		if !ok {
+			unit = &replayedUnit{start: -1, finish: -1}
+			rp.units[event.Unit] = unit
+		}
+
+		switch event.Kind {
+		case TimelineEventStart:
+			unit.start = i
+		case TimelineEventFinish:
+			unit.finish = i
+
+			if event.Error != "" {
+				unit.err = errors.New(event.Error)
+			}
+		}
+	}
+
+	return rp
+}
+
+// Runner returns the UnitRunner replaying the timeline.
+func (rp *TimelineReplayer) Runner() UnitRunner {
+	return func(ctx context.Context, u *component.Unit) error {
+		unit, ok := rp.units[u.Path()]
+		if !ok || unit.start < 0 || unit.finish < 0 {
+			return errors.Errorf("%w: %s", ErrUnitNotInTimeline, u.Path())
+		}
+
+		if err := rp.replay(ctx, unit.start, u.Path(), TimelineEventStart, nil); err != nil {
+			return err
+		}
+
+		if err := rp.replay(ctx, unit.finish, u.Path(), TimelineEventFinish, unit.err); err != nil {
+			return err
+		}
+
+		return unit.err
+	}
+}
+
+// Replayed returns the events replayed so far, in the order they were replayed.
+func (rp *TimelineReplayer) Replayed() []TimelineEvent {
+	return rp.recorder.Events()
+}
+
+// replay waits for the turn of the event at index i, records it and hands the turn to the next event.
+func (rp *TimelineReplayer) replay(ctx context.Context, i int, unit string, kind TimelineEventKind, err error) error {
+	select {
+	case <-rp.turns[i]:
+		rp.recorder.record(unit, kind, err)
+		close(rp.turns[i+1])
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
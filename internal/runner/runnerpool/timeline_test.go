@@ -0,0 +1,131 @@
+package runnerpool_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestController_TimelineRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	// A -> B, and independent C and D
+	newUnits := func() []*component.Unit {
+		return buildComponentUnits(
+			[]string{"A", "B", "C", "D"},
+			map[string][]string{
+				"B": {"A"},
+			},
+		)
+	}
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		if u.Path() == "C" {
+			return assert.AnError
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return nil
+	}
+
+	recorded := filepath.Join(t.TempDir(), "timeline.jsonl")
+
+	units := newUnits()
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithTimelineRecording(recorded),
+	)
+	require.Error(t, controller.Run(t.Context(), log.New()))
+
+	events, err := runnerpool.ReadTimeline(recorded)
+	require.NoError(t, err)
+	require.Len(t, events, 8)
+	assert.Less(t, eventIndex(events, "A", runnerpool.TimelineEventFinish), eventIndex(events, "B", runnerpool.TimelineEventStart))
+
+	for i := range 3 {
+		replayer := runnerpool.NewTimelineReplayer(events)
+
+		units := newUnits()
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(replayer.Runner()),
+		)
+		require.Error(t, controller.Run(t.Context(), log.New()))
+
+		replayedEvents := replayer.Replayed()
+		assert.Equal(t, timelineOrder(events), timelineOrder(replayedEvents), "replay %d", i)
+		assert.Equal(t, assert.AnError.Error(), replayedEvents[eventIndex(replayedEvents, "C", runnerpool.TimelineEventFinish)].Error)
+	}
+}
+
+func TestReplayRunner_UnknownUnit(t *testing.T) {
+	t.Parallel()
+
+	runner := runnerpool.NewTimelineReplayer(nil).Runner()
+
+	err := runner(t.Context(), component.NewUnit("A"))
+	require.ErrorIs(t, err, runnerpool.ErrUnitNotInTimeline)
+}
+
+func eventIndex(events []runnerpool.TimelineEvent, unit string, kind runnerpool.TimelineEventKind) int {
+	for i, event := range events {
+		if event.Unit == unit && event.Kind == kind {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func timelineOrder(events []runnerpool.TimelineEvent) []string {
+	order := make([]string, 0, len(events))
+	for _, event := range events {
+		order = append(order, event.Unit+" "+string(event.Kind))
+	}
+
+	return order
+}
+
+func TestController_TimelineOffsetsUseClock(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, map[string][]string{"B": {"A"}})
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	recorded := filepath.Join(t.TempDir(), "timeline.jsonl")
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			clock.Sleep(time.Minute)
+			return nil
+		}),
+		runnerpool.WithTimelineRecording(recorded),
+		runnerpool.WithClock(clock),
+	)
+	require.NoError(t, controller.Run(t.Context(), log.New()))
+
+	events, err := runnerpool.ReadTimeline(recorded)
+	require.NoError(t, err)
+
+	offsets := make([]time.Duration, 0, len(events))
+	for _, event := range events {
+		offsets = append(offsets, event.Offset)
+	}
+
+	// The offsets follow the clock of the controller, not the wall clock.
+	assert.Equal(t, []time.Duration{0, time.Minute, time.Minute, 2 * time.Minute}, offsets)
+}
@@ -0,0 +1,139 @@
+package runnerpool
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// UndeclaredDependencyWarning describes a unit reading the outputs of another unit of the run through a
+// dependency block while the run does not order it after that unit, so that both may run concurrently and
+// the unit may read stale or missing outputs.
+type UndeclaredDependencyWarning struct {
+	// UnitPath is the path of the unit reading the outputs.
+	UnitPath string
+	// DependencyName is the name of the dependency block reading the outputs.
+	DependencyName string
+	// DependencyPath is the path of the unit whose outputs are read.
+	DependencyPath string
+}
+
+// UndeclaredDependencies cross-references the dependency blocks of the parsed configuration of each unit
+// against the dependencies of the units, and returns the dependency blocks pointing at another of the
+// units that the unit does not depend on, directly or indirectly, sorted by unit path and block name.
+//
+// Disabled dependency blocks and blocks skipping outputs do not read outputs, so they are not reported,
+// nor are blocks pointing outside of the given units. Units without a parsed configuration are skipped.
+// This is a read-only analysis: the units are not modified.
+func UndeclaredDependencies(units []*component.Unit) []UndeclaredDependencyWarning {
+	byPath := make(map[string]*component.Unit, len(units))
+
+	for _, unit := range units {
+		if unit != nil {
+			byPath[filepath.Clean(unit.Path())] = unit
+		}
+	}
+
+	warnings := []UndeclaredDependencyWarning{}
+
+	for _, unit := range units {
+		if unit == nil {
+			continue
+		}
+
+		cfg := unit.Config()
+		if cfg == nil {
+			continue
+		}
+
+		var ordered map[string]bool
+
+		for _, dep := range cfg.TerragruntDependencies {
+			depPath, ok := dep.OutputsPath(unit.Path())
+			if !ok {
+				continue
+			}
+
+			target, ok := byPath[depPath]
+			if !ok || target == unit {
+				continue
+			}
+
+			if ordered == nil {
+				ordered = transitiveDependencyPaths(unit)
+			}
+
+			if ordered[filepath.Clean(target.Path())] {
+				continue
+			}
+
+			warnings = append(warnings, UndeclaredDependencyWarning{
+				UnitPath:       unit.Path(),
+				DependencyName: dep.Name,
+				DependencyPath: target.Path(),
+			})
+		}
+	}
+
+	slices.SortFunc(warnings, func(a, b UndeclaredDependencyWarning) int {
+		if c := strings.Compare(a.UnitPath, b.UnitPath); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.DependencyName, b.DependencyName)
+	})
+
+	return warnings
+}
+
+// transitiveDependencyPaths returns the cleaned paths of all the dependencies of the given unit, directly
+// or indirectly.
+func transitiveDependencyPaths(unit *component.Unit) map[string]bool {
+	visited := map[string]bool{}
+	pending := unit.Dependencies()
+
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		path := filepath.Clean(current.Path())
+		if visited[path] {
+			continue
+		}
+
+		visited[path] = true
+		pending = append(pending, current.Dependencies()...)
+	}
+
+	return visited
+}
+
+// WithUndeclaredDependencyWarning makes Run warn about the units reading the outputs of another unit of the
+// run through a dependency block without depending on it, see UndeclaredDependencies. Such units may run
+// concurrently with the unit they read, and read stale or missing outputs. The warning is disabled by default.
+func WithUndeclaredDependencyWarning(enabled bool) ControllerOption {
+	return func(dr *Controller) {
+		dr.warnUndeclared = enabled
+	}
+}
+
+// warnUndeclaredDependencies logs the dependency blocks of the units of the run reading the outputs of a
+// unit they do not depend on.
+func (dr *Controller) warnUndeclaredDependencies(l log.Logger) {
+	if !dr.warnUndeclared {
+		return
+	}
+
+	units := make([]*component.Unit, 0, len(dr.unitsMap))
+	for _, unit := range dr.unitsMap {
+		units = append(units, unit)
+	}
+
+	for _, warning := range UndeclaredDependencies(units) {
+		l.Warnf("Unit %s reads the outputs of %s through dependency %q without depending on it, they may run concurrently",
+			dr.unitsMap[warning.UnitPath].DisplayPath(), dr.unitsMap[warning.DependencyPath].DisplayPath(), warning.DependencyName)
+	}
+}
@@ -0,0 +1,83 @@
+package runnerpool_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+func TestUndeclaredDependencies(t *testing.T) {
+	t.Parallel()
+
+	// vpc <- db <- app: app reads vpc transitively, cache reads db without depending on it.
+	units := buildComponentUnits(
+		[]string{"vpc", "db", "app", "cache"},
+		map[string][]string{
+			"db":  {"vpc"},
+			"app": {"db"},
+		},
+	)
+
+	disabled := false
+	skip := true
+
+	units[2].StoreConfig(&config.TerragruntConfig{
+		TerragruntDependencies: config.Dependencies{
+			{Name: "vpc", ConfigPath: cty.StringVal("../vpc")},
+			{Name: "external", ConfigPath: cty.StringVal("../external")},
+		},
+	})
+	units[3].StoreConfig(&config.TerragruntConfig{
+		TerragruntDependencies: config.Dependencies{
+			{Name: "db", ConfigPath: cty.StringVal("../db/terragrunt.hcl")},
+			{Name: "disabled", ConfigPath: cty.StringVal("../app"), Enabled: &disabled},
+			{Name: "skipped", ConfigPath: cty.StringVal("../vpc"), SkipOutputs: &skip},
+		},
+	})
+
+	warnings := runnerpool.UndeclaredDependencies(units)
+
+	assert.Equal(t, []runnerpool.UndeclaredDependencyWarning{
+		{UnitPath: "cache", DependencyName: "db", DependencyPath: "db"},
+	}, warnings)
+}
+
+func TestController_UndeclaredDependencyWarning(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A", "B"}, nil)
+	units[1].StoreConfig(&config.TerragruntConfig{
+		TerragruntDependencies: config.Dependencies{
+			{Name: "a", ConfigPath: cty.StringVal("../A")},
+		},
+	})
+
+	for _, enabled := range []bool{true, false} {
+		buf := new(bytes.Buffer)
+		l := log.New(log.WithLevel(log.InfoLevel), log.WithOutput(buf))
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+			runnerpool.WithUndeclaredDependencyWarning(enabled),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), l))
+
+		if enabled {
+			assert.Contains(t, buf.String(), "Unit B reads the outputs of A through dependency")
+		} else {
+			assert.NotContains(t, buf.String(), "without depending on it")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package runnerpool
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-shellwords"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/configbridge"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/runner/common"
+	"github.com/gruntwork-io/terragrunt/internal/shell"
+	"github.com/gruntwork-io/terragrunt/internal/util"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+)
+
+const (
+	// UnitCmdUnitPathEnvName is the env var giving the path of the unit to the commands run for it.
+	UnitCmdUnitPathEnvName = "TG_CTX_UNIT_PATH"
+	// UnitCmdCommandEnvName is the env var giving the command the unit ran to the commands run for it.
+	UnitCmdCommandEnvName = "TG_CTX_COMMAND"
+	// UnitCmdPlanFileEnvName is the env var giving the plan file the unit run wrote to the plan file resolver
+	// command.
+	UnitCmdPlanFileEnvName = "TG_CTX_PLAN_FILE"
+)
+
+// unitSuccessCmd returns a UnitSuccessFunc running the given command after every successful run of a unit,
+// e.g. a script replicating the state of the unit to a secondary backend. The command runs in the directory
+// of the unit, with the env of the unit and the path of the unit and the command it ran in the
+// TG_CTX_UNIT_PATH and TG_CTX_COMMAND env vars. Returns an error if the command cannot be parsed.
+func unitSuccessCmd(l log.Logger, cmd string) (common.UnitSuccessFunc, error) {
+	args, err := parseUnitCmd("unit success command", cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, unit *component.Unit, opts *options.TerragruntOptions) error {
+		_, err := runUnitCmd(ctx, l, unit, opts, nil, false, args)
+
+		return err
+	}, nil
+}
+
+// planFileResolverCmd returns a PlanFileResolver running the given command for every unit whose plan is
+// converted to JSON, e.g. a script decrypting the plan written by the run. The command prints the path of the
+// plan file to convert, where an empty output skips the conversion. It runs like the command of unitSuccessCmd,
+// with the plan file the unit run wrote in the TG_CTX_PLAN_FILE env var as well. The plan file is relative to
+// the directory OpenTofu/Terraform ran in, unless an output folder is set.
+func planFileResolverCmd(l log.Logger, cmd string) (common.PlanFileResolver, error) {
+	args, err := parseUnitCmd("plan file resolver command", cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, unit *component.Unit, opts *options.TerragruntOptions) (string, error) {
+		env := map[string]string{
+			UnitCmdPlanFileEnvName: unit.PlanFile(opts.RootWorkingDir, opts.OutputFolder, opts.JSONOutputFolder, opts.TerraformCommand),
+		}
+
+		output, err := runUnitCmd(ctx, l, unit, opts, env, true, args)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(output.Stdout.String()), nil
+	}, nil
+}
+
+// parseUnitCmd splits the given command into its arguments, returning an error naming the command if it cannot
+// be parsed or is empty.
+func parseUnitCmd(name, cmd string) ([]string, error) {
+	// Normalize Windows paths before parsing, as shellwords treats backslashes as escape characters.
+	args, err := shellwords.NewParser().Parse(filepath.ToSlash(cmd))
+	if err != nil {
+		return nil, errors.Errorf("failed to parse the %s: %w", name, err)
+	}
+
+	if len(args) == 0 {
+		return nil, errors.Errorf("the %s %q is empty", name, cmd)
+	}
+
+	return args, nil
+}
+
+// runUnitCmd runs the given command for the unit in the directory of the unit, with the env of the unit, the
+// given env vars, and the path of the unit and the command it ran in the TG_CTX_UNIT_PATH and TG_CTX_COMMAND
+// env vars.
+func runUnitCmd(
+	ctx context.Context,
+	l log.Logger,
+	unit *component.Unit,
+	opts *options.TerragruntOptions,
+	env map[string]string,
+	suppressStdout bool,
+	args []string,
+) (*util.CmdOutput, error) {
+	cmdEnv := make(map[string]string, len(opts.Env)+len(env)+2) //nolint:mnd
+	maps.Copy(cmdEnv, opts.Env)
+	maps.Copy(cmdEnv, env)
+
+	cmdEnv[UnitCmdUnitPathEnvName] = unit.Path()
+	cmdEnv[UnitCmdCommandEnvName] = opts.TerraformCommand
+
+	shellOpts := configbridge.ShellRunOptsFromOpts(opts).WithEnv(cmdEnv)
+
+	return shell.RunCommandWithOutput(ctx, l, shellOpts, opts.WorkingDir, suppressStdout, false, args[0], args[1:]...)
+}
@@ -0,0 +1,190 @@
+package runnerpool_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestRunner_UnitSuccessCmd(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		exitCode  string
+		failUnit  bool
+		expectErr bool
+		expectLog string
+	}{
+		{
+			name:      "succeeds",
+			exitCode:  "0",
+			expectLog: "vpc apply\napp apply\n",
+		},
+		{
+			name:      "failure only warns",
+			exitCode:  "1",
+			expectLog: "vpc apply\napp apply\n",
+		},
+		{
+			name:      "failure fails the unit",
+			exitCode:  "1",
+			failUnit:  true,
+			expectErr: true,
+			expectLog: "vpc apply\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rootDir := helpers.TmpDirWOSymlinks(t)
+			logFile := filepath.Join(rootDir, "success.log")
+
+			files := map[string]string{
+				"vpc/terragrunt.hcl": "",
+				"vpc/main.tf":        "",
+				"app/terragrunt.hcl": "",
+				"app/main.tf":        "",
+				"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+esac
+exit 0
+`,
+				"success.sh": `#!/bin/sh
+echo "$(basename "$TG_CTX_UNIT_PATH") $TG_CTX_COMMAND" >> ` + logFile + `
+exit ` + tc.exitCode + `
+`,
+			}
+
+			for path, contents := range files {
+				path = filepath.Join(rootDir, path)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+			}
+
+			vpc := component.NewUnit(filepath.Join(rootDir, "vpc")).WithConfig(&config.TerragruntConfig{})
+			app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+			app.AddDependency(vpc)
+
+			opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+			require.NoError(t, err)
+
+			opts.WorkingDir = rootDir
+			opts.RootWorkingDir = rootDir
+			opts.TFPath = filepath.Join(rootDir, "tofu")
+			opts.TerraformCommand = "apply"
+			opts.TerraformCliArgs = iacargs.New("apply")
+			opts.UnitSuccessCmd = filepath.Join(rootDir, "success.sh")
+			opts.FailOnUnitSuccessCmdError = tc.failUnit
+
+			l := thlogger.CreateLogger()
+
+			runner, err := runnerpool.NewRunnerPoolStack(t.Context(), l, opts, component.Components{vpc, app})
+			require.NoError(t, err)
+
+			err = runner.Run(t.Context(), l, opts, nil)
+			if tc.expectErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "on success hook for unit "+vpc.Path()+" failed")
+			} else {
+				require.NoError(t, err)
+			}
+
+			// The command runs after each unit, before the units depending on it are started.
+			successLog, err := os.ReadFile(logFile)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectLog, string(successLog))
+		})
+	}
+
+	opts, err := options.NewTerragruntOptionsForTest("/tmp/test/terragrunt.hcl")
+	require.NoError(t, err)
+
+	opts.UnitSuccessCmd = `backup "state`
+
+	_, err = runnerpool.NewRunnerPoolStack(
+		t.Context(),
+		thlogger.CreateLogger(),
+		opts,
+		component.Components{component.NewUnit("/tmp/test/vpc").WithConfig(&config.TerragruntConfig{})},
+	)
+	require.Error(t, err)
+}
+
+func TestRunner_PlanFileResolverCmd(t *testing.T) {
+	t.Parallel()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+	jsonDir := filepath.Join(rootDir, "json")
+	decrypted := filepath.Join(rootDir, "decrypted.tfplan")
+
+	files := map[string]string{
+		"app/terragrunt.hcl": "",
+		"app/main.tf":        "",
+		// The show call echoes the plan file it reads.
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0" ;;
+  plan) for arg; do case "$arg" in -out=*) echo "encrypted" > "${arg#-out=}" ;; esac; done ;;
+  show) echo "{\"resource_changes\":[],\"plan_file\":\"$3\"}" ;;
+esac
+exit 0
+`,
+		"decrypt.sh": `#!/bin/sh
+[ "$TG_CTX_COMMAND" = plan ] || exit 1
+cp "$TG_CTX_PLAN_FILE" ` + decrypted + `
+echo ` + decrypted + `
+`,
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	app := component.NewUnit(filepath.Join(rootDir, "app")).WithConfig(&config.TerragruntConfig{})
+	app.SetDiscoveryContext(&component.DiscoveryContext{WorkingDir: rootDir})
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "plan"
+	opts.TerraformCliArgs = iacargs.New("plan")
+	opts.OutputFolder = filepath.Join(rootDir, "plans")
+	opts.JSONOutputFolder = jsonDir
+	opts.PlanFileResolverCmd = filepath.Join(rootDir, "decrypt.sh")
+
+	l := thlogger.CreateLogger()
+
+	runner, err := runnerpool.NewRunnerPoolStack(t.Context(), l, opts, component.Components{app})
+	require.NoError(t, err)
+
+	require.NoError(t, runner.Run(t.Context(), l, opts, nil))
+
+	// The plan file printed by the command is converted, after the command read the plan the run wrote.
+	planJSON, err := os.ReadFile(filepath.Join(jsonDir, "app", "tfplan.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(planJSON), `"plan_file":"`+decrypted+`"`)
+
+	plan, err := os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted\n", string(plan))
+}
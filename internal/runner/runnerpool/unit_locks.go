@@ -0,0 +1,41 @@
+package runnerpool
+
+import (
+	"sync"
+
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// UnitLockFunc returns the lock the unit at the given path holds while it runs, e.g. a lock shared by the runs
+// of a unit expanded from its matrix, or nil when the unit does not need one.
+type UnitLockFunc func(path string) *sync.Mutex
+
+// WithUnitLocks makes every unit hold the lock returned by lockOf while it runs. The lock is taken before the
+// concurrency slot of the unit: a unit whose lock is held by another unit is held back, without taking a slot
+// away from the other ready units, and starts once that unit finishes.
+func WithUnitLocks(lockOf UnitLockFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.unitLock = lockOf
+	}
+}
+
+// tryLockUnit takes the lock of the unit of the entry and returns the function releasing it, or false when
+// another unit holds the lock, in which case the entry is left ready to be dispatched later.
+func (dr *Controller) tryLockUnit(l log.Logger, ent *queue.Entry) (func(), bool) {
+	if dr.unitLock == nil {
+		return func() {}, true
+	}
+
+	lock := dr.unitLock(ent.Component.Path())
+	if lock == nil {
+		return func() {}, true
+	}
+
+	if !lock.TryLock() {
+		l.Debugf("Runner Pool Controller: holding back %s, its lock is held by another unit", ent.Component.DisplayPath())
+		return nil, false
+	}
+
+	return lock.Unlock, true
+}
@@ -0,0 +1,81 @@
+package runnerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func TestController_UnitLocks(t *testing.T) {
+	t.Parallel()
+
+	// A and B share a lock, C does not need one.
+	units := buildComponentUnits([]string{"A", "B", "C"}, nil)
+
+	var (
+		shared   sync.Mutex
+		mu       sync.Mutex
+		running  = map[string]bool{}
+		overlaps int
+		ran      int
+		cStarted = make(chan struct{})
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		if (u.Path() == "A" && running["B"]) || (u.Path() == "B" && running["A"]) {
+			overlaps++
+		}
+
+		running[u.Path()] = true
+		ran++
+		mu.Unlock()
+
+		if u.Path() == "C" {
+			close(cStarted)
+		} else {
+			// The unit holding the lock waits for C, which only gets a slot if the unit held back by the
+			// lock does not take the other one.
+			select {
+			case <-cStarted:
+			case <-time.After(5 * time.Second):
+			}
+		}
+
+		mu.Lock()
+		delete(running, u.Path())
+		mu.Unlock()
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(2),
+		runnerpool.WithUnitLocks(func(path string) *sync.Mutex {
+			if path == "C" {
+				return nil
+			}
+
+			return &shared
+		}),
+	)
+
+	start := time.Now()
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.Zero(t, overlaps, "units sharing a lock should not run concurrently")
+	assert.Equal(t, 3, ran)
+	assert.Less(t, time.Since(start), 5*time.Second, "a unit held back by its lock should not take a concurrency slot")
+}
@@ -0,0 +1,254 @@
+package runnerpool
+
+import (
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+// WaveFunc is a hook invoked with the index of a dependency wave and the units that belong to it.
+type WaveFunc func(index int, units []*component.Unit) error
+
+// WithBeforeWave sets a hook that is invoked before the units of each dependency wave are started.
+// A non-nil error aborts the run before the wave starts; units that have not started are marked as early exit.
+//
+// Setting this hook switches the controller to staged execution, where a wave only starts once every
+// unit of the previous wave has finished.
+func WithBeforeWave(fn WaveFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.beforeWave = fn
+		dr.staged = true
+	}
+}
+
+// WithAfterWave sets a hook that is invoked once every unit of a dependency wave has finished.
+// A non-nil error aborts the run; units that have not started are marked as early exit.
+//
+// Setting this hook switches the controller to staged execution.
+func WithAfterWave(fn WaveFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.afterWave = fn
+		dr.staged = true
+	}
+}
+
+// RunSummary is the outcome of the units of a finished dependency wave, listed by path in wave order.
+type RunSummary struct {
+	// Succeeded holds the units that ran successfully.
+	Succeeded []string
+	// Failed holds the units that failed, including the quarantined and non-blocking units whose failures
+	// were ignored.
+	Failed []string
+	// Skipped holds the units that did not run, because they were skipped or the run exited early.
+	Skipped []string
+}
+
+// WaveBarrierFunc is a hook invoked with the index and the summary of each dependency wave once every unit
+// of the wave has finished. Returning true makes the next wave a barrier: the wave after it only starts once
+// every unit of the next wave has finished. Returning false lets the wave after it overlap with the next wave,
+// each of its units starting as soon as its dependencies are done.
+type WaveBarrierFunc func(index int, results RunSummary) bool
+
+// WithWaveBarrier sets a hook deciding, from the results of each finished wave, whether the next wave is a
+// barrier or lets the wave after it overlap, e.g. to only wait for every unit after risky waves, or after a
+// wave with failures. Before wave hooks and approvals run when a wave opens, possibly before the previous
+// wave has finished, and after wave hooks still run once a wave has finished, in wave order.
+//
+// Setting this hook switches the controller to staged execution, where every wave is a barrier until the
+// first wave finishes.
+func WithWaveBarrier(fn WaveBarrierFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.waveBarrier = fn
+		dr.staged = true
+	}
+}
+
+// waveSummary returns the outcome of the units of the finished wave at the given index.
+func (dr *Controller) waveSummary(index int) RunSummary {
+	summary := RunSummary{}
+
+	for _, e := range dr.waves[index] {
+		switch e.Status {
+		case queue.StatusSucceeded:
+			if dr.ignoredFailure(e.Component.Path()) != nil {
+				summary.Failed = append(summary.Failed, e.Component.Path())
+				continue
+			}
+
+			summary.Succeeded = append(summary.Succeeded, e.Component.Path())
+		case queue.StatusFailed:
+			summary.Failed = append(summary.Failed, e.Component.Path())
+		default:
+			summary.Skipped = append(summary.Skipped, e.Component.Path())
+		}
+	}
+
+	return summary
+}
+
+// WithParallelismSchedule sets the parallelism of each dependency wave: the value at index i is the number
+// of units of wave i that may run at once, and the last value applies to every later wave. This allows
+// starting at a low parallelism while the first waves provision shared infrastructure, such as caches or
+// registries, and ramping up once it is in place.
+//
+// Setting a schedule switches the controller to staged execution, and overrides WithMaxConcurrency.
+// Every value must be positive, or Run fails with an InvalidParallelismScheduleError.
+func WithParallelismSchedule(schedule ...int) ControllerOption {
+	return func(dr *Controller) {
+		dr.parallelismSchedule = schedule
+
+		if len(schedule) > 0 {
+			dr.staged = true
+		}
+	}
+}
+
+// validateParallelismSchedule returns an error for the first value of the schedule that is not positive.
+func validateParallelismSchedule(schedule []int) error {
+	for i, parallelism := range schedule {
+		if parallelism <= 0 {
+			return errors.New(InvalidParallelismScheduleError{Wave: i, Parallelism: parallelism})
+		}
+	}
+
+	return nil
+}
+
+// resizeForWave resizes the semaphore bounding the units of the run to the parallelism the parallelism
+// schedule sets for the current wave, if any.
+func (dr *Controller) resizeForWave(l log.Logger) {
+	if len(dr.parallelismSchedule) == 0 || dr.currentWave < 0 || dr.currentWave >= len(dr.waves) {
+		return
+	}
+
+	parallelism := dr.parallelismSchedule[min(dr.currentWave, len(dr.parallelismSchedule)-1)]
+	if parallelism == dr.slots.size() {
+		return
+	}
+
+	l.Debugf("Runner Pool Controller: running wave %d with parallelism %d", dr.currentWave, parallelism)
+
+	dr.slots.resize(parallelism)
+}
+
+// UnitStartFunc is a hook invoked with a unit and the index of the dependency wave it belongs to,
+// or -1 if the unit was added to the run after it started.
+type UnitStartFunc func(unit *component.Unit, wave int)
+
+// WithOnUnitStart sets a hook that is invoked right before each unit runs, once its dependencies have
+// finished and it holds a concurrency slot. Together with the runner returning, it brackets the actual
+// execution of the unit, excluding the time spent waiting to be scheduled.
+//
+// The hook is invoked synchronously from the unit goroutines, so it must be cheap and safe for concurrent use.
+func WithOnUnitStart(fn UnitStartFunc) ControllerOption {
+	return func(dr *Controller) {
+		dr.onUnitStart = fn
+	}
+}
+
+// unitStarted notifies the start hook that the given unit is about to run.
+func (dr *Controller) unitStarted(unit *component.Unit) {
+	if dr.onUnitStart == nil {
+		return
+	}
+
+	wave, ok := dr.waveIndex[unit.Path()]
+	if !ok {
+		wave = -1
+	}
+
+	dr.onUnitStart(unit, wave)
+}
+
+// initWaves computes the dependency waves of the queue for staged execution.
+func (dr *Controller) initWaves() {
+	dr.waves = dr.q.Waves()
+	dr.waveIndex = make(map[string]int, len(dr.q.Snapshot()))
+	dr.currentWave = -1
+	dr.closedWaves = 0
+	dr.overlapWaves = false
+	dr.pendingCanaries = nil
+
+	for i, wave := range dr.waves {
+		for _, e := range wave {
+			dr.waveIndex[e.Component.Path()] = i
+		}
+	}
+}
+
+// advanceWaves runs the after hooks of the waves that have finished, in order, and opens the next wave once
+// every open wave has finished, or as soon as the previous one has when the wave barrier allows an overlap,
+// invoking the wave hooks and picking the wave canaries along the way.
+func (dr *Controller) advanceWaves(l log.Logger) error {
+	if err := dr.checkCanaries(l); err != nil {
+		return err
+	}
+
+	for {
+		for dr.closedWaves <= dr.currentWave && dr.q.AllFinished(dr.waves[dr.closedWaves]) {
+			index := dr.closedWaves
+
+			if dr.afterWave != nil {
+				if err := dr.afterWave(index, dr.waveUnits(index)); err != nil {
+					return errors.Errorf("after wave %d: %w", index, err)
+				}
+			}
+
+			dr.closedWaves++
+			dr.overlapWaves = dr.waveBarrier != nil && !dr.waveBarrier(index, dr.waveSummary(index))
+		}
+
+		// The wave after the first unfinished one may only open early when the barrier allows an overlap.
+		lastOpen := dr.closedWaves
+		if dr.overlapWaves {
+			lastOpen++
+		}
+
+		if dr.currentWave >= lastOpen || dr.currentWave+1 >= len(dr.waves) {
+			return nil
+		}
+
+		dr.currentWave++
+
+		l.Debugf("Runner Pool Controller: starting wave %d with %d tasks", dr.currentWave, len(dr.waves[dr.currentWave]))
+
+		if dr.beforeWave != nil {
+			if err := dr.beforeWave(dr.currentWave, dr.waveUnits(dr.currentWave)); err != nil {
+				return errors.Errorf("before wave %d: %w", dr.currentWave, err)
+			}
+		}
+
+		if err := dr.approveCurrentWave(l); err != nil {
+			return err
+		}
+
+		dr.pickCanary(l, dr.currentWave)
+	}
+}
+
+// inCurrentWave filters ready entries down to those allowed to start in the current wave.
+func (dr *Controller) inCurrentWave(entries []*queue.Entry) []*queue.Entry {
+	out := make([]*queue.Entry, 0, len(entries))
+
+	for _, e := range entries {
+		if dr.waveIndex[e.Component.Path()] <= dr.currentWave {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// waveUnits returns the units that belong to the wave at the given index.
+func (dr *Controller) waveUnits(index int) []*component.Unit {
+	units := make([]*component.Unit, 0, len(dr.waves[index]))
+
+	for _, e := range dr.waves[index] {
+		if unit := dr.unit(e.Component.Path()); unit != nil {
+			units = append(units, unit)
+		}
+	}
+
+	return units
+}
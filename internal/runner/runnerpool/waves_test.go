@@ -0,0 +1,421 @@
+package runnerpool_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+func unitPaths(units []*component.Unit) []string {
+	paths := make([]string, 0, len(units))
+	for _, u := range units {
+		paths = append(paths, u.Path())
+	}
+
+	return paths
+}
+
+func TestController_WaveHooks(t *testing.T) {
+	t.Parallel()
+
+	//   A
+	//  / \
+	// B   C
+	//  \ /
+	//   D
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"A"},
+			"D": {"B", "C"},
+		},
+	)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+	}
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		record("run " + u.Path())
+		return nil
+	}
+
+	var beforeWaves [][]string
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithBeforeWave(func(index int, units []*component.Unit) error {
+			beforeWaves = append(beforeWaves, unitPaths(units))
+			record("before")
+
+			return nil
+		}),
+		runnerpool.WithAfterWave(func(index int, units []*component.Unit) error {
+			record("after")
+			return nil
+		}),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.Equal(t, [][]string{{"A"}, {"B", "C"}, {"D"}}, beforeWaves)
+	assert.Equal(t, []string{
+		"before", "run A", "after",
+		"before", "run B", "run C", "after",
+		"before", "run D", "after",
+	}, events)
+}
+
+func TestController_BeforeWaveErrorAborts(t *testing.T) {
+	t.Parallel()
+
+	// A -> B -> C
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	var ran []string
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		ran = append(ran, u.Path())
+		return nil
+	}
+
+	gateErr := errors.New("health gate failed")
+	q := buildQueue(t, units)
+
+	controller := runnerpool.NewController(
+		q,
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithBeforeWave(func(index int, _ []*component.Unit) error {
+			if index == 1 {
+				return gateErr
+			}
+
+			return nil
+		}),
+	)
+
+	err := controller.Run(t.Context(), logger.CreateLogger())
+	require.ErrorIs(t, err, gateErr)
+	assert.Equal(t, []string{"A"}, ran)
+
+	assert.Equal(t, queue.StatusSucceeded, q.EntryByPath("A").Status)
+	assert.Equal(t, queue.StatusEarlyExit, q.EntryByPath("B").Status)
+	assert.Equal(t, queue.StatusEarlyExit, q.EntryByPath("C").Status)
+}
+
+func TestController_OnUnitStart(t *testing.T) {
+	t.Parallel()
+
+	// A <- B <- C
+	units := buildComponentUnits(
+		[]string{"A", "B", "C"},
+		map[string][]string{
+			"B": {"A"},
+			"C": {"B"},
+		},
+	)
+
+	var (
+		mu      sync.Mutex
+		events  []string
+		started = map[string]int{}
+	)
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, "run "+u.Path())
+
+		return nil
+	}
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithOnUnitStart(func(unit *component.Unit, wave int) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			events = append(events, "start "+unit.Path())
+			started[unit.Path()] = wave
+		}),
+	)
+
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	assert.Equal(t, []string{"start A", "run A", "start B", "run B", "start C", "run C"}, events)
+	assert.Equal(t, map[string]int{"A": 0, "B": 1, "C": 2}, started)
+}
+
+func TestController_ParallelismSchedule(t *testing.T) {
+	t.Parallel()
+
+	// Wave 0: A, B, C. Wave 1: D, E, F, G, all depending on A. Wave 2: H, depending on D.
+	units := buildComponentUnits(
+		[]string{"A", "B", "C", "D", "E", "F", "G", "H"},
+		map[string][]string{
+			"D": {"A"},
+			"E": {"A"},
+			"F": {"A"},
+			"G": {"A"},
+			"H": {"D"},
+		},
+	)
+
+	var (
+		mu      sync.Mutex
+		running int
+		peaks   = map[string]int{}
+	)
+
+	waveOf := map[string]string{"A": "0", "B": "0", "C": "0", "H": "2"}
+
+	runner := func(ctx context.Context, u *component.Unit) error {
+		wave, ok := waveOf[u.Path()]
+		if !ok {
+			wave = "1"
+		}
+
+		mu.Lock()
+		running++
+		peaks[wave] = max(peaks[wave], running)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return nil
+	}
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(runner),
+		runnerpool.WithMaxConcurrency(10),
+		runnerpool.WithParallelismSchedule(1, 3),
+	).Run(t.Context(), logger.CreateLogger())
+	require.NoError(t, err)
+
+	// The first wave runs one unit at a time, later waves use the last value of the schedule.
+	assert.Equal(t, 1, peaks["0"])
+	assert.Equal(t, 3, peaks["1"])
+	assert.Equal(t, 1, peaks["2"])
+}
+
+func TestController_ParallelismScheduleInvalid(t *testing.T) {
+	t.Parallel()
+
+	units := buildComponentUnits([]string{"A"}, map[string][]string{})
+
+	err := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+		runnerpool.WithParallelismSchedule(2, 0),
+	).Run(t.Context(), logger.CreateLogger())
+
+	var invalid runnerpool.InvalidParallelismScheduleError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, 1, invalid.Wave)
+}
+
+func TestController_WaveBarrier(t *testing.T) {
+	t.Parallel()
+
+	// Waves: [A], [B, C], [D], with D only depending on B.
+	depMap := map[string][]string{
+		"B": {"A"},
+		"C": {"A"},
+		"D": {"B"},
+	}
+
+	for _, barrier := range []bool{true, false} {
+		units := buildComponentUnits([]string{"A", "B", "C", "D"}, depMap)
+
+		var (
+			mu        sync.Mutex
+			finished  []string
+			summaries []runnerpool.RunSummary
+			dStarted  = make(chan struct{})
+		)
+
+		runner := func(ctx context.Context, u *component.Unit) error {
+			switch u.Path() {
+			case "C":
+				// C outlives D when the waves overlap.
+				select {
+				case <-dStarted:
+				case <-time.After(200 * time.Millisecond):
+				}
+			case "D":
+				close(dStarted)
+			}
+
+			mu.Lock()
+			finished = append(finished, u.Path())
+			mu.Unlock()
+
+			return nil
+		}
+
+		controller := runnerpool.NewController(
+			buildQueue(t, units),
+			units,
+			runnerpool.WithRunner(runner),
+			runnerpool.WithMaxConcurrency(4),
+			runnerpool.WithWaveBarrier(func(index int, results runnerpool.RunSummary) bool {
+				summaries = append(summaries, results)
+				return barrier
+			}),
+		)
+
+		require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+		require.Len(t, summaries, 3)
+		assert.Equal(t, []string{"A"}, summaries[0].Succeeded)
+		assert.ElementsMatch(t, []string{"B", "C"}, summaries[1].Succeeded)
+
+		if barrier {
+			assert.Equal(t, "D", finished[3], "D should wait for every unit of wave 1")
+		} else {
+			assert.Equal(t, "C", finished[3], "D should start as soon as B is done")
+		}
+	}
+}
+
+func TestRunner_WaveBarrierOnFailure(t *testing.T) {
+	t.Parallel()
+
+	// Waves: [a, f], [b, c], [d], with d only depending on b, and c outliving b.
+	for _, failing := range []bool{true, false} {
+		rootDir := helpers.TmpDirWOSymlinks(t)
+		logFile := filepath.Join(rootDir, "tofu.log")
+
+		exitCode := "0"
+		if failing {
+			exitCode = "1"
+		}
+
+		files := map[string]string{
+			"modules/unit/main.tf": "",
+			// The fake binary logs the start and end of every apply, and fails the apply of f when failing.
+			"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0"; exit 0 ;;
+  init) exit 0 ;;
+esac
+echo "start $TF_VAR_name" >> ` + logFile + `
+[ "$TF_VAR_name" = c ] && sleep 1
+echo "end $TF_VAR_name" >> ` + logFile + `
+[ "$TF_VAR_name" = f ] && exit ` + exitCode + `
+exit 0
+`,
+		}
+
+		units := map[string]*component.Unit{}
+
+		for _, name := range []string{"a", "f", "b", "c", "d"} {
+			files[name+"/terragrunt.hcl"] = `terraform {
+  source = "../modules/unit"
+}
+
+inputs = {
+  name = "` + name + `"
+}
+`
+			units[name] = component.NewUnit(filepath.Join(rootDir, name)).WithConfig(&config.TerragruntConfig{})
+		}
+
+		units["b"].AddDependency(units["a"])
+		units["c"].AddDependency(units["a"])
+		units["d"].AddDependency(units["b"])
+
+		for path, contents := range files {
+			path = filepath.Join(rootDir, path)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+		}
+
+		opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+		require.NoError(t, err)
+
+		opts.WorkingDir = rootDir
+		opts.RootWorkingDir = rootDir
+		opts.TFPath = filepath.Join(rootDir, "tofu")
+		opts.TerraformCommand = "apply"
+		opts.TerraformCliArgs = iacargs.New("apply")
+		opts.WaveBarrierOnFailure = true
+
+		l := logger.CreateLogger()
+
+		stack, err := runnerpool.NewRunnerPoolStack(
+			context.Background(),
+			l,
+			opts,
+			component.Components{units["a"], units["f"], units["b"], units["c"], units["d"]},
+		)
+		require.NoError(t, err)
+
+		err = stack.Run(t.Context(), l, opts, report.NewReport())
+		if failing {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+
+		runLog, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+
+		events := strings.Split(strings.TrimSpace(string(runLog)), "\n")
+		require.Len(t, events, 10)
+
+		if failing {
+			assert.Less(t, slices.Index(events, "end c"), slices.Index(events, "start d"), "d should wait for every unit of wave 1")
+		} else {
+			assert.Less(t, slices.Index(events, "start d"), slices.Index(events, "end c"), "d should start as soon as b is done")
+		}
+	}
+}
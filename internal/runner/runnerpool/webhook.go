@@ -0,0 +1,350 @@
+package runnerpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/puzpuzpuz/xsync/v3"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/queue"
+	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/pkg/log"
+)
+
+const (
+	// resultWebhookQueueSize bounds how many results may wait to be posted to the result webhook.
+	resultWebhookQueueSize = 256
+	// resultWebhookAttempts is the number of times posting a result is attempted.
+	resultWebhookAttempts = 3
+	// resultWebhookTimeout bounds how long a single post to the result webhook may take.
+	resultWebhookTimeout = 10 * time.Second
+	// resultWebhookRetryDelay is the delay between two attempts to post a result.
+	resultWebhookRetryDelay = time.Second
+	// resultWebhookDrainTimeout bounds how long the run waits for the queued results to be delivered once
+	// every unit has finished.
+	resultWebhookDrainTimeout = 30 * time.Second
+)
+
+// WebhookResult is the JSON body posted to the result webhook when a unit finishes.
+type WebhookResult struct {
+	Path       string `json:"path"`
+	Result     string `json:"result"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// WithResultWebhook posts the result of every unit to the given URL as it finishes, as a JSON encoded
+// WebhookResult, e.g. for deployment trackers. The result and reason are taken from the run of the unit in
+// the given report, when there is one, and from the error of the unit otherwise. The units that finish
+// without running, e.g. exiting early after a failed dependency or not started before the deadline of the
+// run, are posted once every unit has finished.
+//
+// Results are posted asynchronously from a bounded queue, so that a slow endpoint does not stall the run,
+// and every post is retried a few times. Results that cannot be queued or delivered only log a warning.
+// Once every unit has finished, the run waits up to 30 seconds for the queued results to be delivered, and
+// drops the rest.
+func WithResultWebhook(url string, r *report.Report) ControllerOption {
+	return func(dr *Controller) {
+		dr.resultWebhook = url
+		dr.webhookReport = r
+	}
+}
+
+// WithResultWebhookFilter passes every line of the errors posted to the result webhook through the given
+// filter, e.g. the output filter redacting secrets from the output of the units.
+func WithResultWebhookFilter(filter OutputFilter) ControllerOption {
+	return func(dr *Controller) {
+		dr.webhookFilter = filter
+	}
+}
+
+// webhookPoster posts queued results to the result webhook from a single goroutine.
+type webhookPoster struct {
+	client *http.Client
+	clock  Clock
+	l      log.Logger
+	// cancel stops the post in flight and drops the queued results, once the drain timeout passed.
+	cancel  context.CancelFunc
+	results chan WebhookResult
+	// posted holds the paths of the units whose result was queued.
+	queued map[string]bool
+	url    string
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// postResults wraps the runner so that it posts the result of every unit to the result webhook, when one is
+// set. The returned function posts the results of the units that finished without running, taking their
+// errors from results, then waits for the queued results to be delivered. It must be called once the run is
+// over.
+func (dr *Controller) postResults(
+	ctx context.Context,
+	l log.Logger,
+	runner UnitRunner,
+	results *xsync.MapOf[string, error],
+) (UnitRunner, func()) {
+	if dr.resultWebhook == "" {
+		return runner, func() {}
+	}
+
+	// Delivering results outlives the cancellation of the run, so that the results of cancelled units are
+	// still posted, until the drain timeout passes.
+	postCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	poster := &webhookPoster{
+		client:  &http.Client{Timeout: resultWebhookTimeout},
+		clock:   dr.clock,
+		l:       l,
+		cancel:  cancel,
+		results: make(chan WebhookResult, resultWebhookQueueSize),
+		queued:  make(map[string]bool),
+		url:     dr.resultWebhook,
+	}
+
+	poster.wg.Go(func() {
+		for result := range poster.results {
+			// Once cancelled, the remaining results are dropped.
+			if postCtx.Err() != nil {
+				continue
+			}
+
+			poster.post(postCtx, result)
+		}
+	})
+
+	posting := func(ctx context.Context, u *component.Unit) error {
+		started := dr.clock.Now()
+
+		err := runner(ctx, u)
+
+		result := dr.webhookResult(u, err)
+		result.DurationMS = dr.clock.Now().Sub(started).Milliseconds()
+
+		poster.queue(result)
+
+		return err
+	}
+
+	return posting, func() {
+		dr.postUnrun(poster, results)
+		poster.close()
+	}
+}
+
+// postUnrun queues the result of every unit that finished without running and was not queued yet.
+func (dr *Controller) postUnrun(poster *webhookPoster, results *xsync.MapOf[string, error]) {
+	for _, entry := range dr.q.Snapshot() {
+		switch entry.Status { //nolint:exhaustive
+		case queue.StatusSucceeded, queue.StatusFailed, queue.StatusEarlyExit, queue.StatusSkipped:
+		default:
+			continue
+		}
+
+		if poster.wasQueued(entry.Component.Path()) {
+			continue
+		}
+
+		err, _ := results.Load(entry.Component.Path())
+
+		poster.queue(dr.unrunWebhookResult(entry, err))
+	}
+}
+
+// unrunWebhookResult returns the result to post to the result webhook for the entry of a unit that finished
+// without running, with the error it was failed with, if any. As for the units that ran, the run of the unit in
+// the report, if finished, decides over the status of the entry.
+func (dr *Controller) unrunWebhookResult(entry *queue.Entry, err error) WebhookResult {
+	path := entry.Component.Path()
+
+	result := WebhookResult{
+		Path:   path,
+		Result: string(report.ResultFailed),
+		Reason: string(report.ReasonRunError),
+	}
+
+	var timeoutErr DependencyWaitTimeoutError
+
+	switch {
+	case entry.Status == queue.StatusSucceeded:
+		result.Result, result.Reason = string(report.ResultSucceeded), ""
+	case entry.Status == queue.StatusSkipped:
+		result.Result, result.Reason = string(report.ResultExcluded), string(report.ReasonUserSkipped)
+	case entry.Status == queue.StatusEarlyExit, err == nil:
+		result.Result, result.Reason = string(report.ResultEarlyExit), string(report.ReasonAncestorError)
+	case errors.As(err, &timeoutErr):
+		result.Reason = string(report.ReasonDependencyTimeout)
+	case errors.Is(err, context.DeadlineExceeded):
+		result.Reason = string(report.ReasonDeadlineExceeded)
+	case dr.Cancelled()[path] != nil:
+		result.Reason = string(report.ReasonCancelled)
+	}
+
+	if err != nil && result.Result == string(report.ResultFailed) {
+		result.Error = dr.filterWebhookError(path, err.Error())
+	}
+
+	return dr.reportedWebhookResult(result)
+}
+
+// webhookResult returns the result of the unit to post to the result webhook, from the report if the unit
+// has a finished run in it, or from the error it returned otherwise.
+func (dr *Controller) webhookResult(u *component.Unit, err error) WebhookResult {
+	result := WebhookResult{
+		Path:   u.Path(),
+		Result: string(report.ResultSucceeded),
+	}
+
+	if err != nil {
+		result.Result = string(report.ResultFailed)
+		result.Reason = string(report.ReasonRunError)
+		result.Error = dr.filterWebhookError(u.Path(), err.Error())
+	}
+
+	return dr.reportedWebhookResult(result)
+}
+
+// reportedWebhookResult returns the given result with the result and reason of the run of its unit in the
+// report, if the unit has a finished run in it.
+func (dr *Controller) reportedWebhookResult(result WebhookResult) WebhookResult {
+	if dr.webhookReport == nil {
+		return result
+	}
+
+	run, reportErr := dr.webhookReport.GetRun(filepath.Clean(result.Path))
+	if reportErr != nil || run.Result == "" {
+		return result
+	}
+
+	result.Result = string(run.Result)
+	result.Reason = ""
+
+	if run.Reason != nil {
+		result.Reason = string(*run.Reason)
+	}
+
+	return result
+}
+
+// filterWebhookError passes every line of the error message of the unit at the given path through the result
+// webhook filter, if any, dropping the lines it drops.
+func (dr *Controller) filterWebhookError(unitPath, msg string) string {
+	if dr.webhookFilter == nil {
+		return msg
+	}
+
+	lines := strings.Split(msg, "\n")
+	filtered := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if out := dr.webhookFilter(unitPath, []byte(line)); out != nil {
+			filtered = append(filtered, string(out))
+		}
+	}
+
+	return strings.Join(filtered, "\n")
+}
+
+// queue queues a result to be posted, or logs a warning if too many results are already waiting.
+func (p *webhookPoster) queue(result WebhookResult) {
+	p.mu.Lock()
+	p.queued[result.Path] = true
+	p.mu.Unlock()
+
+	select {
+	case p.results <- result:
+	default:
+		p.l.Warnf("Not posting the result of unit %s to webhook %s: too many results are waiting to be delivered",
+			result.Path, p.url)
+	}
+}
+
+// wasQueued returns whether the result of the unit at the given path was already queued.
+func (p *webhookPoster) wasQueued(path string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.queued[path]
+}
+
+// post posts a result to the webhook, retrying failed attempts, and logs a warning if it cannot be delivered.
+func (p *webhookPoster) post(ctx context.Context, result WebhookResult) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		p.l.Warnf("Failed to encode the result of unit %s for webhook %s: %v", result.Path, p.url, err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = p.send(ctx, body); err == nil {
+			return
+		}
+
+		if attempt >= resultWebhookAttempts || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-p.clock.After(resultWebhookRetryDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	p.l.Warnf("Failed to post the result of unit %s to webhook %s after %d attempts: %v",
+		result.Path, p.url, resultWebhookAttempts, err)
+}
+
+// send makes a single attempt at posting an encoded result to the webhook.
+func (p *webhookPoster) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// close stops accepting results and waits for the queued ones to be delivered, up to the drain timeout, past
+// which the post in flight is cancelled and the remaining results are dropped.
+func (p *webhookPoster) close() {
+	close(p.results)
+
+	done := make(chan struct{})
+
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-p.clock.After(resultWebhookDrainTimeout):
+		p.l.Warnf("Dropping the results not delivered to webhook %s within %s, %d results were still queued",
+			p.url, resultWebhookDrainTimeout, len(p.results))
+		p.cancel()
+		<-done
+	}
+
+	p.cancel()
+}
@@ -0,0 +1,201 @@
+package runnerpool_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/errors"
+	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/test/helpers/logger"
+)
+
+// webhookClock is a fake clock whose retry delays elapse immediately, while the drain timeout of the result
+// webhook only passes once expired is closed.
+type webhookClock struct {
+	fakeClock
+	expired chan struct{}
+}
+
+func (c *webhookClock) After(d time.Duration) <-chan time.Time {
+	if d < 30*time.Second {
+		return c.fakeClock.After(d)
+	}
+
+	ch := make(chan time.Time, 1)
+
+	go func() {
+		<-c.expired
+		ch <- c.Now()
+	}()
+
+	return ch
+}
+
+func TestController_ResultWebhook(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+		results  = map[string]runnerpool.WebhookResult{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result runnerpool.WebhookResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts[result.Path]++
+
+		// The first attempt to post the result of A fails, and is retried.
+		if result.Path == "A" && attempts[result.Path] == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		results[result.Path] = result
+	}))
+	t.Cleanup(server.Close)
+
+	// C depends on the failing B, so it exits early without running.
+	units := buildComponentUnits([]string{"A", "B", "C"}, map[string][]string{"C": {"B"}})
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error {
+			if u.Path() == "C" {
+				t.Error("C should not run")
+			}
+
+			if u.Path() == "B" {
+				return errors.New("boom with s3cr3t")
+			}
+
+			return nil
+		}),
+		runnerpool.WithClock(&webhookClock{expired: make(chan struct{})}),
+		runnerpool.WithResultWebhook(server.URL, nil),
+		runnerpool.WithResultWebhookFilter(func(unitPath string, line []byte) []byte {
+			return bytes.ReplaceAll(line, []byte("s3cr3t"), []byte("***"))
+		}),
+	)
+
+	require.Error(t, controller.Run(t.Context(), logger.CreateLogger()))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, 2, attempts["A"])
+	assert.Equal(t, runnerpool.WebhookResult{Path: "A", Result: "succeeded"}, results["A"])
+	assert.Equal(t, runnerpool.WebhookResult{Path: "B", Result: "failed", Reason: "run error", Error: "boom with ***"}, results["B"])
+	assert.Equal(t, runnerpool.WebhookResult{Path: "C", Result: "early exit", Reason: "ancestor error"}, results["C"])
+}
+
+func TestController_ResultWebhookDrainTimeout(t *testing.T) {
+	t.Parallel()
+
+	// The endpoint never answers, until the test is over.
+	release := make(chan struct{})
+
+	var requests atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	units := buildComponentUnits([]string{"A", "B", "C"}, nil)
+	clock := &webhookClock{expired: make(chan struct{})}
+
+	// The drain timeout passes as soon as the run waits for the results to be delivered.
+	close(clock.expired)
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(ctx context.Context, u *component.Unit) error { return nil }),
+		runnerpool.WithClock(clock),
+		runnerpool.WithMaxConcurrency(1),
+		runnerpool.WithResultWebhook(server.URL, nil),
+	)
+
+	// Run returns once the drain timeout passed, without waiting for the endpoint.
+	require.NoError(t, controller.Run(t.Context(), logger.CreateLogger()))
+	assert.LessOrEqual(t, requests.Load(), int64(1))
+}
+
+func TestController_ResultWebhookDeadline(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		results = map[string]runnerpool.WebhookResult{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result runnerpool.WebhookResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		results[result.Path] = result
+	}))
+	t.Cleanup(server.Close)
+
+	// A runs until the deadline of the run, so B is never started.
+	units := buildComponentUnits([]string{"A", "B"}, map[string][]string{"B": {"A"}})
+
+	ctx, cancel := context.WithCancelCause(t.Context())
+	defer cancel(nil)
+
+	controller := runnerpool.NewController(
+		buildQueue(t, units),
+		units,
+		runnerpool.WithRunner(func(runCtx context.Context, u *component.Unit) error {
+			cancel(context.DeadlineExceeded)
+
+			<-runCtx.Done()
+
+			return runCtx.Err()
+		}),
+		runnerpool.WithClock(&webhookClock{expired: make(chan struct{})}),
+		runnerpool.WithResultWebhook(server.URL, nil),
+	)
+
+	require.Error(t, controller.Run(ctx, logger.CreateLogger()))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Contains(t, results, "B")
+	assert.Equal(t, "failed", results["B"].Result)
+	assert.Equal(t, "deadline exceeded", results["B"].Reason)
+	assert.Contains(t, results["B"].Error, "deadline of the run passed")
+}
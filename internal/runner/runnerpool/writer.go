@@ -2,19 +2,38 @@ package runnerpool
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"sync"
 )
 
+// DefaultGroupedOutputLimit is the number of bytes of output a unit writer buffers in grouped mode before
+// falling back to streaming, when no other limit is set.
+const DefaultGroupedOutputLimit = 10 << 20
+
 // UnitWriter buffers output for a single unit and flushes incrementally during execution.
 // This prevents interleaved output when multiple units run in parallel while ensuring
 // output appears in real-time during execution, not just at completion.
 type UnitWriter struct {
-	out    io.Writer
-	buffer bytes.Buffer
-	mu     sync.Mutex
+	out      io.Writer
+	budget   *OutputBudget
+	filter   OutputFilter
+	unitPath string
+	// groupName is the name of the unit in the header and footer of its output in grouped mode, where the
+	// whole output is held until Flush, unless it exceeds groupLimit bytes or the budget is exceeded.
+	groupName  string
+	groupLimit int
+	grouped    bool
+	// streaming is set in grouped mode once the header was written, after falling back to streaming.
+	streaming bool
+	buffer    bytes.Buffer
+	mu        sync.Mutex
 }
 
+// OutputFilter rewrites a line of output of the unit at the given path, without its trailing newline, before
+// it is written, e.g. to redact secrets. Returning nil drops the line.
+type OutputFilter func(unitPath string, line []byte) []byte
+
 // NewUnitWriter returns a new UnitWriter instance.
 func NewUnitWriter(out io.Writer) *UnitWriter {
 	return &UnitWriter{
@@ -22,7 +41,44 @@ func NewUnitWriter(out io.Writer) *UnitWriter {
 	}
 }
 
+// WithBudget makes the writer account its buffered bytes against the given shared budget.
+func (writer *UnitWriter) WithBudget(budget *OutputBudget) *UnitWriter {
+	writer.budget = budget
+
+	return writer
+}
+
+// WithFilter makes the writer pass every line of output of the unit at the given path through the filter
+// before writing it.
+func (writer *UnitWriter) WithFilter(unitPath string, filter OutputFilter) *UnitWriter {
+	writer.unitPath = unitPath
+	writer.filter = filter
+
+	return writer
+}
+
+// WithGrouping makes the writer hold the whole output of the unit with the given name, and write it as a
+// single block between a header and a footer on Flush, so that the output of concurrent units does not
+// interleave and blocks appear in completion order. Past limit buffered bytes, if positive, or when the
+// budget of the writer is exceeded, the writer writes the header and falls back to streaming complete lines.
+func (writer *UnitWriter) WithGrouping(name string, limit int) *UnitWriter {
+	writer.grouped = true
+	writer.groupName = name
+	writer.groupLimit = limit
+
+	return writer
+}
+
 func (writer *UnitWriter) Write(p []byte) (int, error) {
+	n, err := writer.write(p)
+	if err != nil {
+		return n, err
+	}
+
+	return n, writer.enforceBudget()
+}
+
+func (writer *UnitWriter) write(p []byte) (int, error) {
 	writer.mu.Lock()
 	defer writer.mu.Unlock()
 
@@ -31,6 +87,16 @@ func (writer *UnitWriter) Write(p []byte) (int, error) {
 		return n, err
 	}
 
+	if writer.grouped && !writer.streaming {
+		if writer.groupLimit <= 0 || writer.buffer.Len() <= writer.groupLimit {
+			return n, nil
+		}
+
+		if headerErr := writer.startStreaming(); headerErr != nil {
+			return n, headerErr
+		}
+	}
+
 	if flushErr := writer.flushCompleteLines(); flushErr != nil {
 		return n, flushErr
 	}
@@ -38,6 +104,25 @@ func (writer *UnitWriter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// enforceBudget reports the buffered size to the shared budget, if any, and flushes the largest
+// buffer when the budget is exceeded. It must be called without holding the writer lock, since
+// the flushed buffer may belong to another writer.
+func (writer *UnitWriter) enforceBudget() error {
+	if writer.budget == nil {
+		return nil
+	}
+
+	writer.mu.Lock()
+	size := writer.buffer.Len()
+	writer.mu.Unlock()
+
+	if largest := writer.budget.update(writer, size); largest != nil {
+		return largest.spill()
+	}
+
+	return nil
+}
+
 // flushCompleteLines flushes any complete lines (ending with newline) from the buffer.
 // Partial lines (without trailing newline) remain in the buffer.
 func (writer *UnitWriter) flushCompleteLines() error {
@@ -52,7 +137,7 @@ func (writer *UnitWriter) flushCompleteLines() error {
 		lineCount := lastNewline + 1
 		lines := writer.buffer.Next(lineCount)
 
-		if _, err := writer.out.Write(lines); err != nil {
+		if _, err := writer.out.Write(writer.filtered(lines)); err != nil {
 			writer.buffer.Write(lines)
 			return err
 		}
@@ -61,17 +146,120 @@ func (writer *UnitWriter) flushCompleteLines() error {
 	return nil
 }
 
-// Flush flushes all buffered data to the output writer.
+// filtered passes every line of the given output through the filter of the writer, if any, and returns the
+// lines kept. A final line without a trailing newline is filtered as is.
+func (writer *UnitWriter) filtered(output []byte) []byte {
+	if writer.filter == nil || len(output) == 0 {
+		return output
+	}
+
+	var result []byte
+
+	for len(output) > 0 {
+		line, rest, found := bytes.Cut(output, []byte{'\n'})
+		output = rest
+
+		line = writer.filter(writer.unitPath, line)
+		if line == nil {
+			continue
+		}
+
+		result = append(result, line...)
+
+		if found {
+			result = append(result, '\n')
+		}
+	}
+
+	return result
+}
+
+// Flush flushes all buffered data to the output writer. In grouped mode, the output is written between the
+// header and the footer of the unit, and nothing is written for a unit without output.
 func (writer *UnitWriter) Flush() error {
 	writer.mu.Lock()
 	defer writer.mu.Unlock()
 
-	if writer.out != nil {
-		if _, err := writer.buffer.WriteTo(writer.out); err != nil {
+	if err := writer.writeBuffered(true); err != nil {
+		return err
+	}
+
+	if writer.streaming {
+		if _, err := fmt.Fprintf(writer.out, "=== End of output of %s ===\n", writer.groupName); err != nil {
+			return err
+		}
+
+		writer.streaming = false
+	}
+
+	if writer.budget != nil {
+		writer.budget.update(writer, writer.buffer.Len())
+	}
+
+	return nil
+}
+
+// spill writes the buffered data to the output writer when the budget is exceeded. In grouped mode, the
+// writer falls back to streaming its output until Flush.
+//
+// With a filter, a final line without a trailing newline is kept buffered until it is complete or flushed,
+// so that the filter never sees a line split in fragments, e.g. a secret a redaction would then miss.
+func (writer *UnitWriter) spill() error {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	if err := writer.writeBuffered(writer.filter == nil); err != nil {
+		return err
+	}
+
+	if writer.budget != nil {
+		writer.budget.update(writer, writer.buffer.Len())
+	}
+
+	return nil
+}
+
+// writeBuffered writes the buffer to the output writer, preceded by the header of the unit in grouped mode
+// when it was not written yet. Unless partial is set, a final line without a trailing newline is kept in the
+// buffer. It must be called with the lock held.
+func (writer *UnitWriter) writeBuffered(partial bool) error {
+	if writer.out == nil {
+		return nil
+	}
+
+	if writer.grouped && !writer.streaming && writer.buffer.Len() > 0 {
+		if err := writer.startStreaming(); err != nil {
 			return err
 		}
 	}
 
+	size := writer.buffer.Len()
+	if !partial {
+		size = bytes.LastIndexByte(writer.buffer.Bytes(), '\n') + 1
+	}
+
+	if _, err := writer.out.Write(writer.filtered(writer.buffer.Bytes()[:size])); err != nil {
+		return err
+	}
+
+	writer.buffer.Next(size)
+
+	return nil
+}
+
+// startStreaming writes the header of the output of the unit in grouped mode, after which its output is
+// streamed until Flush writes the footer. It must be called with the lock held.
+func (writer *UnitWriter) startStreaming() error {
+	if writer.out == nil {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(writer.out, "=== Output of %s ===\n", writer.groupName); err != nil {
+		return err
+	}
+
+	writer.streaming = true
+
 	return nil
 }
 
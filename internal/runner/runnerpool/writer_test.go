@@ -1,14 +1,26 @@
 package runnerpool_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/gruntwork-io/terragrunt/internal/component"
+	"github.com/gruntwork-io/terragrunt/internal/iacargs"
+	"github.com/gruntwork-io/terragrunt/internal/report"
 	"github.com/gruntwork-io/terragrunt/internal/runner/runnerpool"
+	"github.com/gruntwork-io/terragrunt/internal/util"
+	"github.com/gruntwork-io/terragrunt/pkg/config"
+	"github.com/gruntwork-io/terragrunt/pkg/options"
+	"github.com/gruntwork-io/terragrunt/test/helpers"
+	thlogger "github.com/gruntwork-io/terragrunt/test/helpers/logger"
 )
 
 func TestUnitWriter_WriteErrorPropagation(t *testing.T) {
@@ -52,6 +64,228 @@ func TestUnitWriter_FlushCompleteLines(t *testing.T) {
 	require.Contains(t, buf.String(), "partial")
 }
 
+func TestUnitWriter_Filter(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+
+	writer := runnerpool.NewUnitWriter(&buf).WithFilter("/tmp/app", func(unitPath string, line []byte) []byte {
+		assert.Equal(t, "/tmp/app", unitPath)
+
+		if bytes.HasPrefix(line, []byte("debug")) {
+			return nil
+		}
+
+		return bytes.ReplaceAll(line, []byte("hunter2"), []byte("***"))
+	})
+
+	_, err := writer.Write([]byte("password = hunter2\ndebug line\nuser = admin\ntoken hun"))
+	require.NoError(t, err)
+	assert.Equal(t, "password = ***\nuser = admin\n", buf.String())
+
+	_, err = writer.Write([]byte("ter2"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "password = ***\nuser = admin\ntoken ***", buf.String())
+}
+
+func TestUnitWriter_Grouping(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+
+	writer := runnerpool.NewUnitWriter(&buf).WithGrouping("app", 0)
+
+	_, err := writer.Write([]byte("line 1\nline 2\n"))
+	require.NoError(t, err)
+	assert.Empty(t, buf.String(), "grouped output should be held until flushed")
+
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "=== Output of app ===\nline 1\nline 2\n=== End of output of app ===\n", buf.String())
+
+	var empty strings.Builder
+
+	require.NoError(t, runnerpool.NewUnitWriter(&empty).WithGrouping("db", 0).Flush())
+	assert.Empty(t, empty.String())
+}
+
+func TestUnitWriter_GroupingFallsBackToStreaming(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+
+	writer := runnerpool.NewUnitWriter(&buf).WithGrouping("app", 8)
+
+	_, err := writer.Write([]byte("line 1\n"))
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	_, err = writer.Write([]byte("line 2\nline"))
+	require.NoError(t, err)
+	assert.Equal(t, "=== Output of app ===\nline 1\nline 2\n", buf.String())
+
+	_, err = writer.Write([]byte(" 3\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "=== Output of app ===\nline 1\nline 2\nline 3\n=== End of output of app ===\n", buf.String())
+}
+
+func TestRunner_GroupedOutput(t *testing.T) {
+	t.Parallel()
+
+	units, opts, stdout, stderr := newConcurrentOutputStack(t)
+	opts.GroupedOutput = true
+
+	l := thlogger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units)
+	require.NoError(t, err)
+
+	require.NoError(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+	// Each stream of each unit is written as a block holding the lines of that unit only.
+	for _, u := range units {
+		name := filepath.Base(u.Path())
+		other := map[string]string{"a": "b", "b": "a"}[name]
+
+		for stream, output := range map[string]string{"": stdout.String(), " (stderr)": stderr.String()} {
+			header := "=== Output of " + u.DisplayPath() + stream + " ===\n"
+			footer := "=== End of output of " + u.DisplayPath() + stream + " ===\n"
+
+			_, rest, found := strings.Cut(output, header)
+			require.True(t, found, "missing block %q in %q", header, output)
+
+			block, _, found := strings.Cut(rest, footer)
+			require.True(t, found, "missing end of block %q in %q", header, output)
+
+			prefix := "out"
+			if stream != "" {
+				prefix = "err"
+			}
+
+			assert.Contains(t, block, prefix+"1 "+name)
+			assert.Contains(t, block, prefix+"2 "+name)
+			assert.NotContains(t, block, prefix+"1 "+other)
+		}
+	}
+}
+
+func TestRunner_UnitOutputMemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	units, opts, stdout, _ := newConcurrentOutputStack(t)
+	opts.GroupedOutput = true
+	opts.UnitOutputMemoryBudget = 1
+
+	l := thlogger.CreateLogger()
+
+	stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units)
+	require.NoError(t, err)
+
+	require.NoError(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+	// Past the budget, both units stream their output, so their blocks overlap.
+	output := stdout.String()
+	assert.Less(t, strings.LastIndex(output, "=== Output of "), strings.Index(output, "=== End of output of "), output)
+
+	for _, line := range []string{"out1 a", "out2 a", "out1 b", "out2 b"} {
+		assert.Contains(t, output, line)
+	}
+}
+
+func TestRunner_OutputFilterStderr(t *testing.T) {
+	t.Parallel()
+
+	for _, grouped := range []bool{false, true} {
+		units, opts, stdout, stderr := newConcurrentOutputStack(t)
+		opts.GroupedOutput = grouped
+
+		l := thlogger.CreateLogger()
+
+		// The first lines of each stream of each unit stand for secrets. The trailing space keeps the filter
+		// from matching the name of the temporary directory of the test.
+		stack, err := runnerpool.NewRunnerPoolStack(context.Background(), l, opts, units,
+			runnerpool.WithOutputFilter(func(_ string, line []byte) []byte {
+				line = bytes.ReplaceAll(line, []byte("out1 "), []byte("*** "))
+				return bytes.ReplaceAll(line, []byte("err1 "), []byte("*** "))
+			}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, stack.Run(t.Context(), l, opts, report.NewReport()))
+
+		for _, name := range []string{"a", "b"} {
+			assert.Contains(t, stdout.String(), "*** "+name)
+			assert.Contains(t, stdout.String(), "out2 "+name)
+			assert.Contains(t, stderr.String(), "*** "+name)
+			assert.Contains(t, stderr.String(), "err2 "+name)
+		}
+
+		assert.NotContains(t, stdout.String(), "out1 ")
+		assert.NotContains(t, stderr.String(), "err1 ", "grouped: %t", grouped)
+	}
+}
+
+// newConcurrentOutputStack returns the units a and b of a stack whose fake OpenTofu binary writes to stdout and
+// stderr while the other unit runs, along with the options of an apply of the stack writing to the returned
+// stdout and stderr buffers.
+func newConcurrentOutputStack(t *testing.T) (component.Components, *options.TerragruntOptions, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	rootDir := helpers.TmpDirWOSymlinks(t)
+
+	files := map[string]string{
+		"modules/unit/main.tf": "",
+		"tofu": `#!/bin/sh
+case "$1" in
+  -version|version) echo "OpenTofu v1.9.0"; exit 0 ;;
+  init) exit 0 ;;
+esac
+echo "out1 $TF_VAR_name"
+echo "err1 $TF_VAR_name" >&2
+sleep 0.5
+echo "out2 $TF_VAR_name"
+echo "err2 $TF_VAR_name" >&2
+exit 0
+`,
+	}
+
+	units := component.Components{}
+
+	for _, name := range []string{"a", "b"} {
+		files[name+"/terragrunt.hcl"] = `terraform {
+  source = "../modules/unit"
+}
+
+inputs = {
+  name = "` + name + `"
+}
+`
+		units = append(units, component.NewUnit(filepath.Join(rootDir, name)).WithConfig(&config.TerragruntConfig{}))
+	}
+
+	for path, contents := range files {
+		path = filepath.Join(rootDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o755))
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	opts, err := options.NewTerragruntOptionsForTest(filepath.Join(rootDir, "terragrunt.hcl"))
+	require.NoError(t, err)
+
+	opts.WorkingDir = rootDir
+	opts.RootWorkingDir = rootDir
+	opts.TFPath = filepath.Join(rootDir, "tofu")
+	opts.TerraformCommand = "apply"
+	opts.TerraformCliArgs = iacargs.New("apply")
+	opts.Writers.Writer = util.NewSyncWriter(&stdout)
+	opts.Writers.ErrWriter = util.NewSyncWriter(&stderr)
+
+	return units, opts, &stdout, &stderr
+}
+
 func TestUnitWriter_Unwrap(t *testing.T) {
 	t.Parallel()
 
@@ -97,3 +331,66 @@ type failingWriter struct {
 func (w *failingWriter) Write(_ []byte) (int, error) {
 	return 0, w.err
 }
+
+func TestUnitWriter_BudgetFlushesLargestBuffer(t *testing.T) {
+	t.Parallel()
+
+	var small, large strings.Builder
+
+	budget := runnerpool.NewOutputBudget(8)
+	smallWriter := runnerpool.NewUnitWriter(&small).WithBudget(budget)
+	largeWriter := runnerpool.NewUnitWriter(&large).WithBudget(budget)
+
+	_, err := smallWriter.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, 3, budget.Used())
+
+	_, err = largeWriter.Write([]byte("abcdefg"))
+	require.NoError(t, err)
+
+	// The budget was exceeded, so the largest partial line was flushed early.
+	assert.Equal(t, "abcdefg", large.String())
+	assert.Empty(t, small.String())
+	assert.Equal(t, 3, budget.Used())
+
+	require.NoError(t, smallWriter.Flush())
+	assert.Equal(t, "abc", small.String())
+	assert.Equal(t, 0, budget.Used())
+}
+
+func TestUnitWriter_BudgetKeepsPartialLineForFilter(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+
+	budget := runnerpool.NewOutputBudget(8)
+	writer := runnerpool.NewUnitWriter(&buf).WithBudget(budget).WithFilter("/tmp/app", func(_ string, line []byte) []byte {
+		return bytes.ReplaceAll(line, []byte("hunter2"), []byte("***"))
+	})
+
+	// The budget is exceeded in the middle of the secret, so only the complete line is written early.
+	_, err := writer.Write([]byte("user = admin\ntoken hun"))
+	require.NoError(t, err)
+	assert.Equal(t, "user = admin\n", buf.String())
+
+	_, err = writer.Write([]byte("ter2\nlast hunter2"))
+	require.NoError(t, err)
+	assert.Equal(t, "user = admin\ntoken ***\n", buf.String())
+
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, "user = admin\ntoken ***\nlast ***", buf.String())
+	assert.Equal(t, 0, budget.Used())
+}
+
+func TestUnitWriter_BudgetDisabled(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+
+	budget := runnerpool.NewOutputBudget(0)
+	writer := runnerpool.NewUnitWriter(&buf).WithBudget(budget)
+
+	_, err := writer.Write([]byte("partial output that exceeds nothing"))
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
@@ -3,6 +3,7 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"maps"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -11,7 +12,8 @@ type contextKey byte
 
 const (
 	telemeterContextKey contextKey = iota
-	TraceParentEnv                 = "TRACEPARENT"
+	spanAttributesContextKey
+	TraceParentEnv = "TRACEPARENT"
 )
 
 // ContextWithTelemeter returns a new context with the provided Telemeter attached.
@@ -31,6 +33,26 @@ func TelemeterFromContext(ctx context.Context) *Telemeter {
 	return new(Telemeter)
 }
 
+// ContextWithSpanAttributes returns a new context carrying the given attributes, along with the ones the
+// context already carries, which are added to every span opened with it, e.g. the path of the unit being run.
+func ContextWithSpanAttributes(ctx context.Context, attrs map[string]any) context.Context {
+	merged := maps.Clone(spanAttributesFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(attrs))
+	}
+
+	maps.Copy(merged, attrs)
+
+	return context.WithValue(ctx, spanAttributesContextKey, merged)
+}
+
+// spanAttributesFromContext returns the attributes added to every span opened with the context.
+func spanAttributesFromContext(ctx context.Context) map[string]any {
+	attrs, _ := ctx.Value(spanAttributesContextKey).(map[string]any)
+
+	return attrs
+}
+
 // TraceParentFromContext returns the W3C traceparent header value from the context's span, or an error if not available.
 func TraceParentFromContext(ctx context.Context, telemetry *Options) string {
 	span := trace.SpanFromContext(ctx)
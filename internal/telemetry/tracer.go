@@ -243,7 +243,8 @@ func (tracer *Tracer) openSpan(ctx context.Context, name string, attrs map[strin
 	// and fixing the lint.
 
 	ctx, span := tracer.Start(ctx, name) // nolint:spancheck
-	// convert attrs map to span.SetAttributes
+	// convert attrs map to span.SetAttributes, the attributes of the context first so that attrs take precedence
+	span.SetAttributes(mapToAttributes(spanAttributesFromContext(ctx))...)
 	span.SetAttributes(mapToAttributes(attrs)...)
 
 	return ctx, span //nolint:spancheck
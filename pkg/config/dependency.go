@@ -153,6 +153,33 @@ func (dep *Dependency) isEnabled() bool {
 	return *dep.Enabled
 }
 
+// ReadsOutputs returns true if the dependency is enabled and its outputs are read, i.e. it is more than an
+// ordering constraint.
+func (dep *Dependency) ReadsOutputs() bool {
+	return dep.isEnabled() && (dep.SkipOutputs == nil || !*dep.SkipOutputs)
+}
+
+// OutputsPath returns the cleaned directory of the unit the dependency reads the outputs of, with a relative
+// config path resolved against the directory of the unit at unitPath holding the dependency block. It returns
+// false when the dependency does not read outputs or its config path is not known.
+func (dep *Dependency) OutputsPath(unitPath string) (string, bool) {
+	if !dep.ReadsOutputs() || !IsValidConfigPath(dep.ConfigPath) {
+		return "", false
+	}
+
+	path := dep.ConfigPath.AsString()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(unitPath, path)
+	}
+
+	// The config path may point at the configuration file of the unit instead of its directory.
+	if filepath.Ext(path) == ".hcl" {
+		path = filepath.Dir(path)
+	}
+
+	return filepath.Clean(path), true
+}
+
 // isDisabled returns true if the dependency is disabled
 func (dep *Dependency) isDisabled() bool {
 	return !dep.isEnabled()
@@ -856,6 +883,26 @@ func isRenderCommand(pctx *ParsingContext) bool {
 	return pctx.TerraformCliArgs.Contains(renderCommand)
 }
 
+// CacheDependencyOutputJSON stores the output of `terraform output -json` for the given target config in the
+// output cache of the context. Dependency blocks targeting that config then read these outputs instead of
+// fetching them from its state.
+func CacheDependencyOutputJSON(ctx context.Context, targetConfig string, outputJSON []byte) {
+	cache.ContextCache[[]byte](ctx, JSONOutputCacheContextKey).Put(ctx, filepath.Clean(targetConfig), outputJSON)
+}
+
+// CachedDependencyOutputJSON returns the output of `terraform output -json` cached for the given target config
+// in the output cache of the context, if any, e.g. once a dependency block targeting that config was parsed.
+func CachedDependencyOutputJSON(ctx context.Context, targetConfig string) ([]byte, bool) {
+	return cache.ContextCache[[]byte](ctx, JSONOutputCacheContextKey).Get(ctx, filepath.Clean(targetConfig))
+}
+
+// DependencyOutputJSON returns the output of `terraform output -json` for the given target config. The outputs
+// are read from the output cache of the context when a dependency block targeting that config was parsed before,
+// and fetched from its state and cached otherwise.
+func DependencyOutputJSON(ctx context.Context, pctx *ParsingContext, l log.Logger, targetConfig string) ([]byte, error) {
+	return getOutputJSONWithCaching(ctx, pctx, l, filepath.Clean(targetConfig))
+}
+
 // getOutputJSONWithCaching will run terragrunt output on the target config if it is not already cached.
 func getOutputJSONWithCaching(ctx context.Context, pctx *ParsingContext, l log.Logger, targetConfig string) ([]byte, error) {
 	locks := outputLocksFromContext(ctx)
@@ -267,3 +267,71 @@ dependency "enabled" {
 	// Only enabled dependency should be in the paths
 	assert.Len(t, terragruntConfig.Dependencies.Paths, 1)
 }
+
+func TestCacheDependencyOutputJSON(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+
+	depDir := filepath.Join(tmpDir, "dep")
+	appDir := filepath.Join(tmpDir, "app")
+
+	require.NoError(t, os.MkdirAll(depDir, 0o755))
+	require.NoError(t, os.MkdirAll(appDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(depDir, config.DefaultTerragruntConfigPath), []byte(""), 0o600))
+
+	appConfig := filepath.Join(appDir, config.DefaultTerragruntConfigPath)
+	require.NoError(t, os.WriteFile(appConfig, []byte(`
+dependency "dep" {
+  config_path = "../dep"
+}
+
+inputs = {
+  vpc_id = dependency.dep.outputs.vpc_id
+}
+`), 0o600))
+
+	ctx, pctx := newTestParsingContext(t, appConfig)
+	ctx = config.WithConfigValues(ctx)
+
+	config.CacheDependencyOutputJSON(ctx, filepath.Join(depDir, config.DefaultTerragruntConfigPath), []byte(
+		`{"vpc_id": {"sensitive": false, "type": "string", "value": "vpc-123"}}`,
+	))
+
+	cfg, err := config.ParseConfigFile(ctx, pctx, logger.CreateLogger(), appConfig, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "vpc-123", cfg.Inputs["vpc_id"])
+}
+
+func TestDependencyOutputsPath(t *testing.T) {
+	t.Parallel()
+
+	unitPath := filepath.Join(string(filepath.Separator), "live", "app")
+	vpcPath := filepath.Join(string(filepath.Separator), "live", "vpc")
+	disabled := false
+	skipOutputs := true
+
+	testCases := []struct {
+		name     string
+		dep      config.Dependency
+		expected string
+	}{
+		{name: "relative", dep: config.Dependency{ConfigPath: cty.StringVal("../vpc")}, expected: vpcPath},
+		{name: "absolute", dep: config.Dependency{ConfigPath: cty.StringVal(vpcPath)}, expected: vpcPath},
+		{name: "config file", dep: config.Dependency{ConfigPath: cty.StringVal("../vpc/terragrunt.hcl")}, expected: vpcPath},
+		{name: "disabled", dep: config.Dependency{ConfigPath: cty.StringVal("../vpc"), Enabled: &disabled}},
+		{name: "skip outputs", dep: config.Dependency{ConfigPath: cty.StringVal("../vpc"), SkipOutputs: &skipOutputs}},
+		{name: "unknown", dep: config.Dependency{ConfigPath: cty.UnknownVal(cty.String)}},
+		{name: "empty", dep: config.Dependency{ConfigPath: cty.StringVal("")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path, ok := tc.dep.OutputsPath(unitPath)
+			assert.Equal(t, tc.expected != "", ok)
+			assert.Equal(t, tc.expected, path)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/gruntwork-io/terragrunt/internal/iam"
 	pcoptions "github.com/gruntwork-io/terragrunt/internal/providercache/options"
 	"github.com/gruntwork-io/terragrunt/internal/report"
+	"github.com/gruntwork-io/terragrunt/internal/retry"
 	"github.com/gruntwork-io/terragrunt/internal/strict"
 	"github.com/gruntwork-io/terragrunt/internal/strict/controls"
 	"github.com/gruntwork-io/terragrunt/internal/telemetry"
@@ -132,6 +133,9 @@ type TerragruntOptions struct {
 	AuthProviderCmd string
 	// Folder to store JSON representation of output files.
 	JSONOutputFolder string
+	// KeepJSONInMemoryOnly converts the plans of a run --all to JSON to record their resource changes in the
+	// report, without writing the JSON plan files to JSONOutputFolder.
+	KeepJSONInMemoryOnly bool
 	// Folder to store output files.
 	OutputFolder string
 	// The file which hclfmt should be specifically run on
@@ -157,6 +161,9 @@ type TerragruntOptions struct {
 	ReportFormat report.Format
 	// Path to the report schema file.
 	ReportSchemaFile string
+	// ReportSnapshotInterval is the number of seconds between the snapshots of the report written to
+	// ReportFile while a run --all is in progress. Zero disables snapshots.
+	ReportSnapshotInterval int
 	// CLI args that are intended for Terraform (i.e. all the CLI args except the --terragrunt ones)
 	TerraformCliArgs *iacargs.IacArgs
 	// Files with variables to be used in modules scaffolding.
@@ -181,6 +188,105 @@ type TerragruntOptions struct {
 	Parallelism int
 	// When searching the directory tree, this is the max folders to check before exiting with an error.
 	MaxFoldersToCheck int
+	// MaxTotalChanges aborts a run --all apply before anything is applied when the plan reports more
+	// resource changes than this across all units. Zero disables the check.
+	MaxTotalChanges int
+	// FailOnEmptyRun makes run --all fail when no unit is left to run, e.g. because every unit was excluded.
+	FailOnEmptyRun bool
+	// MaxTotalRetries bounds the total number of retries across all units of a run --all, on top of the
+	// attempts allowed by each retry block. Zero disables the limit.
+	MaxTotalRetries int
+	// MaxReportedErrors caps the number of unit errors a run --all fails with, summarizing the rest.
+	// Zero reports every error.
+	MaxReportedErrors int
+	// ExcludeGlobs excludes the units of a run --all whose path matches one of the globs, the last matching glob
+	// deciding, where a glob prefixed with "!" includes the units it matches again.
+	ExcludeGlobs []string
+	// UnitCommands overrides the command a run --all runs in the units at the given paths, e.g. to only plan a
+	// unit while its dependencies are applied. Relative paths are resolved against the working directory.
+	UnitCommands map[string]string
+	// UnitSuccessCmd is a command run after every successful run of a unit of a run --all, e.g. to replicate
+	// its state to a secondary backend.
+	UnitSuccessCmd string
+	// FailOnUnitSuccessCmdError fails a unit when UnitSuccessCmd fails for it, instead of logging a warning.
+	FailOnUnitSuccessCmdError bool
+	// PlanFileResolverCmd is a command printing the plan file to convert to JSON for each unit of a run --all,
+	// e.g. after decrypting the plan the run wrote.
+	PlanFileResolverCmd string
+	// ConfirmEachUnit prompts for confirmation before each unit of a run --all starts, unless NonInteractive is
+	// set. Declined units are skipped, along with the units depending on them.
+	ConfirmEachUnit bool
+	// ProgressLogging logs a progress line, such as "[37/120] finished app (3 running, 80 waiting)", every time
+	// a unit of a run --all finishes.
+	ProgressLogging bool
+	// FanOutWarningThreshold warns about the units of a run --all that more than this many units directly depend
+	// on, as bottlenecks of the run. Zero disables the warning.
+	FanOutWarningThreshold int
+	// WaveBarrierOnFailure runs the dependency waves of a run --all in stages, where a wave only waits for every
+	// unit of the previous wave to finish when the wave before that one had failures.
+	WaveBarrierOnFailure bool
+	// Canaries runs a single canary unit of each dependency wave of a run --all alone first, and only runs the
+	// rest of the wave once the canary succeeded.
+	Canaries bool
+	// GroupErrorsByType groups the unit errors a run --all fails with by the Go type of their cause.
+	GroupErrorsByType bool
+	// GroupErrorsBySubtree groups the unit errors a run --all fails with by the top-level directory of the
+	// stack the units belong to, e.g. per account directory.
+	GroupErrorsBySubtree bool
+	// MaxStartsPerSecond caps how many units of a run --all start per second. Zero does not limit the start rate.
+	MaxStartsPerSecond int
+	// MinFreeDiskBytes fails the units of a run --all whose working directory has less free disk space than this
+	// many bytes, without running them. Zero disables the check.
+	MinFreeDiskBytes int64
+	// FlakyHuntSeed randomizes the dispatch order and the parallelism of a run --all from the seed, to surface
+	// hidden ordering and concurrency assumptions. Zero disables the randomization.
+	FlakyHuntSeed int64
+	// ReleaseFinishedUnits drops the parsed configuration of every unit of a run --all once it has finished,
+	// to bound the memory footprint of massive stacks.
+	ReleaseFinishedUnits bool
+	// StrictReporting fails the units of a run --all whose result cannot be recorded in the report, instead
+	// of only logging the failure.
+	StrictReporting bool
+	// FailOnStalePlan records the dependency outputs of the units planned by a run --all into the output folder,
+	// and fails the units whose dependency outputs changed by the time their plan is applied.
+	FailOnStalePlan bool
+	// RequireFullDependencyChain fails the units of a run --all apply with a dependency, direct or transitive,
+	// that neither succeeded in the run nor is assumed to be applied, without applying them.
+	RequireFullDependencyChain bool
+	// UnitOutputMemoryBudget bounds the total number of bytes of output buffered for the units of a run --all.
+	// Past the budget, the largest buffer is written early, interleaving its output with the output of other
+	// units. Zero disables the budget.
+	UnitOutputMemoryBudget int
+	// MemoryBudget caps the sum of the memory estimates of the running units of a run --all, in MiB, in addition
+	// to Parallelism. Zero disables the budget.
+	MemoryBudget int
+	// UnitMemoryEstimates holds the estimated peak memory of units of a run --all, in MiB, keyed by the path of
+	// the unit. Units without an estimate are not limited by MemoryBudget.
+	UnitMemoryEstimates map[string]int
+	// GroupedOutput holds the output of each unit of a run --all until it finishes, and writes its stdout and
+	// its stderr, along with its log lines, as a block each, so that the output of concurrent units does not
+	// interleave.
+	GroupedOutput bool
+	// EventSocketPath is the Unix domain socket the start and finish of every unit of a run --all are
+	// streamed to, as newline delimited JSON. Empty disables streaming.
+	EventSocketPath string
+	// ResultWebhook is the URL the result of every unit of a run --all is posted to as JSON when it finishes.
+	// Empty disables posting.
+	ResultWebhook string
+	// ContinueOnError makes run --all attempt every unit regardless of failures, while still failing the run
+	// with the errors of every unit that failed.
+	ContinueOnError bool
+	// RetryBudget is the budget of retries shared by the units of a run, built from MaxTotalRetries.
+	RetryBudget *retry.Budget `clone:"shadowcopy"`
+	// ParallelismAuto sets the parallelism of run --all to the width of the widest wave of the run,
+	// capped by Parallelism and the number of CPUs.
+	ParallelismAuto bool
+	// ParallelismFile is a file holding the parallelism of run --all, which is read again every second during
+	// the run, e.g. so that an external autoscaler can adjust it.
+	ParallelismFile string
+	// ParallelismSchedule sets the parallelism of each dependency wave of run --all, the last value
+	// applying to every later wave. Empty runs every wave with Parallelism.
+	ParallelismSchedule []int
 	// CASCloneDepth is passed to git clone as --depth when CAS clones a remote
 	// repository. Defaults to 1 (see internal/cas.DefaultCASCloneDepth). Values must be
 	// positive (git rejects --depth 0) or negative (e.g. -1) for a full clone without --depth.